@@ -0,0 +1,87 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// Accessibility receives announcements of changes in the editor, such as
+// the cursor moving to "line 12, column 4", so that hosts can pass them to
+// a screen reader or voice-over.
+type Accessibility interface {
+	Announce(message string)
+}
+
+// WithAccessibility sets the receiver of announcements.
+// If set to nil, nothing is announced.
+func WithAccessibility(opt Accessibility) EditorOption {
+	return func(e *Editor) {
+		e.accessibility = opt
+	}
+}
+
+// CurrentLine returns the text of the line with the cursor, without its
+// final '\n'. ANSI escape sequences are removed, if they are rendered.
+func (e *Editor) CurrentLine() string {
+	values := e.cursor.line.values
+	if e.ansi_colors && e.read_only {
+		values = ansiLineView(values).runes
+	}
+	return string(values[:len(values)-1])
+}
+
+// announce sends a message to the accessibility receiver, if any.
+func (e *Editor) announce(format string, args ...any) {
+	if e.accessibility == nil {
+		return
+	}
+	e.accessibility.Announce(e.tr(format, args...))
+	e.announced = true
+}
+
+// spoken returns text to announce, naming whitespace which is otherwise silent.
+func (e *Editor) spoken(rs []rune) string {
+	if len(rs) == 1 {
+		switch rs[0] {
+		case '\n':
+			return e.tr("new line")
+		case ' ':
+			return e.tr("space")
+		case '\t':
+			return e.tr("tab")
+		}
+	}
+	return string(rs)
+}
+
+// announcePosition announces the cursor position if it has changed,
+// and nothing else has been announced since the last update.
+func (e *Editor) announcePosition() {
+	if e.accessibility == nil {
+		return
+	}
+	row, col := e.Cursor()
+	pos := Position{row, col}
+	if pos != e.spokenPos && !e.announced {
+		e.announce("line %d, column %d", row+1, col+1)
+	}
+	e.spokenPos = pos
+	e.announced = false
+}
@@ -0,0 +1,47 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+type announcements []string
+
+func (a *announcements) Announce(message string) {
+	*a = append(*a, message)
+}
+
+func TestAnnounce(t *testing.T) {
+	var got announcements
+	editor := NewEditor(WithAccessibility(&got))
+	editor.WriteText([]byte("ab\ncd\n"))
+
+	editor.MoveCursor(1, 1)
+	editor.announcePosition()
+	editor.storeUndoAction(editor.fnHandleRuneSingle(' '))
+	editor.announcePosition()
+	editor.storeUndoAction(editor.fnDeleteSinglePrevious())
+	editor.announcePosition()
+	editor.SetMode(SEARCH_MODE)
+	editor.SetMode(EDIT_MODE)
+
+	want := announcements{
+		"line 2, column 2",
+		"inserted space",
+		"deleted space",
+		"search mode",
+		"edit mode",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Incorrect announcements, expected %q, got %q", want, got)
+	}
+}
+
+func TestCurrentLine(t *testing.T) {
+	editor := NewEditor(WithReadOnly(true), WithANSIColors(true))
+	editor.WriteText([]byte("\x1b[31merr\x1b[0m ok\nnext\n"))
+
+	if got := editor.CurrentLine(); got != "err ok" {
+		t.Fatalf("Incorrect current line, got: %q", got)
+	}
+}
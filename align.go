@@ -0,0 +1,96 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// AlignSelection aligns the selected lines on the first match of the pattern
+// in each line, by inserting spaces before the match. The pattern is matched
+// literally if it is a single character, and as a regular expression otherwise.
+// Lines without a match are left untouched. The alignment is a single
+// undoable action.
+func (e *Editor) AlignSelection(pattern string) error {
+	if len(pattern) == 0 {
+		return nil
+	}
+	if utf8.RuneCountInString(pattern) == 1 {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	first, last, ok := e.selectedRows()
+	if !ok {
+		return nil
+	}
+
+	line := e.start
+	for row := 0; row < first; row++ {
+		line = line.next
+	}
+
+	// Find the column of the match in each line.
+	lines := make([][]rune, 0, last-first+1)
+	columns := make([]int, 0, last-first+1)
+	target := -1
+	for row := first; row <= last; row++ {
+		column := -1
+		text := strings.TrimSuffix(string(line.values), "\n")
+		if loc := re.FindStringIndex(text); loc != nil {
+			column = utf8.RuneCountInString(text[:loc[0]])
+		}
+		if column > target {
+			target = column
+		}
+		lines = append(lines, line.values)
+		columns = append(columns, column)
+		line = line.next
+	}
+
+	if target < 0 {
+		return nil
+	}
+
+	aligned := make([][]rune, len(lines))
+	for i, values := range lines {
+		if columns[i] < 0 || columns[i] == target {
+			aligned[i] = values
+			continue
+		}
+		padded := make([]rune, 0, len(values)+target-columns[i])
+		padded = append(padded, values[:columns[i]]...)
+		for x := columns[i]; x < target; x++ {
+			padded = append(padded, ' ')
+		}
+		aligned[i] = append(padded, values[columns[i]:]...)
+	}
+
+	e.storeUndoAction(e.fnReplaceLines(first, len(lines), aligned))
+	return nil
+}
@@ -0,0 +1,59 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlignSelection(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a = 1\nbbb = 2\nnone\ncc: 3\n"))
+
+	editor.fnSelectAll()
+	if err := editor.AlignSelection("="); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "a   = 1\nbbb = 2\nnone\ncc: 3\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect alignment, expected %q, got: %q", want, got)
+	}
+
+	// The alignment is a single undo action.
+	editor.undoStack[len(editor.undoStack)-1]()
+	want = "a = 1\nbbb = 2\nnone\ncc: 3\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect undo of alignment, expected %q, got: %q", want, got)
+	}
+}
+
+func TestAlignSelectionRegex(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("x := 1\nlong: 2\n"))
+
+	editor.fnSelectAll()
+	if err := editor.AlignSelection(":=?"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "x   := 1\nlong: 2\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect alignment, expected %q, got: %q", want, got)
+	}
+
+	if err := editor.AlignSelection("(("); err == nil {
+		t.Fatalf("Expected an error for an invalid regular expression")
+	}
+}
+
+func TestAlignCommandError(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("x := 1\n"))
+	editor.fnSelectAll()
+
+	editor.RunCommand("Align")
+	editor.promptAction("((")
+	if notice, ok := editor.currentNotice(); !ok || !strings.Contains(notice, "((") {
+		t.Fatalf("Expected a notice of the invalid pattern, got: %q", notice)
+	}
+}
@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ansiPalette is the xterm palette of the 16 standard and bright colors.
+var ansiPalette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// WithANSIColors enables rendering of ANSI SGR color escape sequences, such
+// as "\x1b[31m", when the editor is read-only. Escape sequences are hidden and
+// the text following them is drawn in their foreground color. The content
+// itself, and so any saved text, keeps the escape sequences.
+func WithANSIColors(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.ansi_colors = enabled
+	}
+}
+
+// ansiColor returns the 256 color palette entry n.
+func ansiColor(n int) color.Color {
+	switch {
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		// 6x6x6 color cube
+		n -= 16
+		level := func(c int) uint8 {
+			if c == 0 {
+				return 0
+			}
+			return uint8(55 + c*40)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 0xff}
+	default:
+		// Grayscale ramp
+		gray := uint8(8 + (n-232)*10)
+		return color.RGBA{gray, gray, gray, 0xff}
+	}
+}
+
+// applySGR returns the foreground color after the SGR parameters.
+// A nil color is the default text color.
+func applySGR(params string, fg color.Color) color.Color {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			// An empty parameter is a reset.
+			code = 0
+		}
+
+		switch {
+		case code == 0 || code == 39:
+			fg = nil
+		case code >= 30 && code <= 37:
+			fg = ansiPalette[code-30]
+		case code >= 90 && code <= 97:
+			fg = ansiPalette[code-90+8]
+		case code == 38 || code == 48:
+			// Extended colors, only the foreground is used.
+			var extended color.Color
+			if i+2 < len(codes) && codes[i+1] == "5" {
+				n, _ := strconv.Atoi(codes[i+2])
+				extended = ansiColor(n & 0xff)
+				i += 2
+			} else if i+4 < len(codes) && codes[i+1] == "2" {
+				r, _ := strconv.Atoi(codes[i+2])
+				g, _ := strconv.Atoi(codes[i+3])
+				b, _ := strconv.Atoi(codes[i+4])
+				extended = color.RGBA{uint8(r), uint8(g), uint8(b), 0xff}
+				i += 4
+			}
+			if code == 38 && extended != nil {
+				fg = extended
+			}
+		}
+	}
+	return fg
+}
+
+// ansiLineView hides the escape sequences of the line, coloring the runes
+// which follow SGR sequences. Each line starts in the default color.
+func ansiLineView(values []rune) *lineView {
+	view := &lineView{
+		runes:  make([]rune, 0, len(values)),
+		index:  make([]int, len(values)+1),
+		colors: make([]color.Color, 0, len(values)),
+	}
+
+	var fg color.Color
+	for x := 0; x < len(values); x++ {
+		// Find the end of a control sequence, "ESC [ params final".
+		if values[x] == '\x1b' && x+1 < len(values) && values[x+1] == '[' {
+			end := x + 2
+			for end < len(values) && (values[end] < 0x40 || values[end] > 0x7e) && values[end] != '\n' {
+				end++
+			}
+			if end < len(values) && values[end] != '\n' {
+				if values[end] == 'm' {
+					fg = applySGR(string(values[x+2:end]), fg)
+				}
+				// The sequence has no width.
+				for ; x <= end; x++ {
+					view.index[x] = len(view.runes)
+				}
+				x--
+				continue
+			}
+		}
+
+		view.index[x] = len(view.runes)
+		view.runes = append(view.runes, values[x])
+		view.colors = append(view.colors, fg)
+	}
+	view.index[len(values)] = len(view.runes)
+
+	return view
+}
@@ -0,0 +1,47 @@
+package noter
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestANSILineView(t *testing.T) {
+	values := []rune("ok \x1b[31merr\x1b[0m done\n")
+	view := ansiLineView(values)
+
+	if got := string(view.runes); got != "ok err done\n" {
+		t.Fatalf("Expected escape sequences to be hidden, got: %q", got)
+	}
+
+	red := ansiPalette[1]
+	for d, want := range []color.Color{nil, nil, nil, red, red, red, nil} {
+		if view.colors[d] != want {
+			t.Fatalf("Incorrect color at %d, expected %v, got: %v", d, want, view.colors[d])
+		}
+	}
+
+	// The rune after a sequence is displayed where the sequence starts.
+	if view.index[3] != 3 || view.index[8] != 3 || view.index[11] != 6 {
+		t.Fatalf("Incorrect display positions: %v", view.index)
+	}
+}
+
+func TestApplySGR(t *testing.T) {
+	table := [](struct {
+		params string
+		want   color.Color
+	}){
+		{"32", ansiPalette[2]},
+		{"1;94", ansiPalette[12]},
+		{"38;5;196", color.RGBA{0xff, 0x00, 0x00, 0xff}},
+		{"38;2;1;2;3", color.RGBA{1, 2, 3, 0xff}},
+		{"48;5;196", ansiPalette[7]},
+		{"", nil},
+	}
+
+	for _, entry := range table {
+		if got := applySGR(entry.params, ansiPalette[7]); got != entry.want {
+			t.Fatalf("Incorrect color for %q, expected %v, got %v", entry.params, entry.want, got)
+		}
+	}
+}
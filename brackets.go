@@ -0,0 +1,266 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// bracketPairs maps each opening bracket to its closing bracket.
+var bracketPairs = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// closingBrackets maps each closing bracket to its opening bracket.
+var closingBrackets = map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+// scopeAlpha is the alpha of the scope guide and the scope's brackets,
+// which are drawn in the font color.
+const scopeAlpha = 40
+
+// WithScopeHighlight enables highlighting the extent of the bracketed, or
+// else indented, block around the cursor, with a guide line and by tinting
+// its brackets.
+func WithScopeHighlight(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.scope_highlight = enabled
+	}
+}
+
+// WithBracketMatching highlights the bracket at, or else just before, the
+// cursor, and the bracket matching it, in the Brackets color of the theme.
+func WithBracketMatching(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.bracket_match = enabled
+	}
+}
+
+// isBracket returns true if the rune is an opening or closing bracket.
+func isBracket(r rune) bool {
+	_, open := bracketPairs[r]
+	_, close := closingBrackets[r]
+	return open || close
+}
+
+// cursorBracket returns the position of the bracket at the cursor or,
+// failing that, just before it.
+func (e *Editor) cursorBracket() (int, bool) {
+	values := e.cursor.line.values
+	if x := e.cursor.x; x < len(values) && isBracket(values[x]) {
+		return x, true
+	}
+	if x := e.cursor.x - 1; x >= 0 && isBracket(values[x]) {
+		return x, true
+	}
+	return 0, false
+}
+
+// matchingBrackets returns the bracket by the cursor and its match, as the
+// positions on their lines, or nil if there is no match.
+func (e *Editor) matchingBrackets() map[*editorLine]map[int]bool {
+	x, ok := e.cursorBracket()
+	if !ok {
+		return nil
+	}
+	line, match, ok := matchBracket(e.cursor.line, x)
+	if !ok {
+		return nil
+	}
+	brackets := map[*editorLine]map[int]bool{e.cursor.line: {x: true}}
+	if _, ok := brackets[line]; !ok {
+		brackets[line] = make(map[int]bool)
+	}
+	brackets[line][match] = true
+	return brackets
+}
+
+// JumpToBracket moves the cursor to the bracket matching the bracket at,
+// or else just before, the cursor. It returns false if there is no match.
+func (e *Editor) JumpToBracket() bool {
+	x, ok := e.cursorBracket()
+	if !ok {
+		return false
+	}
+	line, match, ok := matchBracket(e.cursor.line, x)
+	if !ok {
+		return false
+	}
+	e.cursor.line, e.cursor.x = line, match
+	e.fixPosition()
+	return true
+}
+
+// matchBracket returns the position of the bracket matching the bracket
+// at x on the line, scanning forwards from an opening bracket and backwards
+// from a closing bracket.
+func matchBracket(line *editorLine, x int) (*editorLine, int, bool) {
+	if x >= len(line.values) {
+		return nil, 0, false
+	}
+
+	r := line.values[x]
+	if _, ok := bracketPairs[r]; ok {
+		stack := []rune{}
+		for x++; line != nil; line, x = line.next, 0 {
+			for ; x < len(line.values); x++ {
+				c := line.values[x]
+				if _, ok := bracketPairs[c]; ok {
+					stack = append(stack, c)
+				} else if open, ok := closingBrackets[c]; ok {
+					if len(stack) == 0 {
+						return line, x, open == r
+					}
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+		return nil, 0, false
+	}
+
+	if open, ok := closingBrackets[r]; ok {
+		line, x, found := scanToOpeningBracket(line, x)
+		return line, x, found && line.values[x] == open
+	}
+
+	return nil, 0, false
+}
+
+// scanToOpeningBracket scans backwards from x on the line, for the first
+// opening bracket which is not closed before x.
+func scanToOpeningBracket(line *editorLine, x int) (*editorLine, int, bool) {
+	stack := []rune{}
+	for x--; line != nil; {
+		for ; x >= 0; x-- {
+			c := line.values[x]
+			if _, ok := closingBrackets[c]; ok {
+				stack = append(stack, c)
+			} else if _, ok := bracketPairs[c]; ok {
+				if len(stack) == 0 {
+					return line, x, true
+				}
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if line = line.prev; line != nil {
+			x = len(line.values) - 1
+		}
+	}
+	return nil, 0, false
+}
+
+// enclosingBrackets returns the positions of the innermost pair of brackets
+// enclosing x on the line.
+func enclosingBrackets(line *editorLine, x int) (open *editorLine, openX int, close *editorLine, closeX int, ok bool) {
+	if open, openX, ok = scanToOpeningBracket(line, x); !ok {
+		return
+	}
+	close, closeX, ok = matchBracket(open, openX)
+	return
+}
+
+// indentOf returns the number of leading spaces and tabs of the line.
+func indentOf(values []rune) int {
+	indent := 0
+	for _, r := range values {
+		if r != ' ' && r != '\t' {
+			break
+		}
+		indent++
+	}
+	return indent
+}
+
+// blockScope is the extent of the block around the cursor.
+type blockScope struct {
+	lines    map[*editorLine]bool         // lines drawn with the guide.
+	column   int                          // column of the guide.
+	brackets map[*editorLine]map[int]bool // brackets to tint.
+}
+
+// scope returns the bracketed block around the cursor or, failing that,
+// the indented block around the cursor. It returns nil if there is neither.
+func (e *Editor) scope() *blockScope {
+	if open, openX, close, closeX, ok := enclosingBrackets(e.cursor.line, e.cursor.x); ok {
+		s := &blockScope{
+			lines:    make(map[*editorLine]bool),
+			column:   indentOf(open.values),
+			brackets: map[*editorLine]map[int]bool{open: {openX: true}},
+		}
+		if _, ok := s.brackets[close]; !ok {
+			s.brackets[close] = make(map[int]bool)
+		}
+		s.brackets[close][closeX] = true
+		for line := open.next; line != nil && line != close; line = line.next {
+			s.lines[line] = true
+		}
+		return s
+	}
+
+	values := e.cursor.line.values
+	indent := indentOf(values)
+	if isBlankLine(values) || indent == 0 {
+		return nil
+	}
+
+	first, last := e.cursor.line, e.cursor.line
+	for first.prev != nil && (isBlankLine(first.prev.values) || indentOf(first.prev.values) >= indent) {
+		first = first.prev
+	}
+	for last.next != nil && (isBlankLine(last.next.values) || indentOf(last.next.values) >= indent) {
+		last = last.next
+	}
+
+	// Trailing blank lines belong to the following block.
+	for last != e.cursor.line && isBlankLine(last.values) {
+		last = last.prev
+	}
+	for first != e.cursor.line && isBlankLine(first.values) {
+		first = first.next
+	}
+
+	s := &blockScope{lines: make(map[*editorLine]bool)}
+	if first.prev != nil {
+		s.column = indentOf(first.prev.values)
+	}
+	for line := first; line != last.next; line = line.next {
+		s.lines[line] = true
+	}
+	return s
+}
+
+// drawScopeGuide renders the scope guide for a row, from start in the line's view.
+func (e *Editor) drawScopeGuide(y, start int) {
+	if e.blockScope.column < start {
+		return
+	}
+	x := float64(e.textLeft() + (e.blockScope.column-start)*e.font_info.xUnit + 1)
+	top := float64(e.top_padding + y*e.font_info.yUnit)
+	ebitenutil.DrawLine(e.screen, x, top, x, top+float64(e.font_info.yUnit), e.scopeColor())
+}
+
+// scopeColor returns the color of the scope guide and the scope's brackets.
+func (e *Editor) scopeColor() color.Color {
+	c := color.NRGBAModel.Convert(e.font_color).(color.NRGBA)
+	c.A = scopeAlpha
+	return c
+}
@@ -0,0 +1,87 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestMatchBracket(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("f(a[1], {\n  b(2)\n})\n"))
+	line1 := editor.start
+	line3 := line1.next.next
+
+	line, x, ok := matchBracket(line1, 1)
+	if !ok || line != line3 || x != 1 {
+		t.Fatalf("Incorrect match for '(', got (%v,%v,%v)", line == line3, x, ok)
+	}
+
+	line, x, ok = matchBracket(line3, 0)
+	if !ok || line != line1 || x != 8 {
+		t.Fatalf("Incorrect match for '}', got (%v,%v,%v)", line == line1, x, ok)
+	}
+
+	if _, _, ok := matchBracket(line1, 0); ok {
+		t.Fatalf("Expected no match for a non-bracket")
+	}
+}
+
+func TestBracketScope(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("if {\n  a\n  b(c)\n}\n"))
+
+	editor.MoveCursor(2, 2)
+	scope := editor.scope()
+	if scope == nil || len(scope.lines) != 2 || scope.column != 0 {
+		t.Fatalf("Incorrect bracket scope: %v", scope)
+	}
+	if !scope.brackets[editor.start][3] || !scope.brackets[editor.start.next.next.next][0] {
+		t.Fatalf("Incorrect scope brackets: %v", scope.brackets)
+	}
+}
+
+func TestIndentScope(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("def f:\n    a\n\n    b\n\nc\n"))
+
+	editor.MoveCursor(1, 4)
+	scope := editor.scope()
+	if scope == nil || len(scope.lines) != 3 || scope.column != 0 {
+		t.Fatalf("Incorrect indent scope: %v", scope)
+	}
+
+	editor.MoveCursor(5, 0)
+	if scope := editor.scope(); scope != nil {
+		t.Fatalf("Expected no scope at the top level, got: %v", scope)
+	}
+}
+
+func TestJumpToBracket(t *testing.T) {
+	editor := NewEditor(WithBracketMatching(true))
+	editor.WriteText([]byte("f(a[1], {\n  b(2)\n})\n"))
+
+	// After the bracket, at the end of "(2)".
+	editor.MoveCursor(1, 6)
+	brackets := editor.matchingBrackets()
+	if len(brackets) != 1 || !brackets[editor.cursor.line][3] || !brackets[editor.cursor.line][5] {
+		t.Fatalf("Incorrect matching brackets, got %v", brackets)
+	}
+
+	table := [](struct{ row, col int }){
+		{1, 3},
+		{1, 5},
+	}
+	for _, entry := range table {
+		if !editor.JumpToBracket() {
+			t.Fatalf("Expected a jump to (%v,%v)", entry.row, entry.col)
+		}
+		row, col := editor.Cursor()
+		if row != entry.row || col != entry.col {
+			t.Fatalf("Incorrect jump, expected (%v,%v), got (%v,%v)", entry.row, entry.col, row, col)
+		}
+	}
+
+	editor.MoveCursor(1, 1)
+	if editor.JumpToBracket() || editor.matchingBrackets() != nil {
+		t.Fatalf("Expected no bracket by the cursor")
+	}
+}
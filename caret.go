@@ -0,0 +1,204 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"sort"
+)
+
+// Carets are kept as rune offsets from the start of the content, rather
+// than as lines, so that edits elsewhere can't leave them on a removed line.
+
+// AddCaret adds a caret at the position, in addition to the cursor.
+// Typing, Enter, Backspace and pasting then apply at every caret. It returns
+// false if there is already a caret at the position.
+func (e *Editor) AddCaret(row, col int) bool {
+	line := e.lineAt(row)
+	if col > len(line.values)-1 {
+		col = len(line.values) - 1
+	}
+	if col < 0 {
+		col = 0
+	}
+
+	offset := e.offsetOf(line, col)
+	if offset == e.offsetOf(e.cursor.line, e.cursor.x) {
+		return false
+	}
+	for _, c := range e.carets {
+		if c == offset {
+			return false
+		}
+	}
+	e.carets = append(e.carets, offset)
+	e.resetHighlight()
+
+	// Update the backing image.
+	e.updateImage()
+	return true
+}
+
+// Carets returns the positions of the carets added to the cursor.
+func (e *Editor) Carets() []Position {
+	positions := make([]Position, 0, len(e.carets))
+	for _, c := range e.carets {
+		line, x := e.positionOf(c)
+		positions = append(positions, Position{e.getLineNumberFromLine(line) - 1, x})
+	}
+	return positions
+}
+
+// ClearCarets removes the carets added to the cursor. Escape does the same.
+func (e *Editor) ClearCarets() {
+	e.carets = nil
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// offsetOf returns the rune offset of the position in the content.
+func (e *Editor) offsetOf(line *editorLine, x int) int {
+	offset := x
+	for cur := e.start; cur != line && cur != nil; cur = cur.next {
+		offset += len(cur.values)
+	}
+	return offset
+}
+
+// positionOf returns the position of the rune offset in the content,
+// clamped to the end of the content.
+func (e *Editor) positionOf(offset int) (*editorLine, int) {
+	line := e.start
+	for offset >= len(line.values) && line.next != nil {
+		offset -= len(line.values)
+		line = line.next
+	}
+	if offset > len(line.values)-1 {
+		offset = len(line.values) - 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return line, offset
+}
+
+// runeCount returns the number of runes in the content.
+func (e *Editor) runeCount() int {
+	return e.offsetOf(nil, 0)
+}
+
+// atCarets applies an edit at the cursor and at every caret, returning an
+// action which undoes them all.
+//
+// The carets are edited in order through the content. An edit only changes
+// the content around its caret, so the carets which follow keep their
+// distance from the end of the content, and those before keep their offset.
+func (e *Editor) atCarets(edit func() func() bool) func() bool {
+	e.dedupeCarets()
+	if len(e.carets) == 0 || e.mode != EDIT_MODE {
+		return edit()
+	}
+
+	type caret struct {
+		fromEnd int
+		cursor  bool
+	}
+	total := e.runeCount()
+	carets := []caret{{total - e.offsetOf(e.cursor.line, e.cursor.x), true}}
+	for _, c := range e.carets {
+		carets = append(carets, caret{total - c, false})
+	}
+	sort.Slice(carets, func(i, j int) bool { return carets[i].fromEnd > carets[j].fromEnd })
+
+	undos := make([]func() bool, 0, len(carets))
+	e.carets = e.carets[:0]
+	cursor := 0
+	for _, c := range carets {
+		e.cursor.line, e.cursor.x = e.positionOf(e.runeCount() - c.fromEnd)
+		undos = append(undos, edit())
+
+		offset := e.offsetOf(e.cursor.line, e.cursor.x)
+		if c.cursor {
+			cursor = offset
+		} else {
+			e.carets = append(e.carets, offset)
+		}
+	}
+	e.cursor.line, e.cursor.x = e.positionOf(cursor)
+	e.fixPosition()
+
+	return fnCompound(undos...)
+}
+
+// moveCarets moves the carets added to the cursor by one rune left or
+// right, one line up or down, or to the start or end of their line.
+func (e *Editor) moveCarets(right, left, up, down, home, end bool) {
+	for i, c := range e.carets {
+		line, x := e.positionOf(c)
+		switch {
+		case right:
+			line, x = e.positionOf(c + 1)
+		case left && c > 0:
+			line, x = e.positionOf(c - 1)
+		case up && line.prev != nil:
+			line = line.prev
+		case down && line.next != nil:
+			line = line.next
+		case home:
+			x = 0
+		case end:
+			x = len(line.values) - 1
+		}
+		if x > len(line.values)-1 {
+			x = len(line.values) - 1
+		}
+		e.carets[i] = e.offsetOf(line, x)
+	}
+	e.dedupeCarets()
+}
+
+// dedupeCarets removes carets which have met the cursor or each other.
+func (e *Editor) dedupeCarets() {
+	seen := map[int]bool{e.offsetOf(e.cursor.line, e.cursor.x): true}
+	carets := e.carets[:0]
+	for _, c := range e.carets {
+		if !seen[c] {
+			seen[c] = true
+			carets = append(carets, c)
+		}
+	}
+	e.carets = carets
+}
+
+// caretLines returns the rune indexes of the carets on each line.
+func (e *Editor) caretLines() map[*editorLine][]int {
+	if len(e.carets) == 0 {
+		return nil
+	}
+	lines := make(map[*editorLine][]int)
+	for _, c := range e.carets {
+		line, x := e.positionOf(c)
+		lines[line] = append(lines[line], x)
+	}
+	return lines
+}
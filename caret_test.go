@@ -0,0 +1,64 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAtCarets(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("ab\ncd\nef\n"))
+	editor.MoveCursor(1, 1)
+	editor.AddCaret(0, 0)
+	editor.AddCaret(2, 2)
+	if editor.AddCaret(1, 1) {
+		t.Fatalf("Expected no caret to be added at the cursor")
+	}
+
+	table := [](struct {
+		edit   func() func() bool
+		text   string
+		carets []Position
+	}){
+		{func() func() bool { return editor.fnHandleRuneSingle('x') }, "xab\ncxd\nefx\n", []Position{{0, 1}, {2, 3}}},
+		{func() func() bool { return editor.fnHandleRuneSingle('\n') }, "x\nab\ncx\nd\nefx\n\n", []Position{{1, 0}, {5, 0}}},
+		{editor.fnDeleteSinglePrevious, "xab\ncxd\nefx\n", []Position{{0, 1}, {2, 3}}},
+	}
+
+	for _, entry := range table {
+		editor.storeUndoAction(editor.atCarets(entry.edit))
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect text, expected %q, got %q", entry.text, got)
+		}
+		if carets := editor.Carets(); !reflect.DeepEqual(carets, entry.carets) {
+			t.Fatalf("Incorrect carets, expected %v, got %v", entry.carets, carets)
+		}
+	}
+
+	// Undo every edit.
+	for i := 0; i < len(table); i++ {
+		editor.undoStack[len(editor.undoStack)-1]()
+		editor.undoStack = editor.undoStack[:len(editor.undoStack)-1]
+	}
+	if got := string(editor.ReadText()); got != "ab\ncd\nef\n" {
+		t.Fatalf("Incorrect text after undo, got %q", got)
+	}
+}
+
+func TestMoveCarets(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("abc\nd\n"))
+	editor.AddCaret(0, 2)
+
+	editor.moveCarets(false, false, false, true, false, false)
+	if carets := editor.Carets(); !reflect.DeepEqual(carets, []Position{{1, 1}}) {
+		t.Fatalf("Incorrect carets after moving down, got %v", carets)
+	}
+
+	// A caret meeting the cursor is removed.
+	editor.moveCarets(false, false, false, false, true, false)
+	editor.moveCarets(false, false, true, false, false, false)
+	if carets := editor.Carets(); len(carets) != 0 {
+		t.Fatalf("Expected the caret to be removed, got %v", carets)
+	}
+}
@@ -0,0 +1,86 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "unicode"
+
+// caseChange returns the rune at x of the line's values in another case.
+type caseChange func(values []rune, x int) rune
+
+// upperCase returns the rune in upper case.
+func upperCase(values []rune, x int) rune {
+	return unicode.ToUpper(values[x])
+}
+
+// lowerCase returns the rune in lower case.
+func lowerCase(values []rune, x int) rune {
+	return unicode.ToLower(values[x])
+}
+
+// titleCase returns the rune in title case if it starts a word, or else in
+// lower case. An apostrophe within a word, as in "don't", doesn't start one.
+func titleCase(values []rune, x int) rune {
+	if x > 0 && isWordRune(values[x-1]) {
+		return unicode.ToLower(values[x])
+	}
+	if x > 1 && (values[x-1] == '\'' || values[x-1] == '’') && unicode.IsLetter(values[x-2]) {
+		return unicode.ToLower(values[x])
+	}
+	return unicode.ToTitle(values[x])
+}
+
+// fnChangeCase changes the case of the selected runes in place, as a single
+// undo step. The selection and cursor are kept.
+func (e *Editor) fnChangeCase(change caseChange) func() bool {
+	first, firstX, last, lastX, ok := e.selectionBounds()
+	if !ok {
+		return noop
+	}
+	firstRow := e.getLineNumberFromLine(first) - 1
+	lastRow := e.getLineNumberFromLine(last) - 1
+
+	changed := false
+	lines := make([][]rune, 0, lastRow-firstRow+1)
+	for line := first; ; line = line.next {
+		values := append([]rune{}, line.values...)
+		for x := range e.highlighted[line] {
+			if x < len(values) {
+				values[x] = change(line.values, x)
+				changed = changed || values[x] != line.values[x]
+			}
+		}
+		lines = append(lines, values)
+		if line == last {
+			break
+		}
+	}
+	if !changed {
+		return noop
+	}
+
+	row, x := e.getLineNumber(), e.cursor.x
+	undo := e.fnReplaceLines(firstRow, len(lines), lines)
+	e.highlightBetween(e.lineAt(firstRow), firstX, e.lineAt(lastRow), lastX+1)
+	e.MoveCursor(row, x)
+	return undo
+}
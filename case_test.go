@@ -0,0 +1,34 @@
+package noter
+
+import "testing"
+
+func TestChangeCase(t *testing.T) {
+	table := [](struct {
+		command string
+		want    string
+	}){
+		{"UpperCase", "a DON'T STOP\nBELIEVIN' ok\n"},
+		{"LowerCase", "a don't stop\nbelievin' ok\n"},
+		{"TitleCase", "a Don't Stop\nBelievin' ok\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte("a don't STOP\nbelievin' ok\n"))
+		editor.highlightBetween(editor.lineAt(0), 2, editor.lineAt(1), 9)
+		editor.MoveCursor(1, 9)
+
+		editor.RunCommand(entry.command)
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect %v, expected %q, got %q", entry.command, entry.want, got)
+		}
+		if got := string(editor.getHighlightedRunes()); len(got) != len("don't STOP\nbelievin'") {
+			t.Fatalf("Expected the selection to be kept after %v, got %q", entry.command, got)
+		}
+
+		editor.RunCommand("Undo")
+		if got := string(editor.ReadText()); got != "a don't STOP\nbelievin' ok\n" {
+			t.Fatalf("Incorrect undo of %v, got %q", entry.command, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "unicode"
+
+// checkboxMark returns the index of the mark in the task list checkbox
+// which begins the line, such as the space of "- [ ] ", or -1 if there is
+// none. The list item may be bulleted by '-', '*' or '+', or numbered.
+func checkboxMark(values []rune) int {
+	i := 0
+	for i < len(values) && (values[i] == ' ' || values[i] == '\t') {
+		i++
+	}
+
+	// The bullet, or number.
+	switch {
+	case i < len(values) && (values[i] == '-' || values[i] == '*' || values[i] == '+'):
+		i++
+	case i < len(values) && unicode.IsDigit(values[i]):
+		for i < len(values) && unicode.IsDigit(values[i]) {
+			i++
+		}
+		if i == len(values) || (values[i] != '.' && values[i] != ')') {
+			return -1
+		}
+		i++
+	default:
+		return -1
+	}
+
+	// A space, then the checkbox, then a space or the end of the line.
+	if i+4 >= len(values) || values[i] != ' ' || values[i+1] != '[' || values[i+3] != ']' {
+		return -1
+	}
+	if next := values[i+4]; next != ' ' && next != '\n' {
+		return -1
+	}
+	switch values[i+2] {
+	case ' ', 'x', 'X':
+		return i + 2
+	}
+	return -1
+}
+
+// ToggleCheckbox checks or unchecks the Markdown task list checkbox on the
+// cursor line, such as "- [ ] task". It returns false if the line has no
+// checkbox.
+func (e *Editor) ToggleCheckbox() bool {
+	if !e.canEdit() {
+		return false
+	}
+	line := e.cursor.line
+	mark := checkboxMark(line.values)
+	if mark < 0 {
+		return false
+	}
+
+	row := e.getLineNumber()
+	old := line.values[mark]
+	if old == ' ' {
+		line.values[mark] = 'x'
+		e.announce("checked")
+	} else {
+		line.values[mark] = ' '
+		e.announce("unchecked")
+	}
+	e.storeUndoAction(func() bool {
+		e.MoveCursor(row, mark)
+		e.cursor.line.values[mark] = old
+		e.invalidateHighlight(e.cursor.line)
+		return true
+	})
+	e.setModified()
+	return true
+}
+
+// clickCheckbox toggles the checkbox if the position is within its
+// brackets, returning true if it did.
+func (e *Editor) clickCheckbox(line *editorLine, x int) bool {
+	mark := checkboxMark(line.values)
+	if mark < 0 || x < mark-1 || x > mark+1 {
+		return false
+	}
+	e.cursor.line, e.cursor.x = line, x
+	return e.ToggleCheckbox()
+}
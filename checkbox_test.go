@@ -0,0 +1,56 @@
+package noter
+
+import "testing"
+
+func TestCheckboxMark(t *testing.T) {
+	table := [](struct {
+		line string
+		mark int
+	}){
+		{"- [ ] task\n", 3},
+		{"  * [x] task\n", 5},
+		{"12. [X]\n", 5},
+		{"1) [ ] task\n", 4},
+		{"- [] task\n", -1},
+		{"- [y] task\n", -1},
+		{"[ ] task\n", -1},
+		{"-[ ] task\n", -1},
+		{"- [ ]task\n", -1},
+		{"1 [ ] task\n", -1},
+	}
+
+	for _, entry := range table {
+		if mark := checkboxMark([]rune(entry.line)); mark != entry.mark {
+			t.Fatalf("Incorrect mark for %q, expected %v, got %v", entry.line, entry.mark, mark)
+		}
+	}
+}
+
+func TestToggleCheckbox(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("todo\n- [ ] milk\n"))
+	editor.MoveCursor(1, 7)
+
+	editor.ToggleCheckbox()
+	if got := string(editor.ReadText()); got != "todo\n- [x] milk\n" {
+		t.Fatalf("Incorrect checked text, got %q", got)
+	}
+	editor.ToggleCheckbox()
+	if got := string(editor.ReadText()); got != "todo\n- [ ] milk\n" {
+		t.Fatalf("Incorrect unchecked text, got %q", got)
+	}
+
+	editor.runCommand("z")
+	if got := string(editor.ReadText()); got != "todo\n- [x] milk\n" {
+		t.Fatalf("Incorrect undone text, got %q", got)
+	}
+	editor.runCommand("z")
+	if got := string(editor.ReadText()); got != "todo\n- [ ] milk\n" {
+		t.Fatalf("Incorrect undone text, got %q", got)
+	}
+
+	editor.MoveCursor(0, 0)
+	if editor.ToggleCheckbox() {
+		t.Fatalf("Expected no checkbox on the first line")
+	}
+}
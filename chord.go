@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "time"
+
+const EDITOR_CHORD_TIMEOUT = 1500 * time.Millisecond
+
+// BindChord binds a two-stroke chord of COMMAND keys to an action, such as
+// "k" then "c" for COMMAND-K COMMAND-C. Once the first key is pressed, the
+// second must follow within EDITOR_CHORD_TIMEOUT. A first key which begins
+// a chord is no longer available as a single command.
+func (e *Editor) BindChord(first, second string, action func()) {
+	if e.chords == nil {
+		e.chords = make(map[string]map[string]func())
+	}
+	if e.chords[first] == nil {
+		e.chords[first] = make(map[string]func())
+	}
+	e.chords[first][second] = action
+}
+
+// PendingChord returns the first key of a chord waiting for its second key,
+// or an empty string.
+func (e *Editor) PendingChord() string {
+	return e.pendingChord
+}
+
+// handleChord begins or completes a chord with a COMMAND key.
+// It returns true if the key was taken by a chord.
+func (e *Editor) handleChord(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	if first := e.pendingChord; first != "" && first != escapeChord {
+		e.pendingChord = ""
+		if action, ok := e.chords[first][key]; ok {
+			action()
+		} else {
+			e.Notify(e.tr("(%s %s) is not a chord", first, key))
+		}
+		return true
+	}
+
+	if _, ok := e.chords[key]; ok {
+		e.pendingChord = key
+		e.chordUntil = time.Now().Add(EDITOR_CHORD_TIMEOUT)
+		return true
+	}
+	return false
+}
+
+// expireChord forgets a pending chord once its time has passed.
+func (e *Editor) expireChord() {
+	if e.pendingChord != "" && time.Now().After(e.chordUntil) {
+		e.pendingChord = ""
+	}
+}
@@ -0,0 +1,36 @@
+package noter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChord(t *testing.T) {
+	editor := NewEditor()
+	commented := 0
+	editor.BindChord("k", "c", func() { commented++ })
+
+	if editor.handleChord("c") {
+		t.Fatalf("Expected the second key alone not to be taken")
+	}
+	if !editor.handleChord("k") || editor.PendingChord() != "k" {
+		t.Fatalf("Expected the first key to begin the chord")
+	}
+	if !editor.handleChord("c") || commented != 1 || editor.PendingChord() != "" {
+		t.Fatalf("Expected the chord to complete, got: %v", commented)
+	}
+
+	// An unbound second key cancels the chord.
+	editor.handleChord("k")
+	if !editor.handleChord("x") || commented != 1 {
+		t.Fatalf("Expected an unbound chord to be taken without action")
+	}
+
+	// A chord times out.
+	editor.handleChord("k")
+	editor.chordUntil = time.Now().Add(-time.Second)
+	editor.expireChord()
+	if editor.PendingChord() != "" {
+		t.Fatalf("Expected the chord to time out")
+	}
+}
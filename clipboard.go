@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// inputChanged updates the editor after the input has changed.
+func (e *Editor) inputChanged() {
+	if e.mode == SEARCH_MODE {
+		e.search()
+	}
+	if e.mode == PROMPT_MODE && e.promptInput != nil {
+		e.promptInput(string(e.promptTerm))
+	}
+}
+
+// inputCopy returns the runes to copy from the input: the selection,
+// or else all of the input.
+func (e *Editor) inputCopy() []rune {
+	if e.input.runes == nil {
+		return nil
+	}
+	if selected := e.input.selected(); len(selected) > 0 {
+		return selected
+	}
+	return *e.input.runes
+}
+
+// pasteInput inserts the first line of the clipboard into the input,
+// as plain text if plain is true.
+func (e *Editor) pasteInput(plain bool) {
+	line := []rune{}
+	text := []rune(string(e.clipboard.ReadText()))
+	if plain || e.plain_paste {
+		text = plainText(text)
+	}
+	for _, r := range text {
+		if r == '\r' || r == '\n' {
+			break
+		}
+		line = append(line, r)
+	}
+	e.input.insert(line)
+	e.inputChanged()
+}
+
+// copyInput copies the input to the clipboard.
+func (e *Editor) copyInput() {
+	if rs := e.inputCopy(); len(rs) > 0 {
+		e.clipboard.WriteText([]byte(string(rs)))
+	}
+}
+
+// cutInput copies the input to the clipboard, and deletes it.
+func (e *Editor) cutInput() {
+	rs := e.inputCopy()
+	if len(rs) == 0 {
+		return
+	}
+	e.clipboard.WriteText([]byte(string(rs)))
+	if !e.input.deleteSelection() {
+		*e.input.runes = make([]rune, 0)
+		e.input.cursor = 0
+	}
+	e.inputChanged()
+}
@@ -0,0 +1,28 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestClipboardInSearchMode(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("cat\ndog\n"))
+	editor.clipboard.WriteText([]byte("do\nignored"))
+
+	editor.searchMode()
+	editor.pasteInput(false)
+	if got := string(editor.searchTerm); got != "do" {
+		t.Fatalf("Expected the first line pasted into the search term, got: %q", got)
+	}
+	if got := string(editor.ReadText()); got != "cat\ndog\n" || editor.IsModified() {
+		t.Fatalf("Expected the content to be unchanged, got: %q", got)
+	}
+	if editor.MatchCount() != 1 {
+		t.Fatalf("Expected the pasted term to be searched, got: %v matches", editor.MatchCount())
+	}
+
+	editor.cutInput()
+	if got := string(editor.clipboard.ReadText()); got != "do" || len(editor.searchTerm) != 0 {
+		t.Fatalf("Expected the search term to be cut, got: %q, %q", got, string(editor.searchTerm))
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 Andrew Healey
+//
+// Reading the key bindings file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/healeycodes/noter"
+)
+
+// keyConfig is the content of the key bindings file.
+type keyConfig struct {
+	keymap   string
+	bindings map[string]string
+}
+
+// configDir returns the directory of configuration files, ~/.config, or
+// $XDG_CONFIG_HOME if set.
+func configDir() (string, error) {
+	if config := os.Getenv("XDG_CONFIG_HOME"); config != "" {
+		return config, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config"), nil
+}
+
+// keysFile returns the path of the key bindings file,
+// ~/.config/noter/keys.toml, or under $XDG_CONFIG_HOME if set.
+func keysFile() (string, error) {
+	config, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(config, "noter", "keys.toml"), nil
+}
+
+// readKeys reads the key bindings file, which may choose the keymap and
+// bind keystrokes to the names of commands:
+//
+//	keymap = "emacs"
+//
+//	[bindings]
+//	"cmd+k" = "DeleteLine"
+//	"ctrl+alt+s" = "Save"
+//
+// This is the subset of TOML of string keys and values, and comments.
+// A missing file is empty.
+func readKeys(file_path string) (config keyConfig, err error) {
+	config.bindings = make(map[string]string)
+
+	err = readTOML(file_path, func(table, key, value string) error {
+		switch {
+		case table == "" && key == "keymap":
+			config.keymap = value
+		case table == "bindings":
+			config.bindings[key] = value
+		default:
+			return fmt.Errorf("unknown key %q", key)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	return
+}
+
+// readTOML reads the subset of TOML of string keys and values, tables and
+// comments, calling set with each key and value, and the table it's in, or
+// "" before the first table. An error from set is reported at its line.
+func readTOML(file_path string, set func(table, key, value string) error) error {
+	file, err := os.Open(file_path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	table := ""
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected key = value", file_path, lineno)
+		}
+		if key, err = unquoteTOML(strings.TrimSpace(key)); err != nil {
+			return fmt.Errorf("%s:%d: %w", file_path, lineno, err)
+		}
+		if value, err = unquoteTOML(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("%s:%d: %w", file_path, lineno, err)
+		}
+		if err = set(table, key, value); err != nil {
+			return fmt.Errorf("%s:%d: %w", file_path, lineno, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// stripComment removes a '#' comment which is outside of quotes.
+func stripComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquoteTOML returns a bare key, or the content of a basic ("...") or
+// literal ('...') string.
+func unquoteTOML(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		return strconv.Unquote(s)
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1], nil
+	case s != "" && !strings.ContainsAny(s, " \t\"'"):
+		return s, nil
+	}
+	return "", fmt.Errorf("expected a string, got %q", s)
+}
+
+// bindKeys applies the bindings to the editor, warning of any which
+// can't be bound.
+func bindKeys(editor *noter.Editor, bindings map[string]string) {
+	commands := make(map[string]bool)
+	for _, command := range editor.Commands() {
+		commands[command] = true
+	}
+
+	for keystroke, command := range bindings {
+		if command != "" && !commands[command] {
+			fmt.Fprintf(os.Stderr, "noter: unknown command %q bound to %q\n", command, keystroke)
+			continue
+		}
+		if err := editor.Bind(keystroke, command); err != nil {
+			fmt.Fprintf(os.Stderr, "noter: %v\n", err)
+		}
+	}
+}
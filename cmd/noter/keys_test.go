@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadKeys(t *testing.T) {
+	table := [](struct {
+		text     string
+		keymap   string
+		bindings map[string]string
+		fails    bool
+	}){
+		{"", "", map[string]string{}, false},
+		{"keymap = \"emacs\"\n", "emacs", map[string]string{}, false},
+		{"# comment\n[bindings]\n\"cmd+k\" = \"DeleteLine\" # trailing\n'ctrl+#' = 'Save'\n",
+			"", map[string]string{"cmd+k": "DeleteLine", "ctrl+#": "Save"}, false},
+		{"[bindings]\n\"cmd+k\" = \"\"\n", "", map[string]string{"cmd+k": ""}, false},
+		{"keymap\n", "", nil, true},
+		{"keymap = emacs vim\n", "", nil, true},
+		{"colors = \"dark\"\n", "", nil, true},
+		{"[bindings]\n\"cmd+k = \"DeleteLine\"\n", "", nil, true},
+	}
+
+	for _, entry := range table {
+		file_path := filepath.Join(t.TempDir(), "keys.toml")
+		if err := os.WriteFile(file_path, []byte(entry.text), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		config, err := readKeys(file_path)
+		if (err != nil) != entry.fails {
+			t.Fatalf("Incorrect error for %q, expected failure %v, got %v", entry.text, entry.fails, err)
+		}
+		if entry.fails {
+			continue
+		}
+		if config.keymap != entry.keymap {
+			t.Fatalf("Incorrect keymap for %q, expected %q, got %q", entry.text, entry.keymap, config.keymap)
+		}
+		if len(config.bindings) != len(entry.bindings) {
+			t.Fatalf("Incorrect bindings for %q, expected %v, got %v", entry.text, entry.bindings, config.bindings)
+		}
+		for keystroke, command := range entry.bindings {
+			if got, ok := config.bindings[keystroke]; !ok || got != command {
+				t.Fatalf("Incorrect binding of %q for %q, expected %q, got %q", keystroke, entry.text, command, got)
+			}
+		}
+	}
+}
+
+func TestReadKeysMissing(t *testing.T) {
+	config, err := readKeys(filepath.Join(t.TempDir(), "keys.toml"))
+	if err != nil || config.keymap != "" || len(config.bindings) != 0 {
+		t.Fatalf("Expected a missing file to be empty, got %v, %v", config, err)
+	}
+}
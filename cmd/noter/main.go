@@ -11,12 +11,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/flopp/go-findfont"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/healeycodes/noter"
 	"golang.design/x/clipboard"
-	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 )
 
@@ -31,6 +33,10 @@ func (cb *clipBoard) WriteText(content []byte) {
 	clipboard.Write(clipboard.FmtText, content)
 }
 
+func (cb *clipBoard) ReadImage() []byte {
+	return clipboard.Read(clipboard.FmtImage)
+}
+
 type fileContent struct {
 	FilePath string
 }
@@ -68,22 +74,109 @@ func (fc *fileContent) WriteText(content []byte) {
 	}
 }
 
+// PasteImage saves a pasted image beside the file, and returns a markdown
+// link to it.
+func (fc *fileContent) PasteImage(image []byte) string {
+	name := fmt.Sprintf("%s-%d.png", strings.TrimSuffix(fc.FileName(), path.Ext(fc.FilePath)), time.Now().Unix())
+	err := os.WriteFile(path.Join(path.Dir(fc.FilePath), name), image, 0644)
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("![](%s)", name)
+}
+
 type options struct {
 	font_name string
 	font_size float64
 	font_dpi  float64
+	hard_wrap int
+	focus     int
+	soft_wrap bool
+	numbers   bool
+	contrast  bool
+	keymap    string
+	prose     bool
+	theme     string
+	dark      bool
+	spaces    bool
+	indent    bool
+	newline   bool
+	encoding  string
+	bom       bool
 }
 
 func init() {
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage of noter:\n\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "noter [flags] <filename>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "noter [flags] <filename>[:line[:column]]\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "noter [flags] +line[:column] <filename>\n")
 		flag.PrintDefaults()
 	}
 }
 
-func execute(file_path string, opts *options) (err error) {
-	var font_face font.Face
+// keymapNamed returns the keymap with the name, or the default keymap.
+func keymapNamed(name string) *noter.Keymap {
+	switch name {
+	case noter.KeymapVim.Name():
+		return noter.KeymapVim
+	case noter.KeymapEmacs.Name():
+		return noter.KeymapEmacs
+	}
+	return noter.KeymapDefault
+}
+
+// isMarkdown returns true if the file holds Markdown.
+func isMarkdown(file_path string) bool {
+	switch strings.ToLower(path.Ext(file_path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// isTable returns true if the file holds CSV/TSV data.
+func isTable(file_path string) bool {
+	switch strings.ToLower(path.Ext(file_path)) {
+	case ".csv", ".tsv":
+		return true
+	}
+	return false
+}
+
+// fileAddress returns the file named by the arguments, and the position to
+// open it at, from "notes.txt:12:5" or "+12:5 notes.txt". It returns false
+// if the arguments don't address a position.
+func fileAddress(args []string) (file_path string, pos noter.Position, addressed bool) {
+	if len(args) > 1 && strings.HasPrefix(args[0], "+") {
+		_, pos = noter.ParseAddress(":" + args[0][1:])
+		return args[1], pos, true
+	}
+	if _, err := os.Stat(args[0]); err == nil {
+		// The file exists, even if its name looks like an address.
+		return args[0], pos, false
+	}
+	file_path, pos = noter.ParseAddress(args[0])
+	return file_path, pos, file_path != args[0]
+}
+
+// dirFiles returns the names of the files in the directory, other than
+// hidden files, for the switcher.
+func dirFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func execute(file_path string, pos noter.Position, addressed bool, opts *options) (err error) {
+	var font_sfnt *opentype.Font
 
 	if len(opts.font_name) > 0 {
 		var font_path string
@@ -98,41 +191,125 @@ func execute(file_path string, opts *options) (err error) {
 			return
 		}
 
-		var font_sfnt *opentype.Font
 		font_sfnt, err = opentype.Parse(font_data)
 		if err != nil {
 			return
 		}
+	}
+
+	keys_path, err := keysFile()
+	if err != nil {
+		return
+	}
+	// A malformed key bindings file leaves the default keys, with a notice
+	// once the editor is open.
+	keys, keys_err := readKeys(keys_path)
+	if keys_err != nil {
+		keys = keyConfig{}
+	}
+	if opts.keymap == "" {
+		opts.keymap = keys.keymap
+	}
+
+	if opts.dark {
+		opts.theme = "dark"
+	}
+	theme, err := loadTheme(opts.theme)
+	if err != nil {
+		return
+	}
+
+	// Without an address, open the file where it was last left.
+	session_path, err := sessionFile()
+	if err != nil {
+		return
+	}
+	session, err := readSession(session_path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "noter: %v\n", err)
+	}
+	if !addressed {
+		if abs_path, err := filepath.Abs(file_path); err == nil {
+			if left, ok := sessionPosition(session, abs_path); ok {
+				pos = left
+			}
+		}
+	}
+
+	content := &fileContent{FilePath: file_path}
 
-		font_opts := opentype.FaceOptions{
-			Size: opts.font_size,
-			DPI:  opts.font_dpi,
+	// remember records where the open file was left, in the session.
+	var editor *noter.Editor
+	remember := func() {
+		content, ok := editor.Content().(*fileContent)
+		if !ok {
+			return
 		}
-		font_face, err = opentype.NewFace(font_sfnt, &font_opts)
+		abs_path, err := filepath.Abs(content.FilePath)
 		if err != nil {
 			return
 		}
-		defer font_face.Close()
+		row, col := editor.Cursor()
+		session = rememberPosition(session, abs_path, noter.Position{Row: row, Col: col})
+		if err := writeSession(session_path, session); err != nil {
+			fmt.Fprintf(os.Stderr, "noter: %v\n", err)
+		}
 	}
 
-	content := &fileContent{FilePath: file_path}
-
-	editor := noter.NewEditor(
+	editor = noter.NewEditor(
 		noter.WithClipboard(&clipBoard{}),
-		noter.WithContent(content),
-		noter.WithContentName(content.FileName()),
+		noter.WithOnPasteImage(content.PasteImage),
 		noter.WithTopBar(true),
 		noter.WithBottomBar(true),
-		noter.WithFontFace(font_face),
-		noter.WithQuit(func() { os.Exit(0) }),
+		noter.WithFont(font_sfnt, opts.font_size, opts.font_dpi),
+		noter.WithTableMode(isTable(file_path)),
+		noter.WithListEditing(isMarkdown(file_path)),
+		noter.WithProseMode(opts.prose),
+		noter.WithStreamingLoad(true),
+		noter.WithHardWrap(opts.hard_wrap),
+		noter.WithFocusMode(opts.focus),
+		noter.WithFocusDimming(opts.focus > 0),
+		noter.WithSoftWrap(opts.soft_wrap),
+		noter.WithShowWhitespace(opts.spaces),
+		noter.WithAutoIndent(opts.indent),
+		noter.WithFinalNewline(opts.newline),
+		noter.WithEncoding(opts.encoding),
+		noter.WithKeepBOM(opts.bom),
+		noter.WithColorSwatches(true),
+		noter.WithBracketMatching(true),
+		noter.WithIndentAfter("{:"),
+		noter.WithLineNumbers(opts.numbers),
+		noter.WithTheme(theme),
+		noter.WithHighContrast(opts.contrast),
+		noter.WithKeymap(keymapNamed(opts.keymap)),
+		noter.WithScopeHighlight(true),
+		noter.WithScrollbar(true),
+		noter.WithSwitcher(
+			func() []string { return dirFiles(path.Dir(file_path)) },
+			func(name string) noter.Content {
+				return &fileContent{FilePath: path.Join(path.Dir(file_path), name)}
+			},
+		),
+		noter.WithQuit(func() {
+			remember()
+			os.Exit(0)
+		}),
 	)
 
+	editor.BindFunctionKey(ebiten.KeyF1, "Tutorial")
+	bindKeys(editor, keys.bindings)
+	editor.OpenAt(content, content.FileName(), pos.Row, pos.Col)
+	if keys_err != nil {
+		editor.Notify(fmt.Sprintf("%v, using the default keys", keys_err))
+	}
+
 	width, height := editor.Size()
 	ebiten.SetWindowSize(width, height)
 	ebiten.SetWindowTitle("noter")
 	if err = ebiten.RunGame(editor); err != nil {
 		return
 	}
+	remember()
 
 	return
 }
@@ -143,19 +320,35 @@ func main() {
 	flag.StringVar(&opts.font_name, "font", "", "TrueType font name")
 	flag.Float64Var(&opts.font_size, "fontsize", 12.0, "Font size")
 	flag.Float64Var(&opts.font_dpi, "fontdpi", 96.0, "Font DPI")
+	flag.IntVar(&opts.hard_wrap, "wrap", 0, "Hard wrap column while typing (0 disables)")
+	flag.IntVar(&opts.focus, "focus", 0, "Distraction-free centered column width (0 disables)")
+	flag.BoolVar(&opts.soft_wrap, "softwrap", false, "Soft wrap long lines")
+	flag.BoolVar(&opts.spaces, "whitespace", false, "Show spaces and tabs")
+	flag.BoolVar(&opts.indent, "autoindent", false, "Indent new lines as the line before")
+	flag.BoolVar(&opts.newline, "newline", true, "End saved files with a new line (-newline=false keeps them as they were)")
+	flag.StringVar(&opts.encoding, "encoding", "", "Encoding: UTF-8, UTF-16LE, UTF-16BE or Latin-1 (detected if unset)")
+	flag.BoolVar(&opts.bom, "bom", true, "Keep the byte order mark of files which have one")
+	flag.BoolVar(&opts.numbers, "numbers", false, "Show line numbers")
+	flag.BoolVar(&opts.contrast, "contrast", false, "Use high-contrast colors")
+	flag.BoolVar(&opts.prose, "prose", false, "Prose mode, where shift+enter enters a soft break")
+	flag.StringVar(&opts.theme, "theme", "light", "Colors: light, dark, or a .toml or .json theme file")
+	flag.BoolVar(&opts.dark, "dark", false, "Use the dark theme, as -theme dark")
+	flag.StringVar(&opts.keymap, "keymap", "", "Key bindings: default, vim or emacs (overrides keys.toml)")
 
 	flag.Parse()
 
 	var filePath string
+	var pos noter.Position
+	var addressed bool
 	if flag.NArg() < 1 {
 		flag.Usage()
 		os.Exit(1)
 	} else {
 		// This is the way
-		filePath = flag.Arg(0)
+		filePath, pos, addressed = fileAddress(flag.Args())
 	}
 
-	err := execute(filePath, &opts)
+	err := execute(filePath, pos, addressed, &opts)
 
 	if err != nil {
 		panic(err)
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 Andrew Healey
+//
+// Remembering where each file was left.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/healeycodes/noter"
+)
+
+// sessionFiles is the number of files whose positions are remembered.
+const sessionFiles = 100
+
+// sessionEntry is the position a file was left at.
+type sessionEntry struct {
+	file_path string
+	pos       noter.Position
+}
+
+// sessionFile returns the path of the session file,
+// ~/.config/noter/session.txt, or under $XDG_CONFIG_HOME if set.
+func sessionFile() (string, error) {
+	config, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(config, "noter", "session.txt"), nil
+}
+
+// readSession reads the session file, of a line for each file with the
+// row and column it was left at, and its absolute path:
+//
+//	12 5 /home/me/notes.txt
+//
+// The most recently left file is last. A missing file is empty, and
+// malformed lines are skipped.
+func readSession(file_path string) ([]sessionEntry, error) {
+	file, err := os.Open(file_path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []sessionEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		row, err := strconv.Atoi(fields[0])
+		if err != nil || row < 0 {
+			continue
+		}
+		col, err := strconv.Atoi(fields[1])
+		if err != nil || col < 0 {
+			continue
+		}
+		entries = append(entries, sessionEntry{fields[2], noter.Position{Row: row, Col: col}})
+	}
+	return entries, scanner.Err()
+}
+
+// writeSession writes the session file.
+func writeSession(file_path string, entries []sessionEntry) error {
+	if err := os.MkdirAll(filepath.Dir(file_path), 0o755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%d %d %s\n", entry.pos.Row, entry.pos.Col, entry.file_path)
+	}
+	return os.WriteFile(file_path, []byte(b.String()), 0o644)
+}
+
+// sessionPosition returns the position the file was left at, and false if
+// it isn't remembered.
+func sessionPosition(entries []sessionEntry, file_path string) (noter.Position, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].file_path == file_path {
+			return entries[i].pos, true
+		}
+	}
+	return noter.Position{}, false
+}
+
+// rememberPosition returns the entries with the file left at the position,
+// as the most recent, forgetting the least recent beyond sessionFiles.
+func rememberPosition(entries []sessionEntry, file_path string, pos noter.Position) []sessionEntry {
+	kept := make([]sessionEntry, 0, len(entries)+1)
+	for _, entry := range entries {
+		if entry.file_path != file_path {
+			kept = append(kept, entry)
+		}
+	}
+	kept = append(kept, sessionEntry{file_path, pos})
+	if len(kept) > sessionFiles {
+		kept = kept[len(kept)-sessionFiles:]
+	}
+	return kept
+}
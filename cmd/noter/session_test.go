@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/healeycodes/noter"
+)
+
+func TestSession(t *testing.T) {
+	file_path := filepath.Join(t.TempDir(), "noter", "session.txt")
+	entries, err := readSession(file_path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty session, got %v, %v", entries, err)
+	}
+
+	entries = rememberPosition(entries, "/a.txt", noter.Position{Row: 3, Col: 1})
+	entries = rememberPosition(entries, "/b c.txt", noter.Position{Row: 7, Col: 0})
+	entries = rememberPosition(entries, "/a.txt", noter.Position{Row: 4, Col: 2})
+	if err := writeSession(file_path, entries); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entries, err = readSession(file_path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	table := [](struct {
+		file_path string
+		pos       noter.Position
+		ok        bool
+	}){
+		{"/a.txt", noter.Position{Row: 4, Col: 2}, true},
+		{"/b c.txt", noter.Position{Row: 7, Col: 0}, true},
+		{"/d.txt", noter.Position{}, false},
+	}
+
+	for _, entry := range table {
+		pos, ok := sessionPosition(entries, entry.file_path)
+		if pos != entry.pos || ok != entry.ok {
+			t.Fatalf("Incorrect position for %v, expected %v %v, got %v %v", entry.file_path, entry.pos, entry.ok, pos, ok)
+		}
+	}
+	if len(entries) != 2 || entries[1].file_path != "/a.txt" {
+		t.Fatalf("Expected the latest file last, got %v", entries)
+	}
+}
+
+func TestSessionLimit(t *testing.T) {
+	var entries []sessionEntry
+	for i := 0; i <= sessionFiles; i++ {
+		entries = rememberPosition(entries, filepath.Join("/", string(rune('a'+i%26)), string(rune('a'+i/26))), noter.Position{Row: i})
+	}
+	if len(entries) != sessionFiles {
+		t.Fatalf("Incorrect entries, expected %v, got %v", sessionFiles, len(entries))
+	}
+	if _, ok := sessionPosition(entries, "/a/a"); ok {
+		t.Fatalf("Expected the least recent file to be forgotten")
+	}
+}
+
+func TestReadSessionMalformed(t *testing.T) {
+	file_path := filepath.Join(t.TempDir(), "session.txt")
+	text := "1 2 /a.txt\nx 2 /b.txt\n-1 0 /c.txt\n3\n"
+	if err := os.WriteFile(file_path, []byte(text), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entries, err := readSession(file_path)
+	if err != nil || len(entries) != 1 || entries[0].file_path != "/a.txt" {
+		t.Fatalf("Expected only the well-formed line, got %v, %v", entries, err)
+	}
+}
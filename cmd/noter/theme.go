@@ -0,0 +1,110 @@
+// Copyright (c) 2024 Andrew Healey
+//
+// Reading theme files.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/healeycodes/noter"
+)
+
+// themeNamed returns the built-in theme with the name.
+func themeNamed(name string) (noter.Theme, bool) {
+	switch name {
+	case "light":
+		return noter.ThemeLight, true
+	case "dark":
+		return noter.ThemeDark, true
+	}
+	return noter.Theme{}, false
+}
+
+// loadTheme returns the theme named by the -theme flag: "light", "dark",
+// or the path of a theme file.
+func loadTheme(name string) (noter.Theme, error) {
+	if theme, ok := themeNamed(name); ok {
+		return theme, nil
+	}
+	return readTheme(name)
+}
+
+// readTheme reads a theme file, of colors as "#rrggbb" or "#rrggbbaa":
+//
+//	base = "dark"
+//	font = "#e0e0e0"
+//	selection = "#4080ff5a"
+//
+// The keys are base (a built-in theme for the colors not set), font,
+// background, selection, search, cursor, line_numbers, bars and brackets.
+// A file ending in .json holds an object of the same keys, and others are
+// the subset of TOML of keys.toml.
+func readTheme(file_path string) (theme noter.Theme, err error) {
+	values := make(map[string]string)
+	if strings.ToLower(filepath.Ext(file_path)) == ".json" {
+		var data []byte
+		if data, err = os.ReadFile(file_path); err != nil {
+			return
+		}
+		if err = json.Unmarshal(data, &values); err != nil {
+			return theme, fmt.Errorf("%s: %w", file_path, err)
+		}
+	} else if err = readTOML(file_path, func(table, key, value string) error {
+		if table != "" {
+			return fmt.Errorf("unknown table %q", table)
+		}
+		values[key] = value
+		return nil
+	}); err != nil {
+		return
+	}
+
+	if base, ok := values["base"]; ok {
+		if theme, ok = themeNamed(base); !ok {
+			return theme, fmt.Errorf("%s: unknown base theme %q", file_path, base)
+		}
+	}
+
+	colors := map[string]*color.Color{
+		"font":         &theme.Font,
+		"background":   &theme.Background,
+		"selection":    &theme.Selection,
+		"search":       &theme.Search,
+		"cursor":       &theme.Cursor,
+		"line_numbers": &theme.LineNumbers,
+		"bars":         &theme.Bars,
+		"brackets":     &theme.Brackets,
+	}
+	for key, value := range values {
+		if key == "base" {
+			continue
+		}
+		c, ok := colors[key]
+		if !ok {
+			return theme, fmt.Errorf("%s: unknown key %q", file_path, key)
+		}
+		if *c, err = parseColor(value); err != nil {
+			return theme, fmt.Errorf("%s: %s: %w", file_path, key, err)
+		}
+	}
+	return theme, nil
+}
+
+// parseColor parses a color as "#rrggbb", or "#rrggbbaa" with alpha.
+func parseColor(s string) (color.Color, error) {
+	rgba, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || !strings.HasPrefix(s, "#") || (len(rgba) != 3 && len(rgba) != 4) {
+		return nil, fmt.Errorf("expected a color as #rrggbb or #rrggbbaa, got %q", s)
+	}
+	if len(rgba) == 3 {
+		rgba = append(rgba, 0xff)
+	}
+	return color.NRGBA{rgba[0], rgba[1], rgba[2], rgba[3]}, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/healeycodes/noter"
+)
+
+func TestParseColor(t *testing.T) {
+	table := [](struct {
+		text  string
+		color color.Color
+		fails bool
+	}){
+		{"#e0e0e0", color.NRGBA{0xe0, 0xe0, 0xe0, 0xff}, false},
+		{"#4080FF5a", color.NRGBA{0x40, 0x80, 0xff, 0x5a}, false},
+		{"e0e0e0", nil, true},
+		{"#e0e0", nil, true},
+		{"#e0e0e0e0e0", nil, true},
+		{"#gg0000", nil, true},
+		{"", nil, true},
+	}
+
+	for _, entry := range table {
+		c, err := parseColor(entry.text)
+		if (err != nil) != entry.fails {
+			t.Fatalf("Incorrect error for %q, expected failure %v, got %v", entry.text, entry.fails, err)
+		}
+		if c != entry.color {
+			t.Fatalf("Incorrect color for %q, expected %v, got %v", entry.text, entry.color, c)
+		}
+	}
+}
+
+func TestReadTheme(t *testing.T) {
+	selection := color.NRGBA{0x40, 0x80, 0xff, 0x5a}
+
+	table := [](struct {
+		name  string
+		text  string
+		theme func() noter.Theme
+		fails bool
+	}){
+		{"theme.toml", "base = \"dark\" # comment\nselection = \"#4080ff5a\"\n", func() noter.Theme {
+			theme := noter.ThemeDark
+			theme.Selection = selection
+			return theme
+		}, false},
+		{"theme.toml", "'selection' = '#4080ff5a'\n", func() noter.Theme {
+			var theme noter.Theme
+			theme.Selection = selection
+			return theme
+		}, false},
+		{"theme.JSON", "{\"base\": \"light\", \"selection\": \"#4080ff5a\"}", func() noter.Theme {
+			theme := noter.ThemeLight
+			theme.Selection = selection
+			return theme
+		}, false},
+		{"theme.toml", "base = \"blue\"\n", nil, true},
+		{"theme.toml", "margin = \"#000000\"\n", nil, true},
+		{"theme.toml", "font = \"black\"\n", nil, true},
+		{"theme.toml", "[colors]\nfont = \"#000000\"\n", nil, true},
+		{"theme.toml", "font\n", nil, true},
+		{"theme.json", "{\"font\": ", nil, true},
+	}
+
+	for _, entry := range table {
+		file_path := filepath.Join(t.TempDir(), entry.name)
+		if err := os.WriteFile(file_path, []byte(entry.text), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		theme, err := readTheme(file_path)
+		if (err != nil) != entry.fails {
+			t.Fatalf("Incorrect error for %q, expected failure %v, got %v", entry.text, entry.fails, err)
+		}
+		if entry.fails {
+			continue
+		}
+		if expected := entry.theme(); theme != expected {
+			t.Fatalf("Incorrect theme for %q, expected %v, got %v", entry.text, expected, theme)
+		}
+	}
+}
+
+func TestReadThemeMissing(t *testing.T) {
+	if _, err := readTheme(filepath.Join(t.TempDir(), "theme.toml")); err == nil {
+		t.Fatalf("Expected an error for a missing theme file")
+	}
+}
@@ -0,0 +1,563 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// defaultBindings are the keystrokes of the default keymap, bound to the
+// names of commands.
+var defaultBindings = map[string]string{
+	"cmd+f":        "Search",
+	"cmd+z":        "Undo",
+	"cmd+q":        "Quit",
+	"cmd+s":        "Save",
+	"cmd+t":        "ToggleTable",
+	"cmd+r":        "Reflow",
+	"cmd+j":        "Align",
+	"cmd+d":        "ToggleCheckbox",
+	"cmd+u":        "DeleteToLineStart",
+	"cmd+a":        "SelectAll",
+	"cmd+v":        "Paste",
+	"cmd+alt+f":    "SearchWorkspace",
+	"cmd+shift+d":  "Duplicate",
+	"cmd+shift+f":  "FindAll",
+	"cmd+shift+i":  "Reindent",
+	"cmd+shift+l":  "LowerCase",
+	"cmd+shift+u":  "UpperCase",
+	"cmd+shift+v":  "PastePlain",
+	"cmd+x":        "Cut",
+	"cmd+=":        "ZoomIn",
+	"cmd+shift+=":  "ZoomIn",
+	"cmd+-":        "ZoomOut",
+	"cmd+0":        "ZoomReset",
+	"cmd+c":        "Copy",
+	"cmd+p":        "Switch",
+	"cmd+shift+\\": "JumpToBracket",
+	"ctrl+space":   "ShowCompletions",
+	"alt+pageup":   "IncrementNumber",
+	"alt+pagedown": "DecrementNumber",
+}
+
+// searchBindings are the keystrokes bound while searching, in place of
+// those of the keymap.
+var searchBindings = map[string]string{
+	"cmd+r": "ToggleRegexSearch",
+}
+
+// searchCommands are the commands whose bindings also run while searching.
+var searchCommands = map[string]bool{
+	"FindAll":           true,
+	"SearchWorkspace":   true,
+	"ToggleRegexSearch": true,
+}
+
+// keystrokeModifiers are the modifiers of a keystroke, in order.
+// "cmd" is the COMMAND modifier chosen by WithModifierPolicy, and the
+// others are the keys themselves.
+var keystrokeModifiers = []string{"cmd", "ctrl", "alt", "shift", "meta"}
+
+// keyNames are the names of the keys which aren't characters, such as
+// "enter" or "f8".
+var keyNames = func() map[string]bool {
+	names := make(map[string]bool)
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		names[strings.ToLower(key.String())] = true
+	}
+	return names
+}()
+
+// parseKeystroke returns the keystroke in its canonical form, such as
+// "ctrl+alt+f" for "Alt+Ctrl+F".
+func parseKeystroke(keystroke string) (string, error) {
+	parts := strings.Split(strings.ToLower(keystroke), "+")
+	key := parts[len(parts)-1]
+	if key == "" && len(parts) > 1 && parts[len(parts)-2] == "" {
+		// The '+' key itself, as in "cmd++".
+		key, parts = "+", parts[:len(parts)-1]
+	}
+	if utf8.RuneCountInString(key) != 1 && !keyNames[key] {
+		return "", fmt.Errorf("unknown key in keystroke %q", keystroke)
+	}
+
+	held := make(map[string]bool)
+	for _, modifier := range parts[:len(parts)-1] {
+		switch modifier {
+		case "cmd", "ctrl", "alt", "shift", "meta":
+			held[modifier] = true
+		case "control":
+			held["ctrl"] = true
+		case "option":
+			held["alt"] = true
+		default:
+			return "", fmt.Errorf("unknown modifier in keystroke %q", keystroke)
+		}
+	}
+
+	canonical := ""
+	for _, modifier := range keystrokeModifiers {
+		if held[modifier] {
+			canonical += modifier + "+"
+		}
+	}
+	return canonical + key, nil
+}
+
+// pressedKeystroke returns the keystroke of the key with the modifiers
+// held, such as "ctrl+a".
+func pressedKeystroke(key ebiten.Key, letter string) string {
+	keystroke := ""
+	for _, modifier := range []struct {
+		name string
+		key  ebiten.Key
+	}{
+		{"ctrl", ebiten.KeyControl},
+		{"alt", ebiten.KeyAlt},
+		{"shift", ebiten.KeyShift},
+		{"meta", ebiten.KeyMeta},
+	} {
+		if ebiten.IsKeyPressed(modifier.key) {
+			keystroke += modifier.name + "+"
+		}
+	}
+	if utf8.RuneCountInString(letter) == 1 {
+		return keystroke + strings.ToLower(letter)
+	}
+	return keystroke + strings.ToLower(key.String())
+}
+
+// RegisterCommand adds a named command, which can be bound to keystrokes
+// with Bind, replacing any command of the same name.
+func (e *Editor) RegisterCommand(name string, run func(e *Editor)) {
+	e.commands[name] = run
+}
+
+// RunCommand runs the named command, returning false if there is none.
+// Commands which edit the content are ignored when read-only.
+func (e *Editor) RunCommand(name string) bool {
+	run, ok := e.commands[name]
+	if !ok {
+		return false
+	}
+	if e.mode == EDIT_MODE && editingCommands[name] && name != "Undo" && !e.canEdit() {
+		return true
+	}
+	run(e)
+	return true
+}
+
+// Commands returns the names of the commands, in order.
+func (e *Editor) Commands() []string {
+	names := make([]string, 0, len(e.commands))
+	for name := range e.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Bind binds a keystroke, such as "cmd+s" or "ctrl+alt+f", to the named
+// command. "cmd" is the COMMAND modifier of WithModifierPolicy, while
+// "ctrl", "alt", "shift" and "meta" are the keys themselves. Keys are
+// characters or key names, such as "enter", "tab" or "f8". An empty
+// command removes the binding.
+func (e *Editor) Bind(keystroke, command string) error {
+	keystroke, err := parseKeystroke(keystroke)
+	if err != nil {
+		return err
+	}
+	if command == "" {
+		delete(e.bindings, keystroke)
+	} else {
+		e.bindings[keystroke] = command
+	}
+	return nil
+}
+
+// Bindings returns the keystrokes bound to the names of commands.
+func (e *Editor) Bindings() map[string]string {
+	bindings := make(map[string]string, len(e.bindings))
+	for keystroke, command := range e.bindings {
+		bindings[keystroke] = command
+	}
+	return bindings
+}
+
+// commandKeystroke returns the keystroke with the modifier which is
+// COMMAND under the policy written as "cmd", such as "cmd+shift+v" for
+// "ctrl+shift+v", if COMMAND is held with other modifiers. COMMAND alone
+// is left to the command keys, returning "".
+func commandKeystroke(keystroke string, policy ModifierPolicy) string {
+	var names []string
+	switch policy {
+	case MODIFIER_META:
+		names = []string{"meta"}
+	case MODIFIER_CONTROL:
+		names = []string{"ctrl"}
+	case MODIFIER_ALT:
+		names = []string{"alt"}
+	case MODIFIER_NONE:
+		return ""
+	default:
+		names = []string{"ctrl", "meta"}
+	}
+
+	for _, name := range names {
+		rest := strings.Replace(keystroke, name+"+", "", 1)
+		if rest == keystroke {
+			continue
+		}
+		for _, modifier := range keystrokeModifiers[1:] {
+			if strings.HasPrefix(rest, modifier+"+") {
+				return "cmd+" + rest
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// runBinding runs the command bound to the pressed keystroke, returning
+// true if there is one. While searching, only the search commands run.
+func (e *Editor) runBinding(key ebiten.Key, letter string) bool {
+	return e.runKeystroke(pressedKeystroke(key, letter))
+}
+
+// runKeystroke runs the command bound to the keystroke, returning true if
+// there is one.
+func (e *Editor) runKeystroke(keystroke string) bool {
+	command, ok := e.binding(keystroke)
+	if !ok {
+		command, ok = e.binding(commandKeystroke(keystroke, e.modifier_policy))
+	}
+	if e.mode == SEARCH_MODE && !searchCommands[command] {
+		return false
+	}
+	return ok && e.RunCommand(command)
+}
+
+// moveCommand returns a command which moves the cursor as does an arrow
+// key, with the move of the Update key handling.
+func moveCommand(move func(e *Editor, shift bool)) func(e *Editor) {
+	return func(e *Editor) {
+		e.editMode()
+		e.resetHighlight()
+		move(e, false)
+		e.fixPosition()
+	}
+}
+
+// binding returns the command bound to the keystroke, with the search
+// bindings taking precedence while searching.
+func (e *Editor) binding(keystroke string) (string, bool) {
+	if e.mode == SEARCH_MODE {
+		if command, ok := searchBindings[keystroke]; ok {
+			return command, true
+		}
+	}
+	command, ok := e.bindings[keystroke]
+	return command, ok
+}
+
+// registerBuiltinCommands adds the commands of the default keymap.
+func registerBuiltinCommands(e *Editor) {
+	e.commands = make(map[string]func(e *Editor))
+	e.bindings = make(map[string]string)
+	for keystroke, command := range defaultBindings {
+		e.bindings[keystroke] = command
+	}
+
+	e.RegisterCommand("Search", func(e *Editor) {
+		// Enter search mode
+		if e.mode == SEARCH_MODE {
+			e.editMode()
+		} else {
+			e.searchMode()
+		}
+	})
+	e.RegisterCommand("Undo", func(e *Editor) {
+		// Undo (may repeat)
+		if e.read_only || e.lineEdit != nil && !e.canEdit() {
+			return
+		}
+		e.editMode()
+		e.resetHighlight()
+		e.undoing = true
+		defer func() { e.undoing = false }()
+
+		for len(e.undoStack) > 0 {
+			notNoop := e.undoStack[len(e.undoStack)-1]()
+			e.undoStack = e.undoStack[:len(e.undoStack)-1]
+			if notNoop {
+				break
+			}
+		}
+		e.notifyEdit()
+	})
+	e.RegisterCommand("Quit", func(e *Editor) {
+		e.quit()
+	})
+	e.RegisterCommand("Save", func(e *Editor) {
+		e.Save()
+	})
+	e.RegisterCommand("ToggleTable", func(e *Editor) {
+		// Toggle table alignment
+		e.table_mode = !e.table_mode
+	})
+	e.RegisterCommand("Reflow", func(e *Editor) {
+		// Reflow paragraph
+		e.editMode()
+		e.ReflowParagraph()
+	})
+	e.RegisterCommand("ToggleRegexSearch", func(e *Editor) {
+		// Toggle regular expression search
+		e.SetRegexSearch(!e.regex_search)
+	})
+	e.RegisterCommand("Align", func(e *Editor) {
+		// Align the selected lines
+		e.editMode()
+		e.promptMode(e.tr("align on: "), func(input string) {
+			if err := e.AlignSelection(input); err != nil {
+				e.Notify(e.tr("can't align on %q: %v", input, err))
+			}
+		})
+	})
+	e.RegisterCommand("ToggleCheckbox", func(e *Editor) {
+		// Toggle the task list checkbox
+		e.editMode()
+		e.ToggleCheckbox()
+	})
+	e.RegisterCommand("DeleteToLineStart", func(e *Editor) {
+		e.editMode()
+		e.storeUndoAction(e.fnDeleteToLineStart())
+		e.setModified()
+	})
+	e.RegisterCommand("SelectAll", func(e *Editor) {
+		// Highlight all
+		e.editMode()
+		e.fnSelectAll()
+	})
+	e.RegisterCommand("Paste", func(e *Editor) {
+		// Paste into the search or prompt input
+		if e.mode != EDIT_MODE {
+			e.pasteInput(false)
+			return
+		}
+
+		// Paste (may repeat)
+		e.paste(e.clipboardRunes())
+	})
+	e.RegisterCommand("Tutorial", func(e *Editor) {
+		// Play the tutorial
+		e.PlayTutorial(nil)
+	})
+	e.RegisterCommand("Switch", func(e *Editor) {
+		// Switch to other content
+		e.editMode()
+		e.showSwitcher()
+	})
+	e.RegisterCommand("PastePlain", func(e *Editor) {
+		// Paste as plain text
+		if e.mode != EDIT_MODE {
+			e.pasteInput(true)
+			return
+		}
+		e.paste(plainText(e.clipboardRunes()))
+	})
+	e.RegisterCommand("Cut", func(e *Editor) {
+		// Cut the search or prompt input
+		if e.mode != EDIT_MODE {
+			e.cutInput()
+			return
+		}
+
+		// Cut highlight
+		copyRunes := e.getHighlightedRunes()
+		if len(copyRunes) == 0 {
+			return
+		}
+
+		e.clipboard.WriteText([]byte(string(copyRunes)))
+
+		e.storeUndoAction(e.fnDeleteHighlighted())
+		e.resetHighlight()
+
+		e.setModified()
+	})
+	e.RegisterCommand("Copy", func(e *Editor) {
+		// Copy the search or prompt input
+		if e.mode != EDIT_MODE {
+			e.copyInput()
+			return
+		}
+
+		// Copy highlight
+		if len(e.highlighted) == 0 {
+			return
+		}
+		copyRunes := e.getHighlightedRunes()
+		copyBytes := []byte(string(copyRunes))
+		e.clipboard.WriteText(copyBytes)
+	})
+
+	// Commands without a default keystroke.
+	e.RegisterCommand("LineStart", moveCommand((*Editor).moveLineStart))
+	e.RegisterCommand("LineEnd", moveCommand((*Editor).moveLineEnd))
+	e.RegisterCommand("MoveWordLeft", moveCommand((*Editor).moveWordLeft))
+	e.RegisterCommand("MoveWordRight", moveCommand((*Editor).moveWordRight))
+	e.RegisterCommand("DeleteLine", func(e *Editor) {
+		e.editMode()
+		e.deleteLine()
+	})
+	e.RegisterCommand("InsertLineBelow", func(e *Editor) {
+		e.editMode()
+		e.resetHighlight()
+		e.storeUndoAction(e.fnInsertLine(false))
+		e.fixPosition()
+		e.setModified()
+	})
+	e.RegisterCommand("InsertLineAbove", func(e *Editor) {
+		e.editMode()
+		e.resetHighlight()
+		e.storeUndoAction(e.fnInsertLine(true))
+		e.fixPosition()
+		e.setModified()
+	})
+	e.RegisterCommand("ToggleLineEnding", func(e *Editor) {
+		e.editMode()
+		if e.lineEnding == LINE_ENDING_CRLF {
+			e.SetLineEnding(LINE_ENDING_LF)
+		} else {
+			e.SetLineEnding(LINE_ENDING_CRLF)
+		}
+	})
+	e.RegisterCommand("TitleCase", func(e *Editor) {
+		e.editMode()
+		e.storeUndoAction(e.fnChangeCase(titleCase))
+	})
+	e.RegisterCommand("Duplicate", func(e *Editor) {
+		e.editMode()
+		e.storeUndoAction(e.fnDuplicate())
+		e.fixPosition()
+		e.setModified()
+	})
+	e.RegisterCommand("ShowCompletions", func(e *Editor) {
+		e.ShowCompletions()
+	})
+	e.RegisterCommand("Reindent", func(e *Editor) {
+		e.editMode()
+		e.Reindent()
+	})
+	e.RegisterCommand("UpperCase", func(e *Editor) {
+		e.editMode()
+		e.storeUndoAction(e.fnChangeCase(upperCase))
+	})
+	e.RegisterCommand("LowerCase", func(e *Editor) {
+		e.editMode()
+		e.storeUndoAction(e.fnChangeCase(lowerCase))
+	})
+	e.RegisterCommand("ZoomIn", func(e *Editor) {
+		e.zoom(1)
+	})
+	e.RegisterCommand("ZoomOut", func(e *Editor) {
+		e.zoom(-1)
+	})
+	e.RegisterCommand("ZoomReset", func(e *Editor) {
+		e.zoom(0)
+	})
+	e.RegisterCommand("IncrementNumber", func(e *Editor) {
+		e.editMode()
+		e.resetHighlight()
+		e.storeUndoAction(e.fnStepNumber(1))
+	})
+	e.RegisterCommand("DecrementNumber", func(e *Editor) {
+		e.editMode()
+		e.resetHighlight()
+		e.storeUndoAction(e.fnStepNumber(-1))
+	})
+	e.RegisterCommand("FindAll", func(e *Editor) {
+		// List the matches of the search term, or else prompt for a term
+		if e.mode == SEARCH_MODE && len(e.searchTerm) > 0 {
+			term := string(e.searchTerm)
+			e.editMode()
+			e.ShowFindAll(term)
+			return
+		}
+		e.editMode()
+		e.promptMode(e.tr("find all: "), e.ShowFindAll)
+	})
+	e.RegisterCommand("SearchWorkspace", func(e *Editor) {
+		// Search all of the contents for the search term, or else prompt
+		if e.mode == SEARCH_MODE && len(e.searchTerm) > 0 {
+			term := string(e.searchTerm)
+			e.editMode()
+			e.SearchWorkspace(term)
+			return
+		}
+		e.editMode()
+		e.promptMode(e.tr("search files: "), func(input string) {
+			e.SearchWorkspace(input)
+		})
+	})
+	e.RegisterCommand("JumpToBracket", func(e *Editor) {
+		e.editMode()
+		e.resetHighlight()
+		e.JumpToBracket()
+	})
+	e.RegisterCommand("PickColor", func(e *Editor) {
+		e.editMode()
+		e.pickColor(e.cursor.line, e.cursor.x)
+	})
+	e.RegisterCommand("ToggleSelecting", func(e *Editor) {
+		e.editMode()
+		e.SetSelecting(!e.selecting)
+	})
+}
+
+// deleteLine deletes the cursor line, leaving the cursor at the start of
+// the following line.
+func (e *Editor) deleteLine() {
+	line := e.cursor.line
+	e.resetHighlight()
+	switch {
+	case line.next != nil:
+		e.highlightBetween(line, 0, line.next, 0)
+	case line.prev != nil:
+		e.highlightBetween(line.prev, len(line.prev.values)-1, line, len(line.values)-1)
+	default:
+		e.highlightBetween(line, 0, line, len(line.values)-1)
+	}
+	if len(e.highlighted) == 0 {
+		return
+	}
+	e.storeUndoAction(e.fnDeleteHighlighted())
+	e.resetHighlight()
+	e.cursor.x = 0
+	e.setModified()
+}
@@ -0,0 +1,116 @@
+package noter
+
+import "testing"
+
+func TestParseKeystroke(t *testing.T) {
+	table := [](struct {
+		keystroke, canonical string
+		ok                   bool
+	}){
+		{"cmd+s", "cmd+s", true},
+		{"Alt+Ctrl+F", "ctrl+alt+f", true},
+		{"option+control+enter", "ctrl+alt+enter", true},
+		{"shift+F8", "shift+f8", true},
+		{"cmd++", "cmd++", true},
+		{"hyper+a", "", false},
+		{"cmd+nothing", "", false},
+	}
+
+	for _, entry := range table {
+		canonical, err := parseKeystroke(entry.keystroke)
+		if (err == nil) != entry.ok || canonical != entry.canonical {
+			t.Fatalf("Incorrect keystroke for %q, expected %q, got %q (%v)", entry.keystroke, entry.canonical, canonical, err)
+		}
+	}
+}
+
+func TestCommandKeystroke(t *testing.T) {
+	table := [](struct {
+		keystroke string
+		policy    ModifierPolicy
+		want      string
+	}){
+		{"ctrl+shift+v", MODIFIER_META_OR_CONTROL, "cmd+shift+v"},
+		{"shift+meta+v", MODIFIER_META_OR_CONTROL, "cmd+shift+v"},
+		{"ctrl+shift+v", MODIFIER_META, ""},
+		{"ctrl+v", MODIFIER_CONTROL, ""},
+		{"alt+shift+v", MODIFIER_ALT, "cmd+shift+v"},
+		{"shift+v", MODIFIER_META_OR_CONTROL, ""},
+	}
+
+	for _, entry := range table {
+		if got := commandKeystroke(entry.keystroke, entry.policy); got != entry.want {
+			t.Fatalf("Incorrect keystroke for %q, expected %q, got %q", entry.keystroke, entry.want, got)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one\ntwo\n"))
+	editor.MoveCursor(1, 1)
+
+	if err := editor.Bind("cmd+k", "DeleteLine"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	editor.runCommand("k")
+	if got := string(editor.ReadText()); got != "one\n" {
+		t.Fatalf("Incorrect text after the bound command, got %q", got)
+	}
+
+	called := false
+	editor.RegisterCommand("Mine", func(e *Editor) { called = true })
+	editor.Bind("cmd+z", "Mine")
+	editor.runCommand("z")
+	if !called || string(editor.ReadText()) != "one\n" {
+		t.Fatalf("Expected the rebound key to run the registered command")
+	}
+
+	editor.Bind("cmd+z", "")
+	if _, ok := editor.Bindings()["cmd+z"]; ok {
+		t.Fatalf("Expected the binding to be removed")
+	}
+	if editor.RunCommand("Missing") {
+		t.Fatalf("Expected no command to run")
+	}
+}
+
+func TestToggleRegexSearchBinding(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one\n"))
+
+	editor.searchMode()
+	editor.runCommand("r")
+	if !editor.RegexSearch() {
+		t.Fatalf("Expected COMMAND-R to toggle regex search while searching")
+	}
+
+	editor.editMode()
+	editor.runCommand("r")
+	if !editor.RegexSearch() {
+		t.Fatalf("Expected COMMAND-R to reflow, not toggle regex search, while editing")
+	}
+}
+
+func TestMoveCommands(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one two, three\n"))
+	editor.MoveCursor(0, 5)
+
+	table := [](struct {
+		command string
+		col     int
+	}){
+		{"MoveWordRight", 7},
+		{"MoveWordLeft", 4},
+		{"LineEnd", 14},
+		{"LineStart", 0},
+	}
+
+	for _, entry := range table {
+		editor.RunCommand(entry.command)
+		if _, col := editor.Cursor(); col != entry.col {
+			t.Fatalf("Incorrect move by %v, expected %v, got %v", entry.command, entry.col, col)
+		}
+	}
+}
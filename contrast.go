@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// palette is the set of colors used to draw the editor.
+type palette struct {
+	font, selection, search, cursor color.Color
+	background                      *ebiten.Image
+	backgroundColor                 color.Color
+}
+
+// highContrastColors are opaque, and drawn behind black text on white. Each
+// gives a contrast ratio of at least 4.5:1 with the text (WCAG AA), and of
+// at least 3:1 with the background, so selections stand out.
+var highContrastColors = struct {
+	font, background, selection, search, cursor color.Color
+}{
+	font:       color.Black,
+	background: color.White,
+	selection:  color.RGBA{0x00, 0x96, 0xff, 0xff},
+	search:     color.RGBA{0xe6, 0x50, 0x00, 0xff},
+	cursor:     color.RGBA{0xff, 0x00, 0xff, 0xff},
+}
+
+// WithHighContrast draws the editor in high-contrast colors, in place of
+// the configured colors, and disables focus dimming.
+func WithHighContrast(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.high_contrast = enabled
+	}
+}
+
+// HighContrast returns true if high-contrast colors are used.
+func (e *Editor) HighContrast() bool {
+	return e.high_contrast
+}
+
+// SetHighContrast switches between high-contrast and the configured colors.
+func (e *Editor) SetHighContrast(enabled bool) {
+	if enabled == e.high_contrast {
+		return
+	}
+	e.high_contrast = enabled
+	e.applyContrast()
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// applyContrast saves the configured colors and switches to high-contrast
+// colors, or restores the configured colors.
+func (e *Editor) applyContrast() {
+	if !e.high_contrast {
+		e.font_color = e.saved.font
+		e.select_color = e.saved.selection
+		e.search_color = e.saved.search
+		e.cursor_color = e.saved.cursor
+		e.background_image = e.saved.background
+		e.background_color = e.saved.backgroundColor
+		return
+	}
+
+	e.saved = palette{e.font_color, e.select_color, e.search_color, e.cursor_color, e.background_image, e.background_color}
+	WithFontColor(highContrastColors.font)(e)
+	WithBackgroundColor(highContrastColors.background)(e)
+	WithHighlightColor(highContrastColors.selection)(e)
+	WithSearchColor(highContrastColors.search)(e)
+	WithCursorColor(highContrastColors.cursor)(e)
+}
+
+// WithReducedMotion limits movement on screen, for users sensitive to it.
+// Scrolling while dragging a selection past the edge of the text proceeds
+// at a steady pace, rather than speeding up with the distance dragged.
+func WithReducedMotion(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.reduced_motion = enabled
+	}
+}
+
+// ReducedMotion returns true if motion is reduced.
+func (e *Editor) ReducedMotion() bool {
+	return e.reduced_motion
+}
+
+// SetReducedMotion enables or disables reduced motion.
+func (e *Editor) SetReducedMotion(enabled bool) {
+	e.reduced_motion = enabled
+}
@@ -0,0 +1,56 @@
+package noter
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// contrastRatio returns the WCAG contrast ratio of two opaque colors.
+func contrastRatio(a, b color.Color) float64 {
+	luminance := func(c color.Color) float64 {
+		r, g, b, _ := c.RGBA()
+		linear := func(v uint32) float64 {
+			s := float64(v) / 0xffff
+			if s <= 0.03928 {
+				return s / 12.92
+			}
+			return math.Pow((s+0.055)/1.055, 2.4)
+		}
+		return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+	}
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+func TestHighContrastColors(t *testing.T) {
+	c := highContrastColors
+	for _, under := range []color.Color{c.background, c.selection, c.search, c.cursor} {
+		if ratio := contrastRatio(c.font, under); ratio < 4.5 {
+			t.Fatalf("Insufficient text contrast over %v: %.2f", under, ratio)
+		}
+	}
+	for _, mark := range []color.Color{c.selection, c.search, c.cursor} {
+		if ratio := contrastRatio(c.background, mark); ratio < 3 {
+			t.Fatalf("Insufficient contrast of %v with the background: %.2f", mark, ratio)
+		}
+	}
+}
+
+func TestSetHighContrast(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	editor := NewEditor(WithFontColor(red))
+
+	editor.SetHighContrast(true)
+	if editor.font_color != highContrastColors.font || editor.cursorColor() != highContrastColors.cursor {
+		t.Fatalf("Expected high-contrast colors, got %v", editor.font_color)
+	}
+
+	editor.SetHighContrast(false)
+	if editor.font_color != red {
+		t.Fatalf("Expected the configured colors to be restored, got %v", editor.font_color)
+	}
+}
@@ -0,0 +1,59 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"log"
+)
+
+// Diagnostics are counters describing the work done by an editor.
+type Diagnostics struct {
+	FramesRendered uint64 // times the backing image was rendered.
+	RedrawsSkipped uint64 // draws which reused an unchanged backing image.
+	RendersSkipped uint64 // updates which didn't render, as nothing changed.
+	UndoDepth      int    // actions on the undo stack.
+	Lines          int    // lines in the buffer.
+	Runes          int    // runes in the buffer, including new lines.
+}
+
+// WithLogger sets the logger used by the editor.
+// If set to nil, the standard logger is used.
+func WithLogger(logger *log.Logger) EditorOption {
+	return func(e *Editor) {
+		if logger == nil {
+			logger = log.Default()
+		}
+		e.logger = logger
+	}
+}
+
+// Diagnostics returns the editor's counters, such as for a host dashboard.
+func (e *Editor) Diagnostics() Diagnostics {
+	d := e.diagnostics
+	d.UndoDepth = len(e.undoStack)
+	for line := e.start; line != nil; line = line.next {
+		d.Lines++
+		d.Runes += len(line.values)
+	}
+	return d
+}
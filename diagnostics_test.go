@@ -0,0 +1,35 @@
+package noter
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("ab\ncd\n"))
+	editor.MoveCursor(0, 2)
+	editor.storeUndoAction(editor.fnTypeRune('x'))
+
+	d := editor.Diagnostics()
+	if d.UndoDepth != 1 || d.Lines != 3 || d.Runes != 8 {
+		t.Fatalf("Incorrect diagnostics, got: %+v", d)
+	}
+
+	rendered := d.FramesRendered
+	editor.updateImage()
+	if d := editor.Diagnostics(); d.FramesRendered != rendered+1 {
+		t.Fatalf("Expected one more frame rendered, got: %v", d.FramesRendered-rendered)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	editor := NewEditor(WithLogger(log.New(&buf, "noter: ", 0)))
+	editor.logger.Printf("hello")
+	if !strings.HasPrefix(buf.String(), "noter: hello") {
+		t.Fatalf("Expected output through the logger, got: %q", buf.String())
+	}
+}
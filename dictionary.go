@@ -0,0 +1,180 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"bufio"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Dictionary is a word list, such as for spell checking, completion or
+// abbreviations, read from files in a file system. The files are read on
+// first use, so an embed.FS of large lists costs nothing until needed.
+//
+// Each line of a file holds a word. Blank lines, and lines starting with
+// '#', are ignored. Words are matched ignoring case.
+type Dictionary struct {
+	fsys  fs.FS
+	names []string
+
+	once   sync.Once
+	words  map[string]bool
+	sorted []string // lower case words, in order, for completion
+	err    error
+}
+
+// NewDictionary returns a dictionary of the words in the named files of
+// the file system.
+func NewDictionary(fsys fs.FS, names ...string) *Dictionary {
+	return &Dictionary{fsys: fsys, names: names}
+}
+
+// load reads the files, once.
+func (d *Dictionary) load() {
+	d.once.Do(func() {
+		d.words = make(map[string]bool)
+		for _, name := range d.names {
+			if err := d.read(name); err != nil {
+				d.err = err
+				return
+			}
+		}
+		for word := range d.words {
+			d.sorted = append(d.sorted, word)
+		}
+		sort.Strings(d.sorted)
+	})
+}
+
+// read adds the words of the named file.
+func (d *Dictionary) read(name string) error {
+	f, err := d.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		d.words[strings.ToLower(word)] = true
+	}
+	return scanner.Err()
+}
+
+// Err returns the error, if any, from reading the files.
+func (d *Dictionary) Err() error {
+	d.load()
+	return d.err
+}
+
+// Len returns the number of words.
+func (d *Dictionary) Len() int {
+	d.load()
+	return len(d.sorted)
+}
+
+// Contains returns true if the word is in the dictionary.
+func (d *Dictionary) Contains(word string) bool {
+	d.load()
+	return d.words[strings.ToLower(word)]
+}
+
+// Complete returns up to max words starting with the prefix, in order.
+func (d *Dictionary) Complete(prefix string, max int) []string {
+	d.load()
+	prefix = strings.ToLower(prefix)
+	i := sort.SearchStrings(d.sorted, prefix)
+
+	var words []string
+	for ; i < len(d.sorted) && len(words) < max && strings.HasPrefix(d.sorted[i], prefix); i++ {
+		if d.sorted[i] != prefix {
+			words = append(words, d.sorted[i])
+		}
+	}
+	return words
+}
+
+// WithDictionary adds a dictionary, used to complete words.
+func WithDictionary(opt *Dictionary) EditorOption {
+	return func(e *Editor) {
+		e.dictionaries = append(e.dictionaries, opt)
+	}
+}
+
+// Dictionaries returns the dictionaries added to the editor.
+func (e *Editor) Dictionaries() []*Dictionary {
+	return e.dictionaries
+}
+
+// wordBeforeCursor returns the part of the word before the cursor.
+func (e *Editor) wordBeforeCursor() []rune {
+	start := e.cursor.x
+	for start > 0 && isWordRune(e.cursor.line.values[start-1]) {
+		start--
+	}
+	return e.cursor.line.values[start:e.cursor.x]
+}
+
+// ShowCompletions shows a popup of the dictionary words which complete
+// the word before the cursor. It returns false if there are none.
+func (e *Editor) ShowCompletions() bool {
+	prefix := e.wordBeforeCursor()
+	if len(prefix) == 0 || !e.canEdit() {
+		return false
+	}
+
+	seen := make(map[string]bool)
+	var items []string
+	for _, d := range e.dictionaries {
+		if err := d.Err(); err != nil {
+			e.on_error(err)
+			continue
+		}
+		for _, word := range d.Complete(string(prefix), POPUP_DEFAULT_ROWS*4) {
+			if !seen[word] {
+				seen[word] = true
+				items = append(items, word)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return false
+	}
+
+	e.ShowPopup(&Popup{
+		Items: items,
+		OnChoose: func(e *Editor, index int) {
+			rest := []rune(items[index])[len(prefix):]
+			e.storeUndoAction(e.fnHandleRuneMulti(rest))
+			e.setModified()
+		},
+	})
+	return true
+}
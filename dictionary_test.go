@@ -0,0 +1,59 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDictionary(t *testing.T) {
+	fsys := fstest.MapFS{
+		"words.txt": {Data: []byte("# animals\ncat\nCatalog\n\ncatalogue\ndog\n")},
+		"more.txt":  {Data: []byte("caterpillar\n")},
+	}
+	d := NewDictionary(fsys, "words.txt", "more.txt")
+
+	if d.Len() != 5 || !d.Contains("CAT") || d.Contains("animals") {
+		t.Fatalf("Incorrect words, got %v", d.sorted)
+	}
+	if got, want := d.Complete("Cat", 2), []string{"catalog", "catalogue"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Incorrect completions, expected %v, got %v", want, got)
+	}
+
+	missing := NewDictionary(fsys, "missing.txt")
+	if missing.Err() == nil {
+		t.Fatalf("Expected an error for a missing file")
+	}
+}
+
+func TestShowCompletions(t *testing.T) {
+	fsys := fstest.MapFS{"words.txt": {Data: []byte("noter\nnotebook\n")}}
+	editor := NewEditor(WithDictionary(NewDictionary(fsys, "words.txt")))
+	editor.WriteText([]byte("a note\n"))
+	editor.MoveCursor(0, 6)
+
+	if !editor.ShowCompletions() {
+		t.Fatalf("Expected completions to be shown")
+	}
+	popup := editor.TopOverlay().(*Popup)
+	popup.Selected = 1
+	popup.choose(editor)
+
+	if got := string(editor.ReadText()); got != "a noter\n" {
+		t.Fatalf("Incorrect completion, got: %q", got)
+	}
+}
+
+func TestShowCompletionsBinding(t *testing.T) {
+	fsys := fstest.MapFS{"words.txt": {Data: []byte("noter\n")}}
+	editor := NewEditor(WithDictionary(NewDictionary(fsys, "words.txt")))
+	editor.WriteText([]byte("a note\n"))
+	editor.MoveCursor(0, 6)
+
+	if !editor.runKeystroke("ctrl+space") {
+		t.Fatalf("Expected ctrl+space to be bound")
+	}
+	if _, ok := editor.TopOverlay().(*Popup); !ok {
+		t.Fatalf("Expected completions to be shown")
+	}
+}
@@ -0,0 +1,77 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Invalidate renders the editor again on the next Update, such as after
+// an extension's drawing has changed. Changes made through the editor's
+// methods, and input, are rendered without it.
+func (e *Editor) Invalidate() {
+	e.dirty = true
+}
+
+// hasInput returns true if there is any keyboard or mouse input.
+func hasInput() bool {
+	if len(inpututil.AppendPressedKeys(nil)) > 0 || len(inpututil.AppendJustReleasedKeys(nil)) > 0 {
+		return true
+	}
+	if len(ebiten.AppendInputChars(nil)) > 0 {
+		return true
+	}
+	for _, button := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle} {
+		if ebiten.IsMouseButtonPressed(button) || inpututil.IsMouseButtonJustReleased(button) {
+			return true
+		}
+	}
+	x, y := ebiten.Wheel()
+	return x != 0 || y != 0
+}
+
+// checkDirty marks the editor to be rendered after this Update if there is
+// input, or anything which changes over time is showing, such as a notice
+// or the progress of queued work. An idle editor isn't rendered again.
+func (e *Editor) checkDirty() {
+	switch {
+	case hasInput(), len(e.workQueue) > 0, e.pendingChord != "", e.dragging, e.tutorial != nil:
+		e.dirty = true
+	case e.notice != "":
+		// Render once more after the notice expires, without it.
+		if _, ok := e.currentNotice(); !ok {
+			e.notice = ""
+		}
+		e.dirty = true
+	}
+}
+
+// updateImageIfDirty renders the internal image, if it may have changed.
+func (e *Editor) updateImageIfDirty() {
+	if !e.dirty {
+		e.diagnostics.RendersSkipped++
+		return
+	}
+	e.updateImage()
+}
@@ -0,0 +1,57 @@
+package noter
+
+import "testing"
+
+func TestUpdateImageIfDirty(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("ab\n"))
+
+	rendered := editor.Diagnostics().FramesRendered
+	editor.updateImageIfDirty()
+	if d := editor.Diagnostics(); d.FramesRendered != rendered || d.RendersSkipped != 1 {
+		t.Fatalf("Expected an unchanged editor not to render, got: %+v", d)
+	}
+
+	editor.Invalidate()
+	editor.updateImageIfDirty()
+	editor.updateImageIfDirty()
+	if d := editor.Diagnostics(); d.FramesRendered != rendered+1 {
+		t.Fatalf("Expected one render after Invalidate, got: %v", d.FramesRendered-rendered)
+	}
+
+	editor.PostEdit(func(e *Editor) {
+		e.AppendText([]byte("c"))
+	})
+	editor.runPosted()
+	editor.updateImageIfDirty()
+	if d := editor.Diagnostics(); d.FramesRendered != rendered+2 || editor.dirty {
+		t.Fatalf("Expected a render after a posted edit, got: %v", d.FramesRendered-rendered)
+	}
+}
+
+func TestMutatorsInvalidate(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("ab\n"))
+	popup := &Popup{Items: []string{"a"}}
+
+	table := [](struct {
+		name   string
+		mutate func()
+	}){
+		{"PushOverlay", func() { editor.PushOverlay(popup) }},
+		{"RemoveOverlay", func() { editor.RemoveOverlay(popup) }},
+		{"DismissOverlay", func() { editor.PushOverlay(popup); editor.dirty = false; editor.DismissOverlay() }},
+		{"SetLineData", func() { editor.SetLineData(0, "k", 1) }},
+		{"DeleteLineData", func() { editor.DeleteLineData(0, "k") }},
+		{"SetLinesReadOnly", func() { editor.SetLinesReadOnly(0, 0, true) }},
+		{"RegisterToken", func() { editor.RegisterToken("x", 1, nil) }},
+	}
+
+	for _, entry := range table {
+		editor.dirty = false
+		entry.mutate()
+		if !editor.dirty {
+			t.Fatalf("Expected %v to render the editor again", entry.name)
+		}
+	}
+}
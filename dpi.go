@@ -0,0 +1,149 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/bitmapfont/v3"
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+// WithFont sets the font of the text, at the size in points for the dpi.
+// Unlike a face set by WithFontFace, the editor makes the face of the font
+// itself, at the scale of the display (see ebiten.DeviceScaleFactor), so
+// that text is sharp on HiDPI displays. It takes precedence over
+// WithFontFace. Without it, the face (such as the default bitmap font) is
+// drawn at its own size, which the host scales up on a HiDPI display.
+func WithFont(f *opentype.Font, size, dpi float64) EditorOption {
+	return func(e *Editor) {
+		e.font_source = f
+		e.font_size = size
+		e.font_base_size = size
+		e.font_dpi = dpi
+	}
+}
+
+// SetFontFace replaces the face of the text, as WithFontFace, keeping the
+// content, cursor and size of the editor, with more or fewer rows and
+// columns fitting. A nil face is the default. It replaces any font of
+// WithFont, so the editor is no longer laid out at the scale of the
+// display, and the zoom keys are unbound.
+func (e *Editor) SetFontFace(face font.Face) {
+	if face == nil {
+		face = bitmapfont.Face
+	}
+	width, height := e.Size()
+	e.font_source = nil
+	e.unbindZoom()
+	e.scale = 1
+	e.width, e.height = width, height
+	e.setFace(face, false)
+	e.relayout()
+}
+
+// Scale returns the scale of the display the editor is laid out for,
+// such as 2 on a retina display. It is 1 unless WithFont is set.
+func (e *Editor) Scale() float64 {
+	return e.scale
+}
+
+// newFace returns a face of the font of WithFont, at the size and scale.
+func (e *Editor) newFace(size, scale float64) (font.Face, error) {
+	return opentype.NewFace(e.font_source, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     e.font_dpi * scale,
+		Hinting: font.HintingFull,
+	})
+}
+
+// setFace replaces the face of the text, closing any face which the
+// editor made itself.
+func (e *Editor) setFace(face font.Face, owned bool) {
+	if e.font_info != nil && e.ownFace {
+		e.font_info.face.Close()
+	}
+	e.font_info = newfontInfo(face)
+	e.ownFace = owned
+}
+
+// updateScale lays the editor out again if the scale of the display has
+// changed, such as when its window moves to another display.
+func (e *Editor) updateScale() {
+	if e.font_source == nil {
+		return
+	}
+	if scale := ebiten.DeviceScaleFactor(); scale > 0 && scale != e.scale {
+		e.setScale(scale)
+	}
+}
+
+// setScale lays the editor out at the scale, keeping its size on screen.
+// The internal image is made at the scale, with a face of the font to
+// match, so that each pixel of the image is a pixel of the display.
+func (e *Editor) setScale(scale float64) {
+	face, err := e.newFace(e.font_size, scale)
+	if err != nil {
+		e.on_error(err)
+		return
+	}
+
+	width, height := e.Size()
+	e.scale = scale
+	e.width = int(math.Round(float64(width) * scale))
+	e.height = int(math.Round(float64(height) * scale))
+	e.setFace(face, true)
+	e.relayout()
+}
+
+// relayout fits the padding, rows and columns to the size of the editor
+// and the metrics of its font, as when the editor was created, and makes
+// the internal image again if its size has changed.
+func (e *Editor) relayout() {
+	e.width_padding = e.font_info.xUnit / 2
+	if e.padding >= 0 {
+		e.width_padding = int(math.Round(float64(e.padding) * e.scale))
+	}
+	if e.top_bar {
+		e.top_padding = int(float64(e.font_info.yUnit) * 1.25)
+	}
+	if e.bot_bar {
+		e.bot_padding = int(float64(e.font_info.yUnit) * 1.25)
+	}
+
+	e.rows = (e.height - (e.top_padding + e.bot_padding)) / e.font_info.yUnit
+	e.cols = (e.width - e.width_padding*2) / e.font_info.xUnit
+	if e.screen == nil || e.screen.Bounds().Dx() != e.width || e.screen.Bounds().Dy() != e.height {
+		// The old image is freed now, rather than left for the finalizer.
+		if e.screen != nil {
+			e.screen.Dispose()
+		}
+		e.screen = ebiten.NewImage(e.width, e.height)
+	}
+	e.fixPosition()
+
+	// Update the backing image.
+	e.updateImage()
+}
@@ -0,0 +1,67 @@
+package noter
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+)
+
+func TestSetScale(t *testing.T) {
+	f, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		t.Fatalf("Couldn't parse the font: %v", err)
+	}
+	editor := NewEditor(WithFont(f, 12, 72), WithRows(10), WithColumns(40))
+	width, height := editor.Size()
+	xUnit := editor.font_info.xUnit
+
+	editor.setScale(2)
+	if w, h := editor.Size(); w != width || h != height {
+		t.Fatalf("Expected the size to be kept at %vx%v, got %vx%v", width, height, w, h)
+	}
+	if b := editor.screen.Bounds(); editor.width != width*2 || editor.height != height*2 || b.Dx() != width*2 || b.Dy() != height*2 {
+		t.Fatalf("Expected the image to be %vx%v, got %vx%v", width*2, height*2, editor.width, editor.height)
+	}
+	if got := editor.font_info.xUnit; got < xUnit*2-1 || got > xUnit*2+1 {
+		t.Fatalf("Expected the face to be scaled from %v, got %v", xUnit, got)
+	}
+	if editor.rows < 9 || editor.cols < 39 {
+		t.Fatalf("Expected about 10 rows and 40 columns, got %v and %v", editor.rows, editor.cols)
+	}
+}
+
+func TestSetFontFace(t *testing.T) {
+	f, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		t.Fatalf("Couldn't parse the font: %v", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: 24, DPI: 72})
+	if err != nil {
+		t.Fatalf("Couldn't make the face: %v", err)
+	}
+
+	editor := NewEditor(WithRows(10), WithColumns(40))
+	editor.WriteText([]byte("abc\ndef\n"))
+	editor.MoveCursor(1, 2)
+	width, height := editor.Size()
+	xUnit := editor.font_info.xUnit
+	screen := editor.screen
+
+	editor.SetFontFace(face)
+	if editor.screen != screen {
+		t.Fatalf("Expected the image to be kept at the same size")
+	}
+	if got := string(editor.ReadText()); got != "abc\ndef\n" {
+		t.Fatalf("Expected the text to be kept, got %q", got)
+	}
+	if row, col := editor.Cursor(); row != 1 || col != 2 {
+		t.Fatalf("Expected the cursor to be kept at 1:2, got %v:%v", row, col)
+	}
+	if w, h := editor.Size(); w != width || h != height {
+		t.Fatalf("Expected the size to be kept at %vx%v, got %vx%v", width, height, w, h)
+	}
+	if editor.font_info.xUnit == xUnit || editor.cols >= 40 {
+		t.Fatalf("Expected the wider face to fit fewer columns, got %v", editor.cols)
+	}
+}
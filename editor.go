@@ -24,10 +24,14 @@ package noter
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"log"
+	"math"
+	"regexp"
 	"sort"
-	"unicode"
+	"sync"
+	"time"
 
 	"github.com/hajimehoshi/bitmapfont/v3"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -35,17 +39,40 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
 )
 
 const (
 	EDITOR_DEFAULT_ROWS = 25
 	EDITOR_DEFAULT_COLS = 80
+
+	EDITOR_DEFAULT_TAB_WIDTH = 4
+
+	EDITOR_DEFAULT_DRAG_SCROLL_SPEED = 20.0
+
+	EDITOR_DEFAULT_NUMBER_STEP = 1.0
+
+	// EDITOR_SEARCH_CHUNK is the number of lines searched at a time. A
+	// search of content with more lines is queued, see Queue.
+	EDITOR_SEARCH_CHUNK = 20000
 )
 
+// editorLine is a line of the content, in a linked list. The content is held
+// only as these lines, each with its own runes, rather than as a piece table
+// or rope: an edit within a line copies the line, so it takes time in the
+// length of the line, and finding a line takes O(log n) time with the line
+// index. Adding or removing a line must number the lines again, with
+// indexInserted and indexRemoved, or else invalidateLines.
 type editorLine struct {
 	prev   *editorLine
 	next   *editorLine
 	values []rune
+	data   map[string]any // values attached by the host, see SetLineData.
+	edited time.Time      // when the line was last edited, see Outline.
+	soft   bool           // true if the line ends in a soft break, see WithProseMode.
+	tree   lineTree       // the place of the line in the line index, see indexLines.
+
+	protected bool // true if the line is read-only, see SetLinesReadOnly.
 }
 
 type editorCursor struct {
@@ -88,6 +115,8 @@ type fontInfo struct {
 	ascent int       // ascent of the font above the baseline's origin.
 	xUnit  int       // xUnit is the text advance of the '0' glyph.
 	yUnit  int       // yUnit is the line height of the font.
+
+	widths map[rune]int // the columns of each wide rune, see runeColumns.
 }
 
 // Create a new fontInfo
@@ -100,21 +129,48 @@ func newfontInfo(font_face font.Face) (fi *fontInfo) {
 		ascent: metrics.Ascent.Ceil(),
 		xUnit:  advance.Ceil(),
 		yUnit:  metrics.Height.Ceil(),
+		widths: make(map[rune]int),
 	}
 
 	return fi
 }
 
+// Mode is the input mode of the editor.
+type Mode uint
+
 const (
-	EDIT_MODE = iota
+	EDIT_MODE Mode = iota
 	SEARCH_MODE
+	PROMPT_MODE
+
+	// customModeStart is the first Mode returned by RegisterMode.
+	customModeStart
 )
 
 var noop = func() bool { return false }
 
+// searchFailColor is the color of a search term without any matches.
+var searchFailColor = color.RGBA{200, 0, 0, 255}
+
+// fnCompound combines undo actions into a single undo action,
+// which undoes them in reverse order.
+func fnCompound(fns ...func() bool) func() bool {
+	return func() bool {
+		notNoop := false
+		for i := len(fns) - 1; i >= 0; i-- {
+			if fns[i]() {
+				notNoop = true
+			}
+		}
+		return notNoop
+	}
+}
+
 // Editor is a simple text editor, compliant to the ebiten.Game interface.
 //
-// The Meta or Control key can be used with the following command keys:
+// The Meta or Control key can be used with the following command keys
+// (see WithModifierPolicy, WithEscapeCommands and BindFunctionKey for
+// other ways to run them):
 //
 //	| Keystroke  | Action |
 //	| ---        | ---    |
@@ -122,17 +178,65 @@ var noop = func() bool { return false }
 //	| COMMAND-L  | Load the content. |
 //	| COMMAND-C  | Copy the selection to clipboard. |
 //	| COMMAND-V  | Paste clipboard into the selection/current cursor. |
+//	| COMMAND-SHIFT-V | Paste as plain text, with smart quotes made plain, as does a right-click. |
+//	| COMMAND-SHIFT-\ | Jump to the bracket matching the bracket by the cursor. |
 //	| COMMAND-X  | Cut the selection, saving a copy into the clipboard. |
 //	| COMMAND-F  | Find text in the content. |
+//	| COMMAND-SHIFT-D | Duplicate the selection, or the cursor line. |
+//	| COMMAND-SHIFT-U | Change the selection to upper case, or lower case with COMMAND-SHIFT-L. |
+//	| COMMAND-SHIFT-I | Reindent the lines with the indent of WithIndent. |
+//	| COMMAND-SHIFT-F | List all of the matches of the search in a panel. |
+//	| COMMAND-OPTION-F | List the matches of the search in all of the contents of the switcher. |
+//	| COMMAND-T  | Toggle the table alignment display mode. |
+//	| COMMAND-J  | Align the selected lines on a character or regex. |
+//	| COMMAND-R  | Reflow the paragraph at the cursor, or toggle regex search while searching. |
+//	| COMMAND-D  | Check or uncheck the task list checkbox, such as "- [ ]". |
+//	| COMMAND-ENTER | Insert a line below, or above with SHIFT. |
+//	| COMMAND-U  | Delete to the start of the line, as does COMMAND-BACKSPACE. |
+//	| COMMAND-P  | Open other content, see WithSwitcher. |
 //	| COMMAND-Q  | Quit the editor. |
+//	| COMMAND-PLUS | Grow the font of WithFont, or shrink it with COMMAND-MINUS. |
+//	| COMMAND-0  | Reset the size of the font. |
+//	| CONTROL-SPACE | Complete the word before the cursor, see WithDictionary. |
+//
+// Each of these runs a named command, such as "Save". Keystrokes can be
+// bound to other commands with Bind, and new commands added with
+// RegisterCommand, or a whole keymap chosen with WithKeymap.
+//
+// COMMAND-click adds a caret, so that typing applies at several places at
+// once, and Escape removes the added carets.
+//
+// F8 toggles selection mode, where moving the cursor extends the selection
+// without holding SHIFT.
+//
+// While searching, OPTION-C toggles whether the search matches case, and
+// OPTION-W whether it only matches whole words.
+//
+// When following appended content (see WithFollow), moving the cursor off the
+// last line pauses following, and COMMAND-DOWN resumes it.
+//
+// OPTION-UP / OPTION-DOWN move by paragraph, and CONTROL-COMMAND-UP /
+// CONTROL-COMMAND-DOWN swap the line with the one above or below.
+// OPTION-PAGEUP / OPTION-PAGEDOWN increment or decrement the number at the
+// cursor.
+//
+// When soft wrap is enabled, the Up and Down arrows move by visual row,
+// and COMMAND-OPTION-UP / COMMAND-OPTION-DOWN move by line.
+//
+// An Editor is not safe for concurrent use; see PostEdit to make changes
+// from other goroutines.
 type Editor struct {
 	// Settable options
 	font_info        *fontInfo
 	font_color       color.Color
+	number_color     color.Color
+	bar_color        color.Color
 	select_color     color.Color
 	search_color     color.Color
 	cursor_color     color.Color
+	mode_colors      map[Mode]color.Color
 	background_image *ebiten.Image
+	background_color color.Color // of background_image, if a single color.
 	clipboard        Content
 	content          Content
 	content_name     string
@@ -141,24 +245,153 @@ type Editor struct {
 	width            int
 	height           int
 	width_padding    int
+	padding          int // the padding of WithWithPadding, before scaling.
+	scale            float64
+	font_source      *opentype.Font
+	font_size        float64
+	font_base_size   float64 // the size of WithFont, see SetFontSize.
+	font_dpi         float64
+	ownFace          bool
 	bot_bar          bool
+	bot_bar_format   func(e *Editor) string
+	read_only        bool
+	ansi_colors      bool
+	highlighter      Highlighter
+	highlightStates  []any // see highlightState.
+	tokens           []*inlineToken
+	color_swatches   bool
+	color_picker     func(c color.NRGBA, set func(color.NRGBA))
+	swatchToken      *inlineToken
+	swatchImages     map[string]*ebiten.Image
+	accessibility    Accessibility
+	localizer        Localizer
+	announced        bool
+	spokenPos        Position
+	high_contrast    bool
+	reduced_motion   bool
+	saved            palette
+	follow           bool
 	top_bar          bool
+	soft_wrap        bool
+	line_numbers     bool
+	scope_highlight  bool
+	bracket_match    bool
+	bracket_color    color.Color
+	tab_width        int
+	number_step      float64
+	indent_tabs      bool
+	auto_indent      bool
+	indent_after     string
+	auto_surround    bool
+	list_editing     bool
+	prose_mode       bool
+	final_newline    bool
+	force_encoding   string
+	keep_bom         bool
+	streaming_load   bool
+	unloaded         []byte // the text still to load, see WithStreamingLoad.
+	loadReadOnly     bool
+	loadID           int
+	openAt           *Position // the position to move to once loaded, see OpenAt.
+	openEnd          int       // the end of a match to highlight at openAt, if any.
+	undoing          bool
+	select_line_ends bool
+	drag_speed       float64
+	logger           *log.Logger
+	on_error         func(err error)
+	on_mode_change   func(from, to Mode)
+	on_paste_image   func(image []byte) string
+	plain_paste      bool
+	visible_controls bool
+	show_whitespace  bool
+	scrollbar        bool
+	extensions       []Extension
+	table_mode       bool
+	table_delim      rune
+	hard_wrap        int
+	focus_cols       int
+	focus_dimming    bool
 
 	// Internal state
 	screen           *ebiten.Image
 	top_padding      int
 	bot_padding      int
-	mode             uint
+	mode             Mode
+	customMode       Mode
+	customModes      []customMode
 	searchIndex      int
+	searchMatches    int
 	searchTerm       []rune
+	searchInvalid    bool
+	searchWork       *queuedWork // a queued search, see runSearch.
+	lineEdit         *lineEdit   // a queued edit, see queueEdit.
+	regex_search     bool
+	search_case      bool
+	search_word      bool
+	selecting        bool
+	modifier_policy  ModifierPolicy
+	escape_commands  bool
+	functionKeys     map[ebiten.Key]string
+	workQueue        []*queuedWork
+	postMu           sync.Mutex
+	posted           []func(e *Editor)
+	carets           []int
+	caretsOn         map[*editorLine][]int
+	dictionaries     []*Dictionary
+	dataLines        map[*editorLine]bool
+	dataRemoved      []*editorLine // see removeLineData.
+	on_data_removed  func(key string, value any)
+	keymap           *Keymap
+	lines            *lineIndex // see indexLines.
+	commands         map[string]func(e *Editor)
+	bindings         map[string]string
+	escapeMode       Mode
 	start            *editorLine
 	firstVisible     int
 	cursor           *editorCursor
 	modified         bool
+	following        bool
+	lineEnding       string
+	encoding         string
+	bom              bool
 	highlighted      map[*editorLine]map[int]bool
 	searchHighlights map[*editorLine]map[int]bool
+	prompt           string
+	promptTerm       []rune
+	input            lineInput
+	promptAction     func(input string)
+	promptInput      func(input string) // called as the input changes.
+	switch_names     func() []string
+	switch_open      func(name string) Content
+	recentNames      []string
+	tutorial         *tutorialState
 	undoStack        []func() bool
+	overlays         []Overlay
+	caretBounds      image.Rectangle
+	diagnostics      Diagnostics
+	drawnSinceUpdate bool
+	dirty            bool
+	degraded         bool
+	notice           string
+	noticeUntil      time.Time
+	chords           map[string]map[string]func()
+	pendingChord     string
+	chordUntil       time.Time
+	screenRows       []screenRow
+	dragging         bool
+	dragAnchor       editorCursor
+	dragScroll       float64
+	thumbDragging    bool
+	thumbGrab        int // the point of the thumb dragged, see clickScrollbar.
+	modeOverlay      *modeOverlay
+	views            *viewGroup // the views of the content, see NewView.
 	quit             func()
+	tableWidths      []int // the widths of the table columns, until the content is edited.
+	gutterCols       int
+	viewInfo         ViewInfo
+	blockScope       *blockScope
+	matchedBrackets  map[*editorLine]map[int]bool
+	findPanel        *findPanel
 }
 
 // EditorOption is an option that can be sent to NewEditor()
@@ -207,6 +440,31 @@ func WithBottomBar(enabled bool) EditorOption {
 	}
 }
 
+// WithBottomBarFormatter sets the function returning the text of the bottom
+// bar, in place of the help and cursor position, so that the host can show
+// its own status. Notices, progress and chords are still shown in its
+// place while they last, and the mode, line ending and encoding at the
+// right. The default is the help and cursor position.
+func WithBottomBarFormatter(opt func(e *Editor) string) EditorOption {
+	return func(e *Editor) {
+		if opt == nil {
+			opt = func(e *Editor) string {
+				return e.tr("(x)cut (c)opy (v)paste (s)ave (q)uit (f)search [%v:%v:%v] ", e.getLineNumber()+1, e.cursor.x+1, e.cursor.line.values[e.cursor.x])
+			}
+		}
+		e.bot_bar_format = opt
+	}
+}
+
+// WithSelectionLineEnds sets whether a selection which includes the end of a
+// line is drawn up to the right edge, so that selected lines form a block.
+// The default is enabled.
+func WithSelectionLineEnds(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.select_line_ends = enabled
+	}
+}
+
 // WithClipboard sets the clipboard accessor.
 // If set to nil, an in-memory content manager is used.
 func WithClipboard(opt Content) EditorOption {
@@ -220,13 +478,14 @@ func WithClipboard(opt Content) EditorOption {
 
 // WithFontFace set the default font.
 // If set to nil, the monospace font `github.com/hajimehoshi/bitmapfont/v3`
-// is used.
+// is used. The face isn't drawn at the scale of the display, so it's
+// blurry on HiDPI displays; see WithFont.
 func WithFontFace(opt font.Face) EditorOption {
 	return func(e *Editor) {
 		if opt == nil {
 			opt = bitmapfont.Face
 		}
-		e.font_info = newfontInfo(opt)
+		e.setFace(opt, false)
 	}
 }
 
@@ -270,6 +529,7 @@ func WithBackgroundColor(opt color.Color) EditorOption {
 		img := ebiten.NewImage(1, 1)
 		img.Fill(opt)
 		WithBackgroundImage(img)(e)
+		e.background_color = opt
 	}
 }
 
@@ -278,6 +538,7 @@ func WithBackgroundColor(opt color.Color) EditorOption {
 func WithBackgroundImage(opt *ebiten.Image) EditorOption {
 	return func(e *Editor) {
 		e.background_image = opt
+		e.background_color = nil
 	}
 }
 
@@ -351,23 +612,48 @@ func NewEditor(options ...EditorOption) (e *Editor) {
 		width:         -1,
 		height:        -1,
 		width_padding: -1,
+		tab_width:     EDITOR_DEFAULT_TAB_WIDTH,
+		drag_speed:    EDITOR_DEFAULT_DRAG_SCROLL_SPEED,
+		number_step:   EDITOR_DEFAULT_NUMBER_STEP,
+		scale:         1,
+		lines:         &lineIndex{},
 	}
 
+	registerBuiltinCommands(e)
 	WithQuit(nil)(e)
+	WithKeymap(nil)(e)
+	WithLogger(nil)(e)
+	WithOnError(nil)(e)
+	WithOnModeChange(nil)(e)
+	WithBottomBarFormatter(nil)(e)
+	WithAutoSurround(true)(e)
+	WithVisibleControls(true)(e)
+	WithSelectionLineEnds(true)(e)
+	WithFinalNewline(true)(e)
+	WithKeepBOM(true)(e)
 	WithContent(nil)(e)
 	WithClipboard(nil)(e)
 	WithFontFace(nil)(e)
-	WithFontColor(color.Black)(e)
-	WithBackgroundColor(color.White)(e)
-	WithCursorColor(color.RGBA{0, 0, 0, 90})(e)
-	WithHighlightColor(color.RGBA{0, 0, 200, 70})(e)
-	WithSearchColor(color.RGBA{0, 200, 0, 70})(e)
+	WithTheme(ThemeLight)(e)
 
 	for _, opt := range options {
 		opt(e)
 	}
 
+	// Make the face of any font, at the scale of a standard display until
+	// the first layout. Without one, there is nothing to zoom.
+	if e.font_source != nil {
+		if face, err := e.newFace(e.font_size, e.scale); err == nil {
+			e.setFace(face, true)
+		} else {
+			e.on_error(err)
+		}
+	} else {
+		e.unbindZoom()
+	}
+
 	// Determine padding.
+	e.padding = e.width_padding
 	if e.width_padding < 0 {
 		e.width_padding = e.font_info.xUnit / 2
 	}
@@ -420,22 +706,65 @@ func NewEditor(options ...EditorOption) (e *Editor) {
 	// Create the internal image
 	e.screen = ebiten.NewImage(e.width, e.height)
 
+	if e.high_contrast {
+		e.applyContrast()
+	}
+
 	// Load content.
 	e.Load()
 
+	// Set up the key bindings, then attach extensions to the complete editor.
+	e.keymap.apply(e)
+	for _, ext := range e.extensions {
+		ext.Attach(e)
+	}
+
 	return e
 }
 
 func (e *Editor) searchMode() {
+	defer e.modeChanged(e.Mode())
 	e.resetHighlight()
 	e.mode = SEARCH_MODE
 	e.searchHighlights = make(map[*editorLine]map[int]bool)
+	e.input = newLineInput(&e.searchTerm)
+	e.pushModeOverlay()
+}
+
+// pushModeOverlay shows the input bar of the current mode as an overlay.
+func (e *Editor) pushModeOverlay() {
+	e.RemoveOverlay(e.modeOverlay)
+	e.modeOverlay = &modeOverlay{}
+	e.PushOverlay(e.modeOverlay)
 }
 
 func (e *Editor) editMode() {
+	defer e.modeChanged(e.Mode())
+	if e.modeOverlay != nil {
+		e.RemoveOverlay(e.modeOverlay)
+		e.modeOverlay = nil
+	}
 	e.mode = EDIT_MODE
+	e.cancelSearch()
+	e.searchMatches = 0
 	e.searchTerm = make([]rune, 0)
 	e.searchHighlights = make(map[*editorLine]map[int]bool)
+	e.promptTerm = make([]rune, 0)
+	e.promptAction = nil
+	e.promptInput = nil
+	e.input = lineInput{}
+}
+
+// promptMode asks for a line of input in the top bar. The action is
+// called with the input, back in EDIT_MODE, when Enter is pressed.
+func (e *Editor) promptMode(prompt string, action func(input string)) {
+	defer e.modeChanged(e.Mode())
+	e.mode = PROMPT_MODE
+	e.prompt = prompt
+	e.promptTerm = make([]rune, 0)
+	e.promptAction = action
+	e.input = newLineInput(&e.promptTerm)
+	e.pushModeOverlay()
 }
 
 func (e *Editor) fnDeleteHighlighted() func() bool {
@@ -468,6 +797,7 @@ func (e *Editor) fnDeleteHighlighted() func() bool {
 	}
 
 	highlightedRunes := e.getHighlightedRunes()
+	e.announce("deleted %s", e.spoken(highlightedRunes))
 
 	for i := 0; i < highlightCount; i++ {
 		e.deletePrevious()
@@ -478,9 +808,13 @@ func (e *Editor) fnDeleteHighlighted() func() bool {
 
 	return func() bool {
 		e.MoveCursor(lineNum, curX)
+		startLine := e.cursor.line
 		for _, r := range highlightedRunes {
 			e.handleRune(r)
 		}
+
+		// Restore the selection.
+		e.highlightBetween(startLine, curX, e.cursor.line, e.cursor.x)
 		return true
 	}
 }
@@ -491,6 +825,9 @@ func (e *Editor) resetHighlight() {
 
 func (e *Editor) setModified() {
 	e.modified = true
+	e.cursor.line.edited = time.Now()
+	e.invalidateHighlight(e.cursor.line)
+	e.shareModified()
 }
 
 // IsModified returns true if the editor is in modified state.
@@ -506,6 +843,7 @@ func (e *Editor) Save() {
 	}
 
 	e.modified = false
+	e.shareModified()
 }
 
 // Load loads the text from the Content assigned to the editor.
@@ -515,125 +853,234 @@ func (e *Editor) Load() {
 	}
 }
 
-// ReadText returns all of the text in the editor.
+// ReadText returns all of the text in the editor, with the line ending and
+// encoding of the content (see LineEnding and Encoding).
 // Note that this does not clear the 'modified' state of the editor.
 func (e *Editor) ReadText() []byte {
+	return encodeText(e.readText(), e.encoding, e.bom && e.keep_bom)
+}
+
+// readText returns all of the text in the editor as UTF-8, with the line
+// ending of the content.
+func (e *Editor) readText() []byte {
 	allRunes := e.getAllRunes()
+	if e.prose_mode {
+		allRunes = e.joinSoftBreaks(allRunes)
+	}
 
-	return []byte(string(allRunes))
+	// The new line of the final line is virtual.
+	allRunes = allRunes[:len(allRunes)-1]
+
+	// Ensure the text ends with `\n`, unless written without one.
+	if e.final_newline && len(allRunes) > 0 && allRunes[len(allRunes)-1] != '\n' {
+		allRunes = append(allRunes, '\n')
+	}
+
+	// Include any text still to load, which has its line endings already.
+	return append([]byte(e.addLineEndings(string(allRunes))), e.unloaded...)
 }
 
-// WriteText replaces all of the text in the editor.
+// WriteText replaces all of the text in the editor, decoding it from its
+// encoding (see Encoding).
 // Note that this clears the 'modified' state of the editor, and disables
-// all selection highlighting.
+// all selection highlighting. It detaches the editor from any other views
+// (see NewView).
 func (e *Editor) WriteText(text []byte) {
-	source := string(text)
-
+	e.endLoad()
+	e.cancelEdit()
+	e.cancelSearch()
+	text, e.encoding, e.bom = decodeContent(text, e.force_encoding)
+
+	if e.streaming_load && len(text) > EDITOR_LOAD_CHUNK {
+		// Load the first chunk now, and the rest as queued work.
+		end := chunkEnd(text, 0)
+		if end < len(text) {
+			e.beginLoad(text[end:])
+		}
+		text = text[:end]
+	}
 	e.editMode()
 	e.undoStack = make([]func() bool, 0)
 	e.searchTerm = make([]rune, 0)
 	e.highlighted = make(map[*editorLine]map[int]bool)
+	e.detachView()
 	e.start = &editorLine{values: make([]rune, 0)}
 	e.cursor = &editorCursor{line: e.start, x: 0}
+	e.highlightStates = nil
+	e.tableWidths = nil
+	e.invalidateLines()
+	e.lineEnding = detectLineEnding(text)
+	source := e.stripLineEndings(string(text))
 	currentLine := e.start
 
-	if len(source) == 0 {
-		currentLine.values = append(currentLine.values, '\n')
-	} else {
-		for _, char := range source {
-			currentLine.values = append(currentLine.values, char)
-			if char == '\n' {
-				nextLine := &editorLine{values: make([]rune, 0)}
-				currentLine.next = nextLine
-				nextLine.prev = currentLine
-				currentLine = nextLine
-			}
+	for _, char := range source {
+		currentLine.values = append(currentLine.values, char)
+		if char == '\n' {
+			nextLine := &editorLine{values: make([]rune, 0)}
+			currentLine.next = nextLine
+			nextLine.prev = currentLine
+			currentLine = nextLine
 		}
 	}
 
-	// Ensure the final line ends with `\n`
-	if len(currentLine.values) > 0 && currentLine.values[len(currentLine.values)-1] != '\n' {
-		currentLine.values = append(currentLine.values, '\n')
-	}
+	// The final line, which is empty after a trailing new line, ends
+	// with a virtual `\n` so the cursor can be placed after all the text.
+	currentLine.values = append(currentLine.values, '\n')
 
-	// Remove dangling line
-	if currentLine.prev != nil {
-		currentLine.prev.next = nil
-	}
+	// All of the lines have been replaced, leaving any other views with
+	// the content as it was.
+	e.checkLineData()
 
 	// Refresh the internal image.
 	e.updateImage()
 }
 
+// searchScan is a search of the content in progress, which for long
+// content is queued as Work, a chunk of lines at a time.
+type searchScan struct {
+	row int            // the next row to search.
+	re  *regexp.Regexp // the pattern, for a regular expression search.
+
+	// Store the location of all runes that are part of a possible match
+	termIndex int
+	partial   map[*editorLine]map[int]bool
+
+	// Store the starting lines and line indexes of every match
+	// this will be used to tab between results
+	lines []*editorLine
+	xs    []int
+}
+
 func (e *Editor) search() {
+	e.runSearch(e.lineCount() > EDITOR_SEARCH_CHUNK)
+}
+
+// runSearch highlights the matches of the search term and moves to the
+// match at the search index. If queue is true, the lines are searched as
+// queued work, EDITOR_SEARCH_CHUNK lines at a time, and the cursor moves
+// once they all have been. Any search still queued is cancelled.
+func (e *Editor) runSearch(queue bool) {
 	// Always reset search highlights (for empty searches)
 	e.searchHighlights = make(map[*editorLine]map[int]bool)
+	e.searchMatches = 0
+	e.searchInvalid = false
+	e.cancelSearch()
 
 	if len(e.searchTerm) == 0 {
 		return
 	}
 
-	curLine := e.start
-	searchTermIndex := 0
+	scan := &searchScan{partial: make(map[*editorLine]map[int]bool)}
+	if e.regex_search {
+		scan.re = e.searchPattern()
+		if scan.re == nil {
+			e.moveToMatch(nil, nil)
+			return
+		}
+	}
 
-	// Store the location of all runes that are part of a result
-	// this will be used render search highlights
-	possibleMatches := make(map[*editorLine]map[int]bool, 0)
+	if !queue {
+		e.searchLines(scan, e.lineCount())
+		e.moveToMatch(scan.lines, scan.xs)
+		return
+	}
+	e.searchWork = e.queue(e.tr("search"), func() (progress float64, done bool) {
+		if !e.searchLines(scan, EDITOR_SEARCH_CHUNK) {
+			e.searchMatches = len(scan.lines)
+			return float64(scan.row) / float64(e.lineCount()), false
+		}
+		e.searchWork = nil
+		e.moveToMatch(scan.lines, scan.xs)
+		return 1, true
+	})
+}
 
-	// Store the starting lines and line indexes of every match
-	// this will be used to tab between results
-	possibleLines := make([]*editorLine, 0)
-	possibleXs := make([]int, 0)
+// cancelSearch stops any search which is queued.
+func (e *Editor) cancelSearch() {
+	if e.searchWork != nil {
+		e.unqueue(e.searchWork)
+		e.searchWork = nil
+	}
+}
 
-	for curLine != nil {
-		for index, r := range curLine.values {
-			if unicode.ToLower(e.searchTerm[searchTermIndex]) == unicode.ToLower(r) {
+// searchLines searches up to count lines, from the row of the scan. It
+// returns true once all of the lines have been searched.
+func (e *Editor) searchLines(scan *searchScan, count int) bool {
+	curLine := e.lineAt(scan.row)
+	for ; count > 0 && scan.row < e.lineCount(); count-- {
+		if scan.re != nil {
+			e.searchLineRegexp(scan, curLine)
+		} else {
+			e.searchLine(scan, curLine)
+		}
+		curLine = curLine.next
+		scan.row++
+	}
+	return scan.row >= e.lineCount()
+}
 
-				// We've found the possible start of a match
-				if searchTermIndex == 0 {
-					possibleLines = append(possibleLines, curLine)
-					possibleXs = append(possibleXs, index)
-				}
-				searchTermIndex++
+// searchLine searches a line for the search term, continuing any match
+// from the line before.
+func (e *Editor) searchLine(scan *searchScan, curLine *editorLine) {
+	for index, r := range curLine.values {
+		if e.searchRuneMatches(e.searchTerm[scan.termIndex], r) {
 
-				// We've found part of a possible match
-				if _, ok := possibleMatches[curLine]; !ok {
-					possibleMatches[curLine] = make(map[int]bool)
-				}
-				possibleMatches[curLine][index] = true
-			} else {
-				// Clear up the incorrect possible start
-				if searchTermIndex > 0 {
-					possibleLines = possibleLines[:len(possibleLines)-1]
-					possibleXs = possibleXs[:len(possibleXs)-1]
-				}
+			// We've found the possible start of a match
+			if scan.termIndex == 0 {
+				scan.lines = append(scan.lines, curLine)
+				scan.xs = append(scan.xs, index)
+			}
+			scan.termIndex++
+
+			// We've found part of a possible match
+			if _, ok := scan.partial[curLine]; !ok {
+				scan.partial[curLine] = make(map[int]bool)
+			}
+			scan.partial[curLine][index] = true
+		} else {
+			// Clear up the incorrect possible start
+			if scan.termIndex > 0 {
+				scan.lines = scan.lines[:len(scan.lines)-1]
+				scan.xs = scan.xs[:len(scan.xs)-1]
+			}
+
+			scan.termIndex = 0
 
-				searchTermIndex = 0
+			// Clear up the incorrect possible match parts
+			scan.partial = make(map[*editorLine]map[int]bool, 0)
+		}
 
-				// Clear up the incorrect possible match parts
-				possibleMatches = make(map[*editorLine]map[int]bool, 0)
+		// We found a full match. Save the match parts for highlighting
+		// and reset all state to check for more matches
+		if scan.termIndex == len(e.searchTerm) {
+			start := scan.xs[len(scan.xs)-1]
+			if e.search_word && !isWholeWord(curLine.values, start, index+1) {
+				// Discard a match within a word
+				scan.lines = scan.lines[:len(scan.lines)-1]
+				scan.xs = scan.xs[:len(scan.xs)-1]
+				scan.partial = make(map[*editorLine]map[int]bool, 0)
 			}
 
-			// We found a full match. Save the match parts for highlighting
-			// and reset all state to check for more matches
-			if searchTermIndex == len(e.searchTerm) {
-				for line := range possibleMatches {
-					for x := range possibleMatches[line] {
-						if _, ok := e.searchHighlights[line]; !ok {
-							e.searchHighlights[line] = make(map[int]bool)
-						}
-						e.searchHighlights[line][x] = true
+			for line := range scan.partial {
+				for x := range scan.partial[line] {
+					if _, ok := e.searchHighlights[line]; !ok {
+						e.searchHighlights[line] = make(map[int]bool)
 					}
+					e.searchHighlights[line][x] = true
 				}
-
-				searchTermIndex = 0
-				possibleMatches = make(map[*editorLine]map[int]bool, 0)
 			}
+
+			scan.termIndex = 0
+			scan.partial = make(map[*editorLine]map[int]bool, 0)
 		}
-		curLine = curLine.next
 	}
+}
 
+// moveToMatch moves the cursor to the match at the search index, given the
+// line and rune index of the start of each match.
+func (e *Editor) moveToMatch(possibleLines []*editorLine, possibleXs []int) {
 	// Were there any full matches?
+	e.searchMatches = len(possibleLines)
 	if len(possibleLines) > 0 {
 
 		// Have we tabbed before the first full match?
@@ -659,13 +1106,65 @@ func (e *Editor) search() {
 	e.searchIndex = 0
 }
 
+// HasMatches returns true if the current search term has any matches.
+func (e *Editor) HasMatches() bool {
+	return e.searchMatches > 0
+}
+
+// MatchCount returns the number of matches of the current search term.
+func (e *Editor) MatchCount() int {
+	return e.searchMatches
+}
+
+// searchStatus returns the note after the search term, such as "(3/17)"
+// for the third of seventeen matches, and true if the term has failed.
+func (e *Editor) searchStatus() (string, bool) {
+	switch {
+	case len(e.searchTerm) == 0:
+		return "", false
+	case e.searchInvalid:
+		return e.tr(" (invalid pattern)"), true
+	case e.searchMatches == 0:
+		return e.tr(" (no matches)"), true
+	}
+	return fmt.Sprintf(" (%d/%d)", e.searchIndex+1, e.searchMatches), false
+}
+
+// drawSearchBar renders the search term into the top bar, after the prompt.
+// A term without matches is drawn in red, with a "no matches" note.
+func (e *Editor) drawSearchBar(prompt string) {
+	termColor := e.barColor()
+	status, failed := e.searchStatus()
+	if failed {
+		termColor = searchFailColor
+	}
+
+	fontFace := e.font_info.face
+	x := e.width_padding
+	for _, part := range []struct {
+		text  string
+		color color.Color
+	}{
+		{prompt, e.barColor()},
+		{string(e.searchTerm), termColor},
+		{status, e.barColor()},
+	} {
+		if part.text == prompt {
+			e.drawInput(x + font.MeasureString(fontFace, prompt).Ceil())
+		}
+		text.Draw(e.screen, part.text, fontFace, x, e.font_info.ascent, part.color)
+		x += font.MeasureString(fontFace, part.text).Ceil()
+	}
+}
+
 func (e *Editor) fnHandleRuneSingle(r rune) func() bool {
 	undoDeleteHighlighted := func() bool { return false }
-	if len(e.highlighted) != 0 {
+	if len(e.highlighted) != 0 && e.mode == EDIT_MODE {
 		undoDeleteHighlighted = e.fnDeleteHighlighted()
 	}
 
 	e.handleRune(r)
+	e.announce("inserted %s", e.spoken([]rune{r}))
 
 	lineNum := e.getLineNumber()
 	curX := e.cursor.x
@@ -679,13 +1178,14 @@ func (e *Editor) fnHandleRuneSingle(r rune) func() bool {
 
 func (e *Editor) fnHandleRuneMulti(rs []rune) func() bool {
 	undoDeleteHighlighted := func() bool { return false }
-	if len(e.highlighted) != 0 {
+	if len(e.highlighted) != 0 && e.mode == EDIT_MODE {
 		undoDeleteHighlighted = e.fnDeleteHighlighted()
 	}
 
 	for _, r := range rs {
 		e.handleRune(r)
 	}
+	e.announce("inserted %s", e.spoken(rs))
 
 	lineNum := e.getLineNumber()
 	curX := e.cursor.x
@@ -700,9 +1200,11 @@ func (e *Editor) fnHandleRuneMulti(rs []rune) func() bool {
 }
 
 func (e *Editor) handleRune(r rune) {
-	if e.mode == SEARCH_MODE {
-		e.searchTerm = append(e.searchTerm, r)
-		e.search()
+	if e.mode != EDIT_MODE {
+		if r != '\n' {
+			e.input.insert([]rune{r})
+			e.inputChanged()
+		}
 		return
 	}
 
@@ -714,17 +1216,24 @@ func (e *Editor) handleRune(r rune) {
 		before := e.cursor.line
 		after := e.cursor.line.next
 
-		shiftedValues := make([]rune, 0)
-		leftBehindValues := make([]rune, 0)
+		// Lines are copied rather than edited in place, as undo actions
+		// may hold their previous values. Each is allocated once.
+		shiftedValues := make([]rune, 0, len(e.cursor.line.values)-e.cursor.x)
+		leftBehindValues := make([]rune, 0, e.cursor.x+1)
 		shiftedValues = append(shiftedValues, e.cursor.line.values[e.cursor.x:]...)
 		leftBehindValues = append(leftBehindValues, e.cursor.line.values[:e.cursor.x]...)
 		leftBehindValues = append(leftBehindValues, '\n')
 		e.cursor.line.values = leftBehindValues
+		e.cursor.line.edited = time.Now()
+		e.invalidateHighlight(e.cursor.line)
+		soft := before.soft
+		before.soft = false
 
 		e.cursor.line = &editorLine{
 			values: shiftedValues,
 			prev:   before,
 			next:   after,
+			soft:   soft,
 		}
 		e.cursor.x = 0
 
@@ -734,8 +1243,9 @@ func (e *Editor) handleRune(r rune) {
 		if after != nil {
 			after.prev = e.cursor.line
 		}
+		e.indexInserted(before, e.cursor.line)
 	} else {
-		modifiedLine := make([]rune, 0)
+		modifiedLine := make([]rune, 0, len(e.cursor.line.values)+1)
 		modifiedLine = append(modifiedLine, e.cursor.line.values[:e.cursor.x]...)
 		modifiedLine = append(modifiedLine, r)
 		modifiedLine = append(modifiedLine, e.cursor.line.values[e.cursor.x:]...)
@@ -784,12 +1294,28 @@ func (e *Editor) fixPosition() {
 	case lineno > (e.firstVisible + e.rows - 1):
 		e.firstVisible = lineno - (e.rows - 1)
 	}
+
+	if e.soft_wrap {
+		e.fixWrappedPosition(lineno)
+	}
 }
 
 // Update the editor state.
+// A panic while updating is recovered, and reported to the error handler.
 func (e *Editor) Update() error {
-	// Update the internal image when complete.
-	defer e.updateImage()
+	defer e.recoverPanic("update")
+	return e.update()
+}
+
+func (e *Editor) update() error {
+	// Update the internal image when complete, if anything has changed.
+	e.checkDirty()
+	defer e.updateImageIfDirty()
+	defer e.updateFollowing()
+	defer e.announcePosition()
+
+	// Apply edits posted from other goroutines.
+	e.runPosted()
 
 	// // Log key number
 	// for i := 0; i < int(ebiten.KeyMax); i++ {
@@ -800,13 +1326,53 @@ func (e *Editor) Update() error {
 	// }
 
 	// Modifiers
-	command := ebiten.IsKeyPressed(ebiten.KeyMeta) || ebiten.IsKeyPressed(ebiten.KeyControl)
+	command, option := e.modifiers()
 	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
-	option := ebiten.IsKeyPressed(ebiten.KeyAlt)
+
+	// Control and Meta together are distinct from either alone.
+	controlCommand := ebiten.IsKeyPressed(ebiten.KeyMeta) && ebiten.IsKeyPressed(ebiten.KeyControl)
 
 	isCommand := command && !(shift || option)
 	isOnly := !(command || shift || option)
 
+	// Forget a chord which wasn't completed in time.
+	e.expireChord()
+
+	// Continue any queued work.
+	e.runQueue()
+
+	// A tutorial ignores other input while it plays.
+	if e.updateTutorial() {
+		return nil
+	}
+
+	// The topmost overlay handles input first.
+	if e.updateOverlays() {
+		return nil
+	}
+
+	// Then any custom mode.
+	if e.updateCustomMode() {
+		return nil
+	}
+
+	// Then clicking and dragging to select.
+	if e.updateMouse() {
+		return nil
+	}
+
+	// Option-C toggles case sensitive search, and Option-W whole word search.
+	if e.mode == SEARCH_MODE && option && !(command || shift) {
+		if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+			e.SetSearchCaseSensitive(!e.search_case)
+			return nil
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+			e.SetSearchWholeWord(!e.search_word)
+			return nil
+		}
+	}
+
 	// Although ebiten.AppendInputChars() would seem to be a better
 	// solution, it 'eats' the CONTROL meta character on Linux, and
 	// does not return a rune.
@@ -823,81 +1389,42 @@ func (e *Editor) Update() error {
 			letter = string([]rune{rune('a') + rune(key-ebiten.KeyA)})
 		}
 
-		// Command-KEY codes.
-		if isCommand {
-			switch letter {
-			case "f":
-				// Enter search mode
-				if e.mode == SEARCH_MODE {
-					e.editMode()
-				} else {
-					e.searchMode()
-				}
-			case "z":
-				// Undo (may repeat)
-				e.editMode()
-				e.resetHighlight()
-
-				for len(e.undoStack) > 0 {
-					notNoop := e.undoStack[len(e.undoStack)-1]()
-					e.undoStack = e.undoStack[:len(e.undoStack)-1]
-					if notNoop {
-						break
-					}
-				}
-			case "q":
-				// Quit
-				e.quit()
-			case "s":
-				// Save
-				e.Save()
-			case "a":
-				// Highlight all
-				e.editMode()
-				e.fnSelectAll()
-			case "v":
-				// Paste (may repeat)
-				pasteBytes := e.clipboard.ReadText()
-				rs := []rune{}
-				for _, r := range string(pasteBytes) {
-					rs = append(rs, r)
-				}
-				e.storeUndoAction(e.fnHandleRuneMulti(rs))
-				e.setModified()
-			case "x":
-				// Cut highlight
-				copyRunes := e.getHighlightedRunes()
-				if len(copyRunes) == 0 {
-					break
-				}
+		// Commands bound to keystrokes, such as "ctrl+a".
+		if (e.mode == EDIT_MODE || e.mode == SEARCH_MODE) && e.runBinding(key, letter) {
+			return nil
+		}
 
-				e.clipboard.WriteText([]byte(string(copyRunes)))
+		// Commands bound to function keys.
+		if command, ok := e.functionKeys[key]; ok && isOnly {
+			e.runCommand(command)
+			return nil
+		}
 
-				e.storeUndoAction(e.fnDeleteHighlighted())
-				e.resetHighlight()
+		// A key following Escape, when Escape begins commands.
+		if e.pendingChord == escapeChord && isOnly && letter != "" {
+			e.pendingChord = ""
+			e.runCommand(letter)
+			return nil
+		}
 
-				e.setModified()
-			case "c":
-				// Copy highlight
-				if len(e.highlighted) == 0 {
-					break
-				}
-				copyRunes := e.getHighlightedRunes()
-				copyBytes := []byte(string(copyRunes))
-				e.clipboard.WriteText(copyBytes)
-			default:
-				// Ignored key
-			}
+		// Command-KEY codes.
+		if isCommand {
+			e.runCommand(letter)
 		}
 	}
 
 	// All other keys that can be converted into runes.
 	// Even handles emoji input!
-	if !(command || option) {
+	if !(command || option) && !(e.read_only && e.mode == EDIT_MODE) {
 		// Keys which are valid input
 		letters := ebiten.AppendInputChars(nil)
+		if len(letters) > 0 && e.mode == EDIT_MODE && !e.canEdit() {
+			letters = nil
+		}
 		for _, letter := range letters {
-			e.storeUndoAction(e.fnHandleRuneSingle(letter))
+			e.storeUndoAction(e.atCarets(func() func() bool {
+				return e.fnTypeRune(letter)
+			}))
 		}
 	}
 
@@ -911,9 +1438,37 @@ func (e *Editor) Update() error {
 	home := isKeyJustPressedOrRepeating(ebiten.KeyHome)
 	end := isKeyJustPressedOrRepeating(ebiten.KeyEnd)
 
-	// Exit search mode
+	// F8 toggles selection mode, and Escape leaves it.
+	if isOnly && e.mode == EDIT_MODE {
+		if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
+			e.SetSelecting(!e.selecting)
+			return nil
+		}
+		if e.selecting && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			e.SetSelecting(false)
+			return nil
+		}
+		if len(e.carets) > 0 && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			e.ClearCarets()
+			return nil
+		}
+	}
+
+	// Dismiss the topmost overlay, such as the search bar.
 	if isOnly && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		e.editMode()
+		if !e.DismissOverlay() {
+			// The keymap may leave EDIT_MODE for another mode, such as
+			// the normal mode of Vim.
+			if e.escapeMode != EDIT_MODE && e.Mode() == EDIT_MODE {
+				e.SetMode(e.escapeMode)
+				return nil
+			}
+			if e.escape_commands && e.Mode() == EDIT_MODE {
+				e.beginEscapeCommand()
+				return nil
+			}
+			e.SetMode(EDIT_MODE)
+		}
 		return nil
 	}
 
@@ -930,39 +1485,43 @@ func (e *Editor) Update() error {
 		return nil
 	}
 
+	// Move within the search or prompt input
+	if !(command || option) && (right || left || home || end) && e.mode != EDIT_MODE {
+		switch {
+		case right:
+			e.input.move(e.input.cursor+1, shift)
+		case left:
+			e.input.move(e.input.cursor-1, shift)
+		case home:
+			e.input.move(0, shift)
+		case end:
+			e.input.move(len(*e.input.runes), shift)
+		}
+		return nil
+	}
+
 	// Handle movement
 	if right || left || up || down || home || end || pageup || pagedown {
 		e.editMode()
 
+		// Selection mode extends the selection, as if shift were held.
+		shift = shift || e.selecting
+
 		// Clear up old highlighting
 		if !shift {
 			e.resetHighlight()
 		}
 
-		// Option scanning finds the next emptyType after hitting a non-emptyType
-		// TODO: the characters that we filter for needs improving
-		emptyTypes := map[rune]bool{' ': true, '.': true, ',': true}
-
 		switch {
 		case end:
 			switch {
 			case !option && !command:
-				for e.cursor.x < len(e.cursor.line.values)-1 {
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-					e.cursor.x++
-				}
+				e.moveLineEnd(shift)
 			}
 		case home:
 			switch {
 			case !option && !command:
-				for e.cursor.x > 0 {
-					e.cursor.x--
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-				}
+				e.moveLineStart(shift)
 			}
 		case pagedown:
 			switch {
@@ -985,27 +1544,9 @@ func (e *Editor) Update() error {
 		case right:
 			switch {
 			case option && !command:
-				// Find the next empty
-				for e.cursor.x < len(e.cursor.line.values)-2 {
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-					e.cursor.x++
-					if ok := emptyTypes[e.cursor.line.values[e.cursor.x]]; !ok {
-					} else {
-						break
-					}
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-				}
+				e.moveWordRight(shift)
 			case !option && command:
-				for e.cursor.x < len(e.cursor.line.values)-1 {
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-					e.cursor.x++
-				}
+				e.moveLineEnd(shift)
 			case !option && !command:
 				if e.cursor.x < len(e.cursor.line.values)-1 {
 					if shift {
@@ -1023,38 +1564,9 @@ func (e *Editor) Update() error {
 		case left:
 			switch {
 			case option && !command:
-				// Find the next non-empty
-				for e.cursor.x > 0 {
-					e.cursor.x--
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-					if ok := emptyTypes[e.cursor.line.values[e.cursor.x]]; !ok {
-						break
-					}
-				}
-
-				// Find the next empty
-				for e.cursor.x > 0 {
-					if ok := emptyTypes[e.cursor.line.values[e.cursor.x-1]]; !ok {
-						if shift {
-							e.highlight(e.cursor.line, e.cursor.x)
-						}
-					} else {
-						break
-					}
-					e.cursor.x--
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-				}
+				e.moveWordLeft(shift)
 			case !option && command:
-				for e.cursor.x > 0 {
-					e.cursor.x--
-					if shift {
-						e.highlight(e.cursor.line, e.cursor.x)
-					}
-				}
+				e.moveLineStart(shift)
 			case !option && !command:
 				if e.cursor.x > 0 {
 					e.cursor.x--
@@ -1071,8 +1583,12 @@ func (e *Editor) Update() error {
 			}
 		case up:
 			switch {
+			case controlCommand && !option && !shift:
+				if e.canEdit(e.cursor.line.prev) {
+					e.storeUndoAction(e.fnSwapUp())
+				}
 			case option && !command:
-				e.storeUndoAction(e.fnSwapUp())
+				e.moveParagraph(-1, shift)
 			case !option && command:
 				if shift {
 					e.highlightLineToLeft()
@@ -1088,24 +1604,24 @@ func (e *Editor) Update() error {
 					}
 				}
 				e.fixPosition()
+			case option && command:
+				// Move by line, even when soft wrapped
+				e.moveLineUp(shift)
 			case !option && !command:
-				for x := e.cursor.x - 1; shift && x >= 0; x-- {
-					e.highlight(e.cursor.line, x)
-				}
-				if e.cursor.line.prev != nil {
-					e.cursor.line = e.cursor.line.prev
-					for x := e.cursor.x; shift && x < len(e.cursor.line.values); x++ {
-						e.highlight(e.cursor.line, x)
-					}
+				if e.soft_wrap {
+					e.moveVisualRow(-1, shift)
 				} else {
-					e.cursor.x = 0
+					e.moveLineUp(shift)
 				}
-				e.fixPosition()
 			}
 		case down:
 			switch {
-			case option && !command && !shift:
-				e.storeUndoAction(e.fnSwapDown())
+			case controlCommand && !option && !shift:
+				if e.canEdit(e.cursor.line.next) {
+					e.storeUndoAction(e.fnSwapDown())
+				}
+			case option && !command:
+				e.moveParagraph(1, shift)
 			case !option && command:
 				for e.cursor.line.next != nil {
 					if shift {
@@ -1122,30 +1638,74 @@ func (e *Editor) Update() error {
 				}
 				e.cursor.x = len(e.cursor.line.values) - 1
 				e.fixPosition()
+			case option && command:
+				// Move by line, even when soft wrapped
+				e.moveLineDown(shift)
 			case !option && !command:
-				if e.cursor.line.next != nil {
-					if shift {
-						e.highlightLineToRight()
-					}
-					e.cursor.line = e.cursor.line.next
-					e.fixPosition()
-					if shift {
-						e.highlightLineToLeft()
-					}
+				if e.soft_wrap {
+					e.moveVisualRow(1, shift)
+				} else {
+					e.moveLineDown(shift)
 				}
 			}
 		}
 
+		// Move the other carets alike, for moves by a rune or line.
+		if len(e.carets) > 0 {
+			if command || option || shift || pageup || pagedown {
+				e.ClearCarets()
+			} else {
+				e.moveCarets(right, left, up, down, home, end)
+			}
+		}
+
+		// Step over inline tokens as a single unit.
+		if right {
+			e.skipToken(1, shift)
+		} else {
+			e.skipToken(-1, shift)
+		}
+
 		return nil
 	}
 
-	// Enter
+	// Shift-Enter enters a soft break in prose mode
+	if shift && !(command || option) && isKeyJustPressedOrRepeating(ebiten.KeyEnter) {
+		if e.prose_mode && e.mode == EDIT_MODE && e.canEdit() {
+			e.storeUndoAction(e.fnSoftBreak())
+			e.fixPosition()
+		}
+		return nil
+	}
+
+	// Command-Enter and Command-Shift-Enter
+	if command && !option && isKeyJustPressedOrRepeating(ebiten.KeyEnter) {
+		if e.mode == EDIT_MODE && e.canEdit() {
+			e.resetHighlight()
+			e.storeUndoAction(e.fnInsertLine(shift))
+			e.fixPosition()
+			e.setModified()
+		}
+		return nil
+	}
+
+	// Enter
 	if isOnly && isKeyJustPressedOrRepeating(ebiten.KeyEnter) {
 		if e.mode == SEARCH_MODE {
 			e.searchIndex++
 			e.search()
-		} else {
-			e.storeUndoAction(e.fnHandleRuneSingle('\n'))
+		} else if e.mode == PROMPT_MODE {
+			action, input := e.promptAction, string(e.promptTerm)
+			e.editMode()
+			if action != nil {
+				action(input)
+			}
+		} else if e.canEdit() {
+			// Continue a list item.
+			if e.list_editing && len(e.carets) == 0 && len(e.highlighted) == 0 && e.continueList() {
+				return nil
+			}
+			e.storeUndoAction(e.atCarets(e.fnNewLine))
 			e.fixPosition()
 		}
 		return nil
@@ -1158,28 +1718,75 @@ func (e *Editor) Update() error {
 			e.search()
 			return nil
 		}
-		// Just insert four spaces
-		for i := 0; i < 4; i++ {
-			e.storeUndoAction(e.fnHandleRuneSingle(' '))
+		if e.table_mode {
+			e.NextCell()
+			return nil
+		}
+		if !e.canEdit() {
+			return nil
+		}
+		// Indent the selected lines
+		if e.mode == EDIT_MODE {
+			if undo := e.fnIndentSelection(1); undo != nil {
+				e.storeUndoAction(undo)
+				return nil
+			}
+		}
+		// Nest a list item
+		if e.mode == EDIT_MODE && e.list_editing && e.nestListItem(1) {
+			return nil
+		}
+		// Just insert an indent
+		e.storeUndoAction(e.fnInsertIndent())
+		return nil
+	}
+
+	// Shift-Tab
+	if shift && !(command || option) && isKeyJustPressedOrRepeating(ebiten.KeyTab) {
+		if e.mode == EDIT_MODE && e.table_mode {
+			e.PrevCell()
+		} else if e.mode == EDIT_MODE && e.canEdit() {
+			// Dedent the selected lines, unnest a list item, or remove an indent
+			if undo := e.fnIndentSelection(-1); undo != nil {
+				e.storeUndoAction(undo)
+			} else if !e.list_editing || !e.nestListItem(-1) {
+				e.storeUndoAction(e.fnDedentLine())
+			}
+		}
+		return nil
+	}
+
+	// Command-Backspace
+	if isCommand && isKeyJustPressedOrRepeating(ebiten.KeyBackspace) {
+		if e.mode == EDIT_MODE && e.canEdit(e.joinedLine()) {
+			e.storeUndoAction(e.fnDeleteToLineStart())
+			e.setModified()
 		}
 		return nil
 	}
 
 	// Backspace
 	if isOnly && isKeyJustPressedOrRepeating(ebiten.KeyBackspace) {
-		if e.mode == SEARCH_MODE {
-			if len(e.searchTerm) > 0 {
-				e.searchTerm = e.searchTerm[:len(e.searchTerm)-1]
-			}
-			e.search()
+		if e.mode != EDIT_MODE {
+			e.input.backspace()
+			e.inputChanged()
+			return nil
+		}
+		if !e.canEdit(e.joinedLines()...) {
 			return nil
 		}
 		// Delete all highlighted content
 		if len(e.highlighted) != 0 {
 			e.storeUndoAction(e.fnDeleteHighlighted())
 		} else {
-			// Or..
-			e.storeUndoAction(e.fnDeleteSinglePrevious())
+			e.storeUndoAction(e.atCarets(func() func() bool {
+				if e.inIndentation() {
+					// Or an indent level..
+					return e.fnDeleteIndent()
+				}
+				// Or..
+				return e.fnDeleteSinglePrevious()
+			}))
 		}
 
 		e.resetHighlight()
@@ -1190,9 +1797,99 @@ func (e *Editor) Update() error {
 	return nil
 }
 
+// runCommand runs the command bound to a COMMAND key, such as "s" to save.
+func (e *Editor) runCommand(letter string) {
+	// Chords take the keys which begin or complete them.
+	if e.handleChord(letter) {
+		return
+	}
+
+	// Extensions may handle commands first.
+	if e.extensionCommand(letter) {
+		return
+	}
+
+	if command, ok := e.binding("cmd+" + letter); ok {
+		e.RunCommand(command)
+	}
+}
+
+// moveParagraph moves the cursor to the blank line after the next paragraph,
+// or before the previous paragraph, highlighting the text passed over if
+// shift is held.
+func (e *Editor) moveParagraph(dir int, shift bool) {
+	step := func(line *editorLine) *editorLine {
+		if dir < 0 {
+			return line.prev
+		}
+		return line.next
+	}
+
+	// Skip any blank lines from a blank line, then the paragraph.
+	line := e.cursor.line
+	for isBlankLine(line.values) && step(line) != nil && isBlankLine(step(line).values) {
+		line = step(line)
+	}
+	for step(line) != nil && !isBlankLine(step(line).values) {
+		line = step(line)
+	}
+	if step(line) != nil {
+		line = step(line)
+	}
+
+	x := 0
+	if dir > 0 && line.next == nil {
+		x = len(line.values) - 1
+	}
+
+	if shift {
+		if dir > 0 {
+			e.highlightBetween(e.cursor.line, e.cursor.x, line, x)
+		} else {
+			e.highlightBetween(line, x, e.cursor.line, e.cursor.x)
+		}
+	}
+	e.cursor.line, e.cursor.x = line, x
+	e.fixPosition()
+}
+
+// moveLineUp moves the cursor to the previous line,
+// highlighting the runes passed over if shift is held.
+func (e *Editor) moveLineUp(shift bool) {
+	for x := e.cursor.x - 1; shift && x >= 0; x-- {
+		e.highlight(e.cursor.line, x)
+	}
+	if e.cursor.line.prev != nil {
+		e.cursor.line = e.cursor.line.prev
+		for x := e.cursor.x; shift && x < len(e.cursor.line.values); x++ {
+			e.highlight(e.cursor.line, x)
+		}
+	} else {
+		e.cursor.x = 0
+	}
+	e.fixPosition()
+}
+
+// moveLineDown moves the cursor to the next line,
+// highlighting the runes passed over if shift is held.
+func (e *Editor) moveLineDown(shift bool) {
+	if e.cursor.line.next != nil {
+		if shift {
+			e.highlightLineToRight()
+		}
+		e.cursor.line = e.cursor.line.next
+		e.fixPosition()
+		if shift {
+			e.highlightLineToLeft()
+		}
+	}
+}
+
 func (e *Editor) storeUndoAction(fun func() bool) {
 	if e.mode == EDIT_MODE {
-		e.undoStack = append(e.undoStack, fun)
+		e.undoStack = append(e.undoStack, e.viewUndo(fun))
+		e.selecting = false
+		e.notifyEdit()
 	}
 }
 
@@ -1210,47 +1907,129 @@ func (e *Editor) fnReturnToCursor(line *editorLine, startingX int) func() {
 }
 
 func (e *Editor) fnSwapDown() func() bool {
-	if e.cursor.line.next != nil {
-		tempValues := e.cursor.line.values
-		e.cursor.line.values = e.cursor.line.next.values
-		e.cursor.line.next.values = tempValues
-		e.cursor.line = e.cursor.line.next
-		e.fixPosition()
+	return e.fnSwapLine(1)
+}
 
-		lineNum := e.getLineNumber()
-		curX := e.cursor.x
-		return func() bool {
-			e.MoveCursor(lineNum, curX)
-			tempValues := e.cursor.line.values
-			e.cursor.line.values = e.cursor.line.prev.values
-			e.cursor.line.prev.values = tempValues
-			e.cursor.line = e.cursor.line.prev
-			return true
+func (e *Editor) fnSwapUp() func() bool {
+	return e.fnSwapLine(-1)
+}
+
+// fnSwapLine swaps the cursor line with the next line, or the previous line
+// if dir is negative. The cursor and selection move with the line, and are
+// restored, with the scroll position, by undo.
+func (e *Editor) fnSwapLine(dir int) func() bool {
+	other := e.cursor.line.next
+	if dir < 0 {
+		other = e.cursor.line.prev
+	}
+	if other == nil {
+		return noop
+	}
+
+	before := e.captureState()
+	e.swapLines(e.cursor.line, other)
+	e.cursor.line = other
+	e.fixPosition()
+
+	row := e.getLineNumber()
+	return func() bool {
+		line := e.lineAt(row)
+		if dir < 0 {
+			e.swapLines(line, line.next)
+		} else {
+			e.swapLines(line, line.prev)
 		}
+		e.restoreState(before)
+		return true
 	}
-	return noop
 }
 
-func (e *Editor) fnSwapUp() func() bool {
-	if e.cursor.line.prev != nil {
-		tempValues := e.cursor.line.values
-		e.cursor.line.values = e.cursor.line.prev.values
-		e.cursor.line.prev.values = tempValues
-		e.cursor.line = e.cursor.line.prev
-		e.fixPosition()
-
-		lineNum := e.getLineNumber()
-		curX := e.cursor.x
-		return func() bool {
-			e.MoveCursor(lineNum, curX)
-			tempValues := e.cursor.line.values
-			e.cursor.line.values = e.cursor.line.next.values
-			e.cursor.line.next.values = tempValues
-			e.cursor.line = e.cursor.line.next
-			return true
+// swapLines swaps the text, and the selections, of two lines. Their data,
+// soft breaks and edit times go with the text.
+func (e *Editor) swapLines(a, b *editorLine) {
+	a.values, b.values = b.values, a.values
+	a.data, b.data = b.data, a.data
+	a.soft, b.soft = b.soft, a.soft
+	a.edited, b.edited = b.edited, a.edited
+	e.invalidateHighlight(a)
+	e.invalidateHighlight(b)
+	if a.data != nil {
+		e.dataLines[a] = true
+	}
+	if b.data != nil {
+		e.dataLines[b] = true
+	}
+	e.highlighted[a], e.highlighted[b] = e.highlighted[b], e.highlighted[a]
+	for _, line := range []*editorLine{a, b} {
+		if e.highlighted[line] == nil {
+			delete(e.highlighted, line)
 		}
 	}
-	return noop
+}
+
+// fnReplaceLines replaces count lines, starting at row, with the given
+// lines as a single undoable action. Each line must end with '\n'.
+// Existing lines are reused where possible, and the cursor is left at
+// the start of the first replaced line.
+func (e *Editor) fnReplaceLines(row, count int, lines [][]rune) func() bool {
+	if count < 1 || len(lines) < 1 || !e.canEditRows(row, count) {
+		return noop
+	}
+
+	curRow, curX := e.getLineNumber(), e.cursor.x
+
+	first := e.lineAt(row)
+
+	// Collect the lines being replaced.
+	replaced := make([]*editorLine, 0, count)
+	for line := first; line != nil && len(replaced) < count; line = line.next {
+		replaced = append(replaced, line)
+	}
+	old := make([][]rune, len(replaced))
+	for i, line := range replaced {
+		old[i] = line.values
+	}
+
+	// Reuse the existing lines, unlinking any surplus.
+	reused := len(replaced)
+	if len(lines) < reused {
+		reused = len(lines)
+	}
+	now := time.Now()
+	for i := 0; i < reused; i++ {
+		replaced[i].values = lines[i]
+		replaced[i].edited = now
+	}
+	e.invalidateHighlight(first)
+	last := replaced[reused-1]
+	after := replaced[len(replaced)-1].next
+	last.next = after
+	e.indexRemoved(row+reused, len(replaced)-reused)
+
+	// Link in any additional lines.
+	added := make([]*editorLine, 0, len(lines)-reused)
+	for _, values := range lines[reused:] {
+		line := &editorLine{values: values, prev: last, next: after, edited: now}
+		last.next = line
+		last = line
+		added = append(added, line)
+	}
+	if after != nil {
+		after.prev = last
+	}
+	e.indexInserted(replaced[reused-1], added...)
+
+	e.resetHighlight()
+	e.cursor.line = first
+	e.cursor.x = 0
+	e.fixPosition()
+	e.setModified()
+
+	return func() bool {
+		e.fnReplaceLines(row, len(lines), old)
+		e.MoveCursor(curRow, curX)
+		return true
+	}
 }
 
 func (e *Editor) fnSelectAll() {
@@ -1264,6 +2043,99 @@ func (e *Editor) fnSelectAll() {
 	}
 }
 
+// fnDeleteToLineStart deletes from the cursor back to the start of the line,
+// or the selection if there is one. At the start of a line, the previous
+// new line is deleted instead.
+func (e *Editor) fnDeleteToLineStart() func() bool {
+	if len(e.highlighted) != 0 {
+		undo := e.fnDeleteHighlighted()
+		e.resetHighlight()
+		return undo
+	}
+	if e.cursor.x == 0 {
+		return e.fnDeleteSinglePrevious()
+	}
+
+	deleted := append([]rune{}, e.cursor.line.values[:e.cursor.x]...)
+	e.cursor.line.values = append([]rune{}, e.cursor.line.values[e.cursor.x:]...)
+	e.cursor.x = 0
+
+	lineNum := e.getLineNumber()
+	return func() bool {
+		e.MoveCursor(lineNum, 0)
+		for _, r := range deleted {
+			e.handleRune(r)
+		}
+		return true
+	}
+}
+
+// fnDuplicate inserts a copy of the selection after it, selecting the copy,
+// or else a copy of the cursor line below it, keeping the cursor column.
+// If the lines can't be edited, the cursor and selection are left as they
+// are.
+func (e *Editor) fnDuplicate() func() bool {
+	if !e.canEdit() {
+		return noop
+	}
+	if _, _, last, lastX, ok := e.selectionBounds(); ok {
+		copied := e.getHighlightedRunes()
+		row, x := e.getLineNumberFromLine(last)-1, lastX+1
+		if x >= len(last.values) && last.next != nil {
+			// The selection ends with the '\n' of its line, so the copy
+			// begins the next line.
+			row, x = row+1, 0
+		}
+		e.resetHighlight()
+		e.MoveCursor(row, x)
+		undo := e.fnHandleRuneMulti(copied)
+		e.highlightBetween(e.lineAt(row), x, e.cursor.line, e.cursor.x)
+		return undo
+	}
+
+	row, x := e.getLineNumber(), e.cursor.x
+	values := e.cursor.line.values
+	undo := e.fnReplaceLines(row, 1, [][]rune{append([]rune{}, values...), append([]rune{}, values...)})
+	e.TryMoveCursor(row+1, x)
+	return undo
+}
+
+// fnInsertLine inserts a new line below the cursor line, or above it, without
+// breaking the cursor line. The new line has the same indentation.
+func (e *Editor) fnInsertLine(above bool) func() bool {
+	indent := append([]rune{}, e.cursor.line.values[:indentOf(e.cursor.line.values)]...)
+	row := e.getLineNumber()
+
+	if above {
+		e.cursor.x = 0
+		for _, r := range indent {
+			e.handleRune(r)
+		}
+		e.handleRune('\n')
+		e.cursor.line = e.cursor.line.prev
+	} else {
+		e.cursor.x = len(e.cursor.line.values) - 1
+		e.handleRune('\n')
+		for _, r := range indent {
+			e.handleRune(r)
+		}
+		row++
+	}
+	e.cursor.x = len(indent)
+
+	return func() bool {
+		if above {
+			e.MoveCursor(row+1, 0)
+		} else {
+			e.MoveCursor(row, len(indent))
+		}
+		for i := 0; i <= len(indent); i++ {
+			e.deletePrevious()
+		}
+		return true
+	}
+}
+
 func (e *Editor) fnDeleteSinglePrevious() func() bool {
 	if e.cursor.line == e.start && e.cursor.x == 0 {
 		return noop
@@ -1271,6 +2143,7 @@ func (e *Editor) fnDeleteSinglePrevious() func() bool {
 
 	if e.cursor.x-1 < 0 {
 		e.deletePrevious()
+		e.announce("deleted %s", e.spoken([]rune{'\n'}))
 		lineNum := e.getLineNumber()
 		curX := e.cursor.x
 		return func() bool {
@@ -1281,6 +2154,7 @@ func (e *Editor) fnDeleteSinglePrevious() func() bool {
 	} else {
 		curRune := e.cursor.line.values[e.cursor.x-1]
 		e.deletePrevious()
+		e.announce("deleted %s", e.spoken([]rune{curRune}))
 		lineNum := e.getLineNumber()
 		curX := e.cursor.x
 		return func() bool {
@@ -1301,40 +2175,117 @@ func (e *Editor) deletePrevious() {
 
 	if e.cursor.x == 0 {
 		if e.cursor.line.prev != nil {
+			row := e.getLineNumber()
 			e.cursor.x = len(e.cursor.line.prev.values) - 1
 			e.cursor.line.prev.values = e.cursor.line.prev.values[:len(e.cursor.line.prev.values)-1]
 			e.cursor.line.prev.values = append(e.cursor.line.prev.values, e.cursor.line.values...)
+			e.cursor.line.prev.soft = e.cursor.line.soft
+			e.cursor.line.prev.protected = e.cursor.line.prev.protected || e.cursor.line.protected
 			e.cursor.line.prev.next = e.cursor.line.next
 			if e.cursor.line.next != nil {
 				e.cursor.line.next.prev = e.cursor.line.prev
 			}
 			e.cursor.line = e.cursor.line.prev
+			e.indexRemoved(row, 1)
 		}
 	} else {
 		e.cursor.x--
 		e.cursor.line.values = append(e.cursor.line.values[:e.cursor.x], e.cursor.line.values[e.cursor.x+1:]...)
 	}
+	e.invalidateHighlight(e.cursor.line)
 }
 
 func (e *Editor) getHighlightedRunes() []rune {
 	copyRunes := make([]rune, 0)
-	curLine := e.start
-	for curLine != nil {
+	first, _, last, _, ok := e.selectionBounds()
+	if !ok {
+		return copyRunes
+	}
+	for curLine := first; curLine != last.next; curLine = curLine.next {
 		if highlightedLine, ok := e.highlighted[curLine]; ok {
-			highlightedIndexes := make([]int, 0)
-			for index := range highlightedLine {
-				highlightedIndexes = append(highlightedIndexes, index)
-			}
-			sort.Ints(highlightedIndexes)
-			for _, i := range highlightedIndexes {
-				copyRunes = append(copyRunes, curLine.values[i])
-			}
+			copyRunes = appendHighlighted(copyRunes, curLine.values, highlightedLine)
 		}
-		curLine = curLine.next
 	}
 	return copyRunes
 }
 
+// appendHighlighted appends the highlighted runes of a line, in order.
+// A contiguous range, the usual case, is sliced without sorting.
+func appendHighlighted(dst []rune, values []rune, highlighted map[int]bool) []rune {
+	first, last := len(values), -1
+	for x := range highlighted {
+		if x < first {
+			first = x
+		}
+		if x > last {
+			last = x
+		}
+	}
+	if last-first+1 == len(highlighted) {
+		return append(dst, values[first:last+1]...)
+	}
+
+	indexes := make([]int, 0, len(highlighted))
+	for x := range highlighted {
+		indexes = append(indexes, x)
+	}
+	sort.Ints(indexes)
+	for _, x := range indexes {
+		dst = append(dst, values[x])
+	}
+	return dst
+}
+
+// selectionBounds returns the positions of the first and last highlighted runes.
+// Only the highlighted lines are looked at, rather than all of the lines.
+func (e *Editor) selectionBounds() (first *editorLine, firstX int, last *editorLine, lastX int, ok bool) {
+	firstRow, lastRow := -1, -1
+	for curLine, highlighted := range e.highlighted {
+		row := e.getLineNumberFromLine(curLine) - 1
+		if len(highlighted) == 0 || row >= e.lineCount() {
+			continue
+		}
+		if firstRow < 0 || row < firstRow {
+			first, firstRow = curLine, row
+		}
+		if row > lastRow {
+			last, lastRow = curLine, row
+		}
+	}
+	if first == nil {
+		return nil, 0, nil, 0, false
+	}
+
+	firstX, lastX = len(first.values), -1
+	for x := range e.highlighted[first] {
+		if x < firstX {
+			firstX = x
+		}
+	}
+	for x := range e.highlighted[last] {
+		if x > lastX {
+			lastX = x
+		}
+	}
+	return first, firstX, last, lastX, true
+}
+
+// selectedRows returns the first and last rows containing highlighted runes.
+func (e *Editor) selectedRows() (first, last int, ok bool) {
+	first = -1
+	row := 0
+	for curLine := e.start; curLine != nil; curLine = curLine.next {
+		if len(e.highlighted[curLine]) > 0 {
+			if first < 0 {
+				first = row
+			}
+			last = row
+		}
+		row++
+	}
+	return first, last, first >= 0
+}
+
 func (e *Editor) highlightLine() {
 	for x := range e.cursor.line.values {
 		e.highlight(e.cursor.line, x)
@@ -1353,7 +2304,26 @@ func (e *Editor) highlightLineToLeft() {
 	}
 }
 
+// highlightBetween highlights the runes from one position up to,
+// but not including, a later position.
+func (e *Editor) highlightBetween(line *editorLine, x int, toLine *editorLine, toX int) {
+	for line != nil && (line != toLine || x < toX) {
+		if x >= len(line.values) {
+			line = line.next
+			x = 0
+			continue
+		}
+		e.highlight(line, x)
+		x++
+	}
+}
+
 func (e *Editor) highlight(line *editorLine, x int) {
+	// The new line of the final line is virtual, so can't be selected.
+	if line.next == nil && x == len(line.values)-1 {
+		return
+	}
+
 	if _, ok := e.highlighted[line]; ok {
 		e.highlighted[line][x] = true
 	} else {
@@ -1380,18 +2350,14 @@ func (e *Editor) Cursor() (row int, col int) {
 // If `row` is `-1` then the cursor will be on the final row.
 // If `col` is `-1` then the cursor is moved to the final rune in the row.
 func (e *Editor) MoveCursor(row int, col int) {
-	e.cursor.line = e.start
-	i := 0
-	for i != row {
-		if e.cursor.line.next == nil {
-			if row < 0 {
-				// We're moving to the last line.
-				break
-			}
-			log.Fatalf("attempted illegal move to %v %v", row, col)
-		}
-		e.cursor.line = e.cursor.line.next
-		i++
+	switch {
+	case row < 0:
+		// We're moving to the last line.
+		e.cursor.line = e.lastLine()
+	case row < e.lineCount():
+		e.cursor.line = e.lineAt(row)
+	default:
+		e.logger.Fatalf("attempted illegal move to %v %v", row, col)
 	}
 	if col == -1 {
 		e.cursor.x = len(e.cursor.line.values) - 1
@@ -1408,18 +2374,16 @@ func (e *Editor) getLineNumber() int {
 }
 
 func (e *Editor) getLineNumberFromLine(line *editorLine) int {
-	cur := e.start
-	count := 1
-	for cur != line && cur != e.cursor.line {
-		count++
-		cur = cur.next
+	if row, ok := e.rowOf(line); ok {
+		return row + 1
 	}
-	return count
+	return e.lineCount() + 1
 }
 
-// Return the size in pixels of the editor.
+// Return the size in pixels of the editor, on screen. The internal image
+// is larger by the Scale of the display.
 func (e *Editor) Size() (width, height int) {
-	return e.width, e.height
+	return int(math.Round(float64(e.width) / e.scale)), int(math.Round(float64(e.height) / e.scale))
 }
 
 // copyIntoImageStretched copies the src image into dst,
@@ -1436,18 +2400,104 @@ func copyIntoImageStretched(dst, src *ebiten.Image) {
 
 // Draw the editor onto the screen, scaled to full size.
 func (e *Editor) Draw(screen *ebiten.Image) {
+	if e.drawnSinceUpdate {
+		e.diagnostics.RedrawsSkipped++
+	}
+	e.drawnSinceUpdate = true
+
 	// Scale editor to the screen region we want to draw into.
 	copyIntoImageStretched(screen, e.screen)
 }
 
-// Color a line based on a selection highlighing map.
-func (e *Editor) colorSelected(col, row int, runes []rune, selected map[int]bool, selected_color color.Color) {
+// lineView is the displayed form of an editorLine. Display modes may
+// insert virtual runes, such as padding, which are not part of the text.
+type lineView struct {
+	runes  []rune        // runes to display.
+	index  []int         // index[x] is the display position of the rune at x.
+	colors []color.Color // colors[d] is the color of the rune displayed at d, if not nil.
+	tokens []viewToken   // inline tokens, drawn over their blank columns.
+}
+
+// newLineView returns a view which displays the runes as they are.
+func newLineView(values []rune) *lineView {
+	view := &lineView{
+		runes: values,
+		index: make([]int, len(values)+1),
+	}
+	for x := range view.index {
+		view.index[x] = x
+	}
+	return view
+}
+
+// selection converts a selection of rune positions into display positions.
+// Any virtual runes following a selected rune are also selected.
+func (v *lineView) selection(selected map[int]bool) map[int]bool {
+	display := make(map[int]bool, len(selected))
+	for x := range selected {
+		if x >= len(v.index)-1 {
+			continue
+		}
+		for d := v.index[x]; d < v.index[x+1]; d++ {
+			display[d] = true
+		}
+	}
+	return display
+}
+
+// position returns the rune displayed at, or before, the display position.
+func (v *lineView) position(display int) int {
+	x := 0
+	for x < len(v.index)-2 && v.index[x+1] <= display {
+		x++
+	}
+	return x
+}
+
+// lineView returns the displayed form of the line.
+func (e *Editor) lineView(line *editorLine) *lineView {
+	if e.degraded {
+		return newLineView(line.values)
+	}
+	return e.modeLineView(line).expandTabs(e.tab_width)
+}
+
+// modeLineView returns the form of the line in the current display mode.
+func (e *Editor) modeLineView(line *editorLine) *lineView {
+	var view *lineView
+	switch {
+	case e.ansi_colors && e.read_only:
+		view = ansiLineView(line.values)
+	case e.table_mode:
+		view = tableLineView(line.values, e.tableDelimiter(), e.tableWidths)
+	default:
+		view = newLineView(line.values)
+		if spans := e.tokenSpans(line.values); len(spans) > 0 {
+			view = tokenLineView(line.values, spans)
+		}
+		if e.color_swatches {
+			view = e.withSwatches(view, colorSpans(line.values))
+		}
+	}
+	if e.visible_controls && hasInvisible(view.runes) {
+		view = withControls(view)
+	}
+	return view
+}
+
+// Color a line based on a selection highlighing map,
+// for the runes from col up to end.
+func (e *Editor) colorSelected(col, end, row int, runes []rune, selected map[int]bool, selected_color color.Color) {
 	start := -1
 	fontFace := e.font_info.face
 
+	if end < col {
+		return
+	}
+
 	draw_highlight := func(start, end int) {
 		// End of a selection - highlight it!
-		x_offset := e.width_padding
+		x_offset := e.textLeft()
 		x_offset += font.MeasureString(fontFace, string(runes[col:col+start])).Floor()
 		x_advance := font.MeasureString(fontFace, string(runes[col+start:col+end])).Ceil()
 
@@ -1462,7 +2512,7 @@ func (e *Editor) colorSelected(col, row int, runes []rune, selected map[int]bool
 		)
 	}
 
-	for x, _ := range runes[col:] {
+	for x := range runes[col:end] {
 		_, ok := selected[col+x]
 		if ok {
 			if start < 0 {
@@ -1478,7 +2528,7 @@ func (e *Editor) colorSelected(col, row int, runes []rune, selected map[int]bool
 	}
 
 	if start >= 0 {
-		draw_highlight(start, len(runes)-1)
+		draw_highlight(start, end-col)
 	}
 }
 
@@ -1501,19 +2551,43 @@ func (e *Editor) ContentName() string {
 // SetContentName updates the top bar's content name.
 func (e *Editor) SetContentName(content_name string) {
 	e.content_name = content_name
+	e.tableWidths = nil
 
 	// Update the backing image.
 	e.updateImage()
 }
 
+// CaretBounds returns the area of the editor image where the cursor was last drawn.
+func (e *Editor) CaretBounds() image.Rectangle {
+	return e.caretBounds
+}
+
 // Return the internal image of the editor.
 func (e *Editor) Image() (img *ebiten.Image) {
 	return e.screen
 }
 
-// updateImage updates the internal image.
+// updateImage updates the internal image. A panic while rendering is
+// reported to the error handler, and the image rendered again as plain text.
 func (e *Editor) updateImage() {
+	defer func() {
+		if r := recover(); r != nil {
+			e.reportPanic("render", r)
+			if !e.degraded {
+				e.degraded = true
+				e.updateImage()
+			}
+		}
+	}()
+	e.renderImage()
+}
+
+// renderImage renders the internal image.
+func (e *Editor) renderImage() {
 	screen := e.screen
+	e.diagnostics.FramesRendered++
+	e.drawnSinceUpdate = false
+	e.dirty = false
 
 	// Draw the background
 	if e.background_image != nil {
@@ -1521,50 +2595,85 @@ func (e *Editor) updateImage() {
 	}
 
 	// Collect font metrics.
-	xUnit := e.font_info.xUnit
 	yUnit := e.font_info.yUnit
 	fontAscent := e.font_info.ascent
 	textColor := e.font_color
-	fontFace := e.font_info.face
+	barColor := e.barColor()
 
 	// Handle top bar
 	if e.top_bar {
 		modifiedText := ""
 		if e.modified {
-			modifiedText = "(modified)"
+			modifiedText = e.tr("(modified)")
 		}
 
 		topBar := ">"
 		if e.mode == SEARCH_MODE {
-			topBar = string(append([]rune(topBar), e.searchTerm...))
+			e.drawSearchBar(e.searchPrompt())
 		} else {
-			topBar = fmt.Sprintf("%s %s", e.content_name, modifiedText)
-		}
+			if e.mode == PROMPT_MODE {
+				topBar = e.prompt + string(e.promptTerm)
+				e.drawInput(e.width_padding + font.MeasureString(e.font_info.face, e.prompt).Ceil())
+			} else {
+				topBar = fmt.Sprintf("%s %s", e.content_name, modifiedText)
+			}
 
-		text.Draw(screen, string(topBar), e.font_info.face,
-			e.width_padding, fontAscent,
-			textColor)
-		ebitenutil.DrawLine(e.screen, 0, float64(yUnit+1), float64(e.width), float64(yUnit+1), textColor)
+			text.Draw(screen, string(topBar), e.font_info.face,
+				e.width_padding, fontAscent,
+				barColor)
+			if e.follow && e.mode == EDIT_MODE {
+				e.drawFollowIndicator(screen, barColor)
+			}
+		}
+		ebitenutil.DrawLine(e.screen, 0, float64(yUnit+1), float64(e.width), float64(yUnit+1), barColor)
 	}
 
 	if e.bot_bar {
 		// Handle bottom bar
-		botBar := fmt.Sprintf("(x)cut (c)opy (v)paste (s)ave (q)uit (f)search [%v:%v:%v] ", e.getLineNumber()+1, e.cursor.x+1, e.cursor.line.values[e.cursor.x])
-		text.Draw(screen, string(botBar), e.font_info.face,
+		text.Draw(screen, e.bottomBarText(), e.font_info.face,
 			e.width_padding, e.height-yUnit+fontAscent,
-			textColor)
+			barColor)
+		e.drawStatusIndicator(screen, barColor)
 
-		ebitenutil.DrawLine(screen, 0, float64(e.height-yUnit-2), float64(e.width), float64(e.height-yUnit-2), textColor)
+		ebitenutil.DrawLine(screen, 0, float64(e.height-yUnit-2), float64(e.width), float64(e.height-yUnit-2), barColor)
 	}
 
 	// Handle all lines
 	y := 0
+	e.gutterCols = e.gutterColumns()
+
+	if e.table_mode && !e.degraded && e.tableWidths == nil {
+		e.tableWidths = e.tableColumnWidths()
+	}
+
+	var paragraph map[*editorLine]bool
+	if e.focus_cols > 0 && e.focus_dimming && !e.high_contrast && !e.degraded {
+		paragraph = e.focusParagraph()
+	}
+
+	e.blockScope = nil
+	if e.scope_highlight && !e.degraded {
+		e.blockScope = e.scope()
+	}
+
+	e.matchedBrackets = nil
+	if e.bracket_match && !e.degraded {
+		e.matchedBrackets = e.matchingBrackets()
+	}
 
 	// Find the first visible line.
-	curLine := e.start
-	for line := 0; curLine.next != nil && line != e.firstVisible; line++ {
-		// Skip to first visible
-		curLine = curLine.next
+	// Skip to first visible
+	curLine := e.lineAt(e.firstVisible)
+	lineno := e.getLineNumberFromLine(curLine) - 1
+
+	e.screenRows = e.screenRows[:0]
+	e.caretsOn = e.caretLines()
+	e.viewInfo = ViewInfo{FirstLine: lineno, LastLine: lineno, Columns: e.textColumns()}
+
+	highlight := e.highlighter != nil && !e.degraded
+	var highlightState any
+	if highlight {
+		highlightState = e.highlightState(curLine)
 	}
 
 	for curLine != nil {
@@ -1573,44 +2682,208 @@ func (e *Editor) updateImage() {
 			break
 		}
 
-		// Handle each line (only render the visible section)
-		xStart := 0
-		charactersPerScreen := int(float64(e.width-e.width_padding*2) / float64(xUnit))
-		if e.cursor.line == curLine && e.cursor.x > charactersPerScreen {
-			xStart = ((e.cursor.x / charactersPerScreen) * charactersPerScreen) + 1
+		view := e.lineView(curLine)
+		if highlight {
+			highlightState = e.highlightView(view, curLine, highlightState)
+			if lineno == len(e.highlightStates) {
+				e.highlightStates = append(e.highlightStates, highlightState)
+			}
 		}
 
-		// Render highlighting (if any)
-		if highlight, ok := e.highlighted[curLine]; ok {
-			e.colorSelected(xStart, y, curLine.values, highlight, e.select_color)
+		// Split the line into the rows to render.
+		var rows [][2]int
+		if e.soft_wrap {
+			rows = e.wrapRows(view)
+		} else {
+			// Handle each line (only render the visible section)
+			xStart := 0
+			charactersPerScreen := e.textColumns()
+			if e.cursor.line == curLine {
+				if col := e.columnsOf(view.runes[:view.index[e.cursor.x]]); col > charactersPerScreen {
+					xStart = e.columnEnd(view.runes, 0, (col/charactersPerScreen)*charactersPerScreen)
+				}
+			}
+
+			// Clip the text to the visible columns, where wide runes
+			// cover two.
+			xEnd := e.columnEnd(view.runes, xStart, charactersPerScreen+1)
+			rows = [][2]int{{xStart, xEnd}}
 		}
 
-		// Render search highlighting (if any)
-		if searchHighlight, ok := e.searchHighlights[curLine]; ok {
-			e.colorSelected(xStart, y, curLine.values, searchHighlight, e.search_color)
+		// Render the text dimmed if outside of the focused paragraph.
+		lineColor := textColor
+		if paragraph != nil && !paragraph[curLine] {
+			lineColor = dimColor(textColor)
 		}
 
-		// Render cursor
-		if e.cursor.line == curLine {
-			// We append a '0' to the line to highlight, so that a
-			// cursor at the end of a line actually is a non-zero width.
-			runes := append(curLine.values, '0')
+		for i, row := range rows {
+			if y == e.rows {
+				break
+			}
 
-			cursorHighlight := map[int]bool{e.cursor.x: true}
+			e.viewInfo.LastLine = lineno
+			if curLine == e.cursor.line && !e.soft_wrap {
+				e.viewInfo.HorizontalOffset = row[0]
+			}
 
-			e.colorSelected(xStart, y, runes, cursorHighlight, e.cursor_color)
-		}
+			if i == 0 && e.gutterCols > 0 {
+				e.drawLineNumber(y, lineno+1, e.lineNumberColor())
+			}
 
-		// Render the text.
-		text.Draw(screen, string(curLine.values[xStart:]), fontFace,
-			e.width_padding, e.top_padding+y*yUnit+fontAscent,
-			textColor)
+			last := i == len(rows)-1
+			e.screenRows = append(e.screenRows, screenRow{curLine, view, row[0], row[1], last})
+			e.drawRow(y, curLine, view, row[0], row[1], last, lineColor)
+			e.drawTokens(y, view, row[0], row[1])
+			if e.show_whitespace {
+				e.drawWhitespace(y, curLine, view, row[0], row[1], dimColor(textColor))
+			}
+			if !last {
+				e.drawWrapMarker(y, view, row[0], row[1], dimColor(textColor))
+			} else if curLine.soft && curLine.next != nil && row[1] > row[0] {
+				// Soft breaks are marked as wrapped, after the text.
+				e.drawWrapMarker(y, view, row[0], row[1]-1, dimColor(textColor))
+			} else if !e.final_newline {
+				e.drawNoNewlineMarker(y, curLine, view, row[0], row[1]-1, dimColor(textColor))
+			}
+			y++
+		}
 
 		curLine = curLine.next
-		y++
+		lineno++
+	}
+
+	e.drawScrollbar()
+
+	// Render any extensions, then overlays, above the text.
+	e.drawExtensions()
+	e.drawOverlays()
+	e.drawTutorial()
+}
+
+// drawRow renders the display runes of a line, from start up to end, at row y.
+// The last row of a line also renders the cursor at the end of the line.
+func (e *Editor) drawRow(y int, line *editorLine, view *lineView, start, end int, last bool, textColor color.Color) {
+	// Selections stop short of the final '\n' of the line.
+	selectEnd := end
+	if last {
+		selectEnd = len(view.runes) - 1
+	}
+
+	// Render highlighting (if any)
+	if highlight, ok := e.highlighted[line]; ok {
+		e.colorSelected(start, selectEnd, y, view.runes, view.selection(highlight), e.select_color)
+
+		// Extend a selection including the new line to the right edge.
+		if last && e.select_line_ends && highlight[len(line.values)-1] && selectEnd >= start {
+			x := e.textLeft() + font.MeasureString(e.font_info.face, string(view.runes[start:selectEnd])).Floor()
+			right := e.textLeft() + e.textColumns()*e.font_info.xUnit
+			if right > x {
+				ebitenutil.DrawRect(e.screen,
+					float64(x), float64(e.top_padding+y*e.font_info.yUnit),
+					float64(right-x), float64(e.font_info.yUnit),
+					e.select_color)
+			}
+		}
+	}
+
+	// Render search highlighting (if any)
+	if searchHighlight, ok := e.searchHighlights[line]; ok {
+		e.colorSelected(start, selectEnd, y, view.runes, view.selection(searchHighlight), e.search_color)
+	}
+
+	// Render the scope around the cursor (if any)
+	if e.blockScope != nil {
+		if brackets, ok := e.blockScope.brackets[line]; ok {
+			e.colorSelected(start, selectEnd, y, view.runes, view.selection(brackets), e.scopeColor())
+		}
+		if e.blockScope.lines[line] {
+			e.drawScopeGuide(y, start)
+		}
+	}
+
+	// Render the bracket by the cursor and its match (if any)
+	if brackets, ok := e.matchedBrackets[line]; ok {
+		e.colorSelected(start, selectEnd, y, view.runes, view.selection(brackets), e.bracketColor())
+	}
+
+	// Render the carets added to the cursor (if any)
+	for _, x := range e.caretsOn[line] {
+		caretX := view.index[x]
+		if caretX >= start && (caretX < end || last) {
+			runes := append(view.runes, '0')
+			caretEnd := end
+			if last {
+				caretEnd = len(runes) - 1
+			}
+			e.colorSelected(start, caretEnd, y, runes, map[int]bool{caretX: true}, e.cursorColor())
+		}
+	}
+
+	// Render cursor
+	cursorX := -1
+	if e.cursor.line == line {
+		cursorX = view.index[e.cursor.x]
+	}
+	if cursorX >= start && (cursorX < end || last) {
+		// We append a '0' to the line to highlight, so that a
+		// cursor at the end of a line actually is a non-zero width.
+		runes := append(view.runes, '0')
+
+		cursorHighlight := map[int]bool{cursorX: true}
+
+		cursorEnd := end
+		if last {
+			cursorEnd = len(runes) - 1
+		}
+		e.colorSelected(start, cursorEnd, y, runes, cursorHighlight, e.cursorColor())
+
+		// Remember where the cursor was drawn, for positioning popups.
+		x := e.textLeft() + font.MeasureString(e.font_info.face, string(runes[start:cursorX])).Floor()
+		top := e.top_padding + y*e.font_info.yUnit
+		width := e.runeColumns(runes[cursorX]) * e.font_info.xUnit
+		e.caretBounds = image.Rect(x, top, x+width, top+e.font_info.yUnit)
+	}
+
+	// Render the text, in spans of the same color.
+	for spanStart := start; spanStart < end; {
+		spanColor := textColor
+		spanEnd := end
+		if view.colors != nil {
+			if view.colors[spanStart] != nil {
+				spanColor = view.colors[spanStart]
+			}
+			spanEnd = spanStart + 1
+			for spanEnd < end && view.colors[spanEnd] == view.colors[spanStart] {
+				spanEnd++
+			}
+		}
+
+		x := e.textLeft() + font.MeasureString(e.font_info.face, string(view.runes[start:spanStart])).Floor()
+		text.Draw(e.screen, string(view.runes[spanStart:spanEnd]), e.font_info.face,
+			x, e.top_padding+y*e.font_info.yUnit+e.font_info.ascent,
+			spanColor)
+		spanStart = spanEnd
+	}
+}
+
+// bottomBarText returns the text of the bottom bar: any pending chord,
+// progress or notice, or else the text of WithBottomBarFormatter.
+func (e *Editor) bottomBarText() string {
+	if e.pendingChord != "" {
+		return e.tr("(%s) waiting for the next key of the chord...", e.pendingChord)
+	}
+	if work, ok := e.workText(); ok {
+		return work
+	}
+	if notice, ok := e.currentNotice(); ok {
+		return notice
 	}
+	return e.bot_bar_format(e)
 }
 
+// Layout returns the size of the internal image, which is scaled for the
+// display when WithFont is set.
 func (e *Editor) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	e.updateScale()
 	return e.width, e.height
 }
@@ -1,7 +1,9 @@
 package noter
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -83,8 +85,11 @@ func TestDeleteLine(t *testing.T) {
 func TestHighlightLineAndGetHighlightedRunes(t *testing.T) {
 	line1 := &editorLine{values: []rune{'a', '\n'}}
 	line2 := &editorLine{values: []rune{'b', '\n'}}
+	line3 := &editorLine{values: []rune{'\n'}}
 	line1.next = line2
 	line2.prev = line1
+	line2.next = line3
+	line3.prev = line2
 	editor := &Editor{
 		start: line1,
 		cursor: &editorCursor{
@@ -182,3 +187,299 @@ func TestMoveCursorScroll(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchNoMatches(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("cat\nconcatenate\n"))
+
+	editor.searchMode()
+	editor.searchTerm = []rune("cat")
+	editor.search()
+	if !editor.HasMatches() || editor.MatchCount() != 2 {
+		t.Fatalf("Expected 2 matches, got: %v", editor.MatchCount())
+	}
+
+	editor.searchTerm = []rune("dog")
+	editor.search()
+	if editor.HasMatches() {
+		t.Fatalf("Expected no matches, got: %v", editor.MatchCount())
+	}
+}
+
+func TestFinalEmptyLine(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("abc\n"))
+
+	// The cursor can be placed after the final new line.
+	editor.MoveCursor(1, 0)
+	if row, col := editor.Cursor(); row != 1 || col != 0 {
+		t.Fatalf("Expected the cursor on the final empty line, got: %v:%v", row, col)
+	}
+
+	editor.storeUndoAction(editor.fnTypeRune('x'))
+	if got := string(editor.ReadText()); got != "abc\nx\n" {
+		t.Fatalf("Incorrect text typed on the final line, got: %q", got)
+	}
+
+	// Selecting all doesn't include the virtual new line.
+	editor.fnSelectAll()
+	if got := string(editor.getHighlightedRunes()); got != "abc\nx" {
+		t.Fatalf("Incorrect selection of all text, got: %q", got)
+	}
+
+	editor.WriteText(nil)
+	if got := string(editor.ReadText()); got != "" {
+		t.Fatalf("Expected empty text, got: %q", got)
+	}
+}
+
+func TestDeleteToLineStart(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one\ntwo three\n"))
+
+	editor.MoveCursor(1, 4)
+	editor.storeUndoAction(editor.fnDeleteToLineStart())
+	if got := string(editor.ReadText()); got != "one\nthree\n" {
+		t.Fatalf("Incorrect delete to line start, got: %q", got)
+	}
+
+	// At the start of a line, the lines are joined.
+	editor.storeUndoAction(editor.fnDeleteToLineStart())
+	if got := string(editor.ReadText()); got != "onethree\n" {
+		t.Fatalf("Incorrect delete at line start, got: %q", got)
+	}
+
+	for i := len(editor.undoStack) - 1; i >= 0; i-- {
+		editor.undoStack[i]()
+	}
+	if got := string(editor.ReadText()); got != "one\ntwo three\n" {
+		t.Fatalf("Incorrect undo of delete to line start, got: %q", got)
+	}
+}
+
+func TestInsertLine(t *testing.T) {
+	table := [](struct {
+		above bool
+		want  string
+		row   int
+	}){
+		{false, "a\n  bc\n  \nd\n", 2},
+		{true, "a\n  \n  bc\nd\n", 1},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte("a\n  bc\nd\n"))
+		editor.MoveCursor(1, 3)
+
+		editor.storeUndoAction(editor.fnInsertLine(entry.above))
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect line insertion, expected %q, got %q", entry.want, got)
+		}
+		if row, col := editor.Cursor(); row != entry.row || col != 2 {
+			t.Fatalf("Expected cursor on the new line at %v:2, got %v:%v", entry.row, row, col)
+		}
+
+		editor.undoStack[0]()
+		if got := string(editor.ReadText()); got != "a\n  bc\nd\n" {
+			t.Fatalf("Incorrect undo of line insertion, got %q", got)
+		}
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("ab\ncd\n"))
+	editor.MoveCursor(0, 1)
+
+	editor.RunCommand("Duplicate")
+	if got := string(editor.ReadText()); got != "ab\nab\ncd\n" {
+		t.Fatalf("Incorrect line duplication, got: %q", got)
+	}
+	if row, col := editor.Cursor(); row != 1 || col != 1 {
+		t.Fatalf("Expected cursor on the copy at 1:1, got: %v:%v", row, col)
+	}
+
+	editor.highlightBetween(editor.lineAt(1), 1, editor.lineAt(2), 1)
+	editor.RunCommand("Duplicate")
+	if got := string(editor.ReadText()); got != "ab\nab\ncb\ncd\n" {
+		t.Fatalf("Incorrect selection duplication, got: %q", got)
+	}
+	if got := string(editor.getHighlightedRunes()); got != "b\nc" {
+		t.Fatalf("Expected the copy to be selected, got: %q", got)
+	}
+
+	editor.RunCommand("Undo")
+	editor.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != "ab\ncd\n" {
+		t.Fatalf("Incorrect undo of duplication, got: %q", got)
+	}
+
+	// A selection of whole lines is copied below them.
+	editor.highlightBetween(editor.lineAt(0), 0, editor.lineAt(1), 0)
+	editor.RunCommand("Duplicate")
+	if got := string(editor.ReadText()); got != "ab\nab\ncd\n" {
+		t.Fatalf("Incorrect duplication of a line selection, got %q", got)
+	}
+	if got := string(editor.getHighlightedRunes()); got != "ab\n" {
+		t.Fatalf("Expected the copy to be selected, got %q", got)
+	}
+	if row, col := editor.Cursor(); row != 2 || col != 0 {
+		t.Fatalf("Expected the cursor after the copy at 2:0, got %v:%v", row, col)
+	}
+
+	// Nothing moves when the content is read-only.
+	editor.SetReadOnly(true)
+	editor.MoveCursor(0, 1)
+	editor.storeUndoAction(editor.fnDuplicate())
+	if got := string(editor.ReadText()); got != "ab\nab\ncd\n" {
+		t.Fatalf("Expected no duplication when read-only, got %q", got)
+	}
+	if row, col := editor.Cursor(); row != 0 || col != 1 {
+		t.Fatalf("Expected the cursor to stay at 0:1, got %v:%v", row, col)
+	}
+}
+
+func TestMoveParagraph(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a\nb\n\nc\nd\n\n\ne\n"))
+	editor.MoveCursor(1, 1)
+
+	table := [](struct {
+		dir int
+		row int
+	}){
+		{1, 2},
+		{1, 5},
+		{1, 8},
+		{-1, 6},
+		{-1, 2},
+		{-1, 0},
+	}
+
+	for _, entry := range table {
+		editor.moveParagraph(entry.dir, false)
+		if row, col := editor.Cursor(); row != entry.row || col != 0 {
+			t.Fatalf("Incorrect paragraph move %v, expected %v:0, got %v:%v", entry.dir, entry.row, row, col)
+		}
+	}
+
+	editor.moveParagraph(1, true)
+	if got := string(editor.getHighlightedRunes()); got != "a\nb\n" {
+		t.Fatalf("Incorrect paragraph selection, got %q", got)
+	}
+}
+
+func TestTypeOverSelectionUndo(t *testing.T) {
+	table := [](struct {
+		name string
+		edit func(e *Editor) func() bool
+		want string
+	}){
+		{"type", func(e *Editor) func() bool { return e.fnTypeRune('X') }, "oXee\n"},
+		{"paste", func(e *Editor) func() bool { return e.fnHandleRuneMulti([]rune("YZ")) }, "oYZee\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte("one\nthree\n"))
+		editor.highlightBetween(editor.start, 1, editor.start.next, 3)
+
+		editor.storeUndoAction(entry.edit(editor))
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect %s over selection, expected %q, got %q", entry.name, entry.want, got)
+		}
+		if len(editor.undoStack) != 1 {
+			t.Fatalf("Expected a single undo step for %s, got %v", entry.name, len(editor.undoStack))
+		}
+
+		editor.resetHighlight()
+		editor.undoStack[0]()
+		if got := string(editor.ReadText()); got != "one\nthree\n" {
+			t.Fatalf("Incorrect undo of %s, got %q", entry.name, got)
+		}
+		if got := string(editor.getHighlightedRunes()); got != "ne\nthr" {
+			t.Fatalf("Expected undo of %s to restore the selection, got %q", entry.name, got)
+		}
+	}
+}
+
+func TestAppendHighlighted(t *testing.T) {
+	values := []rune("abcdef\n")
+	table := [](struct {
+		highlighted map[int]bool
+		want        string
+	}){
+		{map[int]bool{1: true, 2: true, 3: true}, "bcd"},
+		{map[int]bool{5: true, 0: true, 3: true}, "adf"},
+		{map[int]bool{}, ""},
+	}
+
+	for _, entry := range table {
+		if got := string(appendHighlighted(nil, values, entry.highlighted)); got != entry.want {
+			t.Fatalf("Incorrect highlighted runes, expected %q, got %q", entry.want, got)
+		}
+	}
+}
+
+func benchmarkEditor(lines int) *Editor {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog.\n", lines)
+	editor := NewEditor()
+	editor.WriteText([]byte(text))
+	return editor
+}
+
+func BenchmarkCopySelectAll(b *testing.B) {
+	editor := benchmarkEditor(10000)
+	editor.fnSelectAll()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		editor.getHighlightedRunes()
+	}
+}
+
+func BenchmarkCopyLine(b *testing.B) {
+	editor := benchmarkEditor(10000)
+	editor.MoveCursor(5000, 0)
+	editor.highlightLine()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		editor.getHighlightedRunes()
+	}
+}
+
+func BenchmarkHandleRune(b *testing.B) {
+	editor := NewEditor()
+	editor.WriteText([]byte(strings.Repeat("lorem ipsum dolor sit amet ", 40) + "\n"))
+	editor.MoveCursor(0, 0)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		editor.handleRune('a')
+		editor.deletePrevious()
+	}
+}
+
+func TestBottomBarFormatter(t *testing.T) {
+	editor := NewEditor(WithBottomBarFormatter(func(e *Editor) string {
+		row, col := e.Cursor()
+		return fmt.Sprintf("%s %d:%d", e.ModeName(e.Mode()), row+1, col+1)
+	}))
+	editor.WriteText([]byte("abc\ndef\n"))
+	editor.MoveCursor(1, 2)
+
+	if got := editor.bottomBarText(); got != "edit 2:3" {
+		t.Fatalf("Incorrect bottom bar, expected %q, got: %q", "edit 2:3", got)
+	}
+
+	editor.Notify("saved")
+	if got := editor.bottomBarText(); got != "saved" {
+		t.Fatalf("Expected the notice in the bottom bar, got: %q", got)
+	}
+
+	if got := NewEditor().bottomBarText(); !strings.HasPrefix(got, "(x)cut") {
+		t.Fatalf("Expected the help in the default bottom bar, got: %q", got)
+	}
+}
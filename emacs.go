@@ -0,0 +1,123 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// KeymapEmacs adds the navigation chords of Emacs to the default bindings:
+//
+//	| Keystroke | Action |
+//	| ---       | ---    |
+//	| CONTROL-A | Move to the start of the line. |
+//	| CONTROL-E | Move to the end of the line. |
+//	| CONTROL-K | Kill to the end of the line, or the new line at its end. |
+//	| CONTROL-Y | Yank the killed text. |
+//	| ALT-F     | Move forward to the end of the word. |
+//	| ALT-B     | Move back to the start of the word. |
+//
+// Consecutive kills are collected together, and the killed text is kept
+// in the clipboard.
+var KeymapEmacs = &Keymap{
+	name: "emacs",
+	bindings: map[string]string{
+		"ctrl+a": "LineStart",
+		"ctrl+e": "LineEnd",
+		"ctrl+k": "KillLine",
+		"ctrl+y": "Yank",
+		"alt+f":  "ForwardWord",
+		"alt+b":  "MoveWordLeft",
+	},
+	attach: attachEmacs,
+}
+
+// emacsState is the state of the Emacs emulation of an editor.
+type emacsState struct {
+	killText string   // the text in the clipboard from the previous kill.
+	killPos  Position // the cursor after the previous kill.
+}
+
+// attachEmacs registers the commands of Emacs.
+func attachEmacs(e *Editor) {
+	emacs := &emacsState{}
+	e.RegisterCommand("KillLine", emacs.kill)
+	e.RegisterCommand("Yank", emacs.yank)
+	e.RegisterCommand("ForwardWord", forwardWord)
+}
+
+// forwardWord moves forward to the end of the word, or the next word.
+func forwardWord(e *Editor) {
+	e.editMode()
+	e.resetHighlight()
+	for !isWordRune(cursorRune(e)) && stepCursor(e, 1) {
+	}
+	for isWordRune(cursorRune(e)) && stepCursor(e, 1) {
+	}
+	e.fixPosition()
+}
+
+// kill cuts to the end of the line, or the new line if the cursor is at
+// the end, adding to the clipboard if the previous command was a kill.
+func (emacs *emacsState) kill(e *Editor) {
+	e.editMode()
+	if !e.canEdit() {
+		return
+	}
+	line, x := e.cursor.line, e.cursor.x
+	e.resetHighlight()
+	if x < len(line.values)-1 {
+		e.highlightBetween(line, x, line, len(line.values)-1)
+	} else if line.next != nil {
+		e.highlightBetween(line, x, line.next, 0)
+	}
+	if len(e.highlighted) == 0 {
+		return
+	}
+
+	killed := string(e.getHighlightedRunes())
+	row, col := e.Cursor()
+	if (Position{row, col}) == emacs.killPos && string(e.clipboard.ReadText()) == emacs.killText {
+		killed = emacs.killText + killed
+	}
+	e.clipboard.WriteText([]byte(killed))
+
+	e.storeUndoAction(e.fnDeleteHighlighted())
+	e.resetHighlight()
+	e.setModified()
+
+	row, col = e.Cursor()
+	emacs.killText, emacs.killPos = killed, Position{row, col}
+}
+
+// yank pastes the clipboard at the cursor.
+func (emacs *emacsState) yank(e *Editor) {
+	e.editMode()
+	if !e.canEdit() {
+		return
+	}
+	rs := e.clipboardRunes()
+	if len(rs) == 0 {
+		return
+	}
+	e.resetHighlight()
+	e.storeUndoAction(e.fnHandleRuneMulti(rs))
+	e.setModified()
+	emacs.killText = ""
+}
@@ -0,0 +1,52 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestEmacsKill(t *testing.T) {
+	editor := NewEditor(WithKeymap(KeymapEmacs))
+	editor.WriteText([]byte("one two\nthree\n"))
+	editor.MoveCursor(0, 4)
+
+	// Kill the rest of the line, then its new line, then the next line.
+	for i := 0; i < 3; i++ {
+		editor.RunCommand(editor.Bindings()["ctrl+k"])
+	}
+	if got := string(editor.ReadText()); got != "one \n" {
+		t.Fatalf("Incorrect text after kills, got %q", got)
+	}
+	if got := string(editor.clipboard.ReadText()); got != "two\nthree" {
+		t.Fatalf("Expected the kills collected together, got %q", got)
+	}
+
+	editor.RunCommand(editor.Bindings()["ctrl+a"])
+	editor.RunCommand(editor.Bindings()["ctrl+y"])
+	if got := string(editor.ReadText()); got != "two\nthreeone \n" {
+		t.Fatalf("Incorrect text after yank, got %q", got)
+	}
+}
+
+func TestEmacsMove(t *testing.T) {
+	editor := NewEditor(WithKeymap(KeymapEmacs))
+	editor.WriteText([]byte("one two, three\n"))
+	editor.MoveCursor(0, 0)
+
+	table := [](struct {
+		keystroke string
+		col       int
+	}){
+		{"alt+f", 3},
+		{"alt+f", 7},
+		{"alt+b", 4},
+		{"ctrl+e", 14},
+		{"ctrl+a", 0},
+	}
+
+	for _, entry := range table {
+		editor.RunCommand(editor.Bindings()[entry.keystroke])
+		if _, col := editor.Cursor(); col != entry.col {
+			t.Fatalf("Incorrect move by %v, expected %v, got %v", entry.keystroke, entry.col, col)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encodings are the encodings of content, by name. UTF-8 needs no
+// decoding, so it has none. Byte order marks are handled separately, as
+// some content has them and some doesn't.
+var encodings = map[string]encoding.Encoding{
+	ENCODING_UTF8:    nil,
+	ENCODING_UTF16LE: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	ENCODING_UTF16BE: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	ENCODING_LATIN1:  charmap.ISO8859_1,
+}
+
+// byteOrderMarks are the byte order marks of the encodings which have one.
+var byteOrderMarks = map[string][]byte{
+	ENCODING_UTF8:    {0xef, 0xbb, 0xbf},
+	ENCODING_UTF16LE: {0xff, 0xfe},
+	ENCODING_UTF16BE: {0xfe, 0xff},
+}
+
+// WithEncoding sets the encoding of the content, one of ENCODING_UTF8,
+// ENCODING_UTF16LE, ENCODING_UTF16BE or ENCODING_LATIN1, rather than
+// detecting it when the content is written. An unknown name is ignored.
+// The default is to detect it.
+func WithEncoding(name string) EditorOption {
+	return func(e *Editor) {
+		if _, ok := encodings[name]; ok || name == "" {
+			e.force_encoding = name
+		}
+	}
+}
+
+// WithKeepBOM sets whether content which started with a byte order mark,
+// such as the "\xef\xbb\xbf" of UTF-8, keeps it when read with ReadText
+// and saved. The mark is never part of the text itself. The default is
+// enabled.
+func WithKeepBOM(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.keep_bom = enabled
+	}
+}
+
+// BOM returns true if the content started with a byte order mark when it
+// was written.
+func (e *Editor) BOM() bool {
+	return e.bom
+}
+
+// detectEncoding returns the encoding of the text. UTF-16 is detected by
+// its byte order mark, or by the zero bytes of mostly ASCII text. Text
+// which is neither UTF-16 nor UTF-8 is taken to be Latin-1.
+func detectEncoding(text []byte) string {
+	switch {
+	case bytes.HasPrefix(text, byteOrderMarks[ENCODING_UTF8]):
+		return ENCODING_UTF8
+	case bytes.HasPrefix(text, byteOrderMarks[ENCODING_UTF16LE]):
+		return ENCODING_UTF16LE
+	case bytes.HasPrefix(text, byteOrderMarks[ENCODING_UTF16BE]):
+		return ENCODING_UTF16BE
+	}
+
+	sample := text
+	if len(sample) > 1024 {
+		sample = sample[:1024]
+	}
+	if units := len(sample) / 2; units > 0 && len(sample)%2 == 0 {
+		even, odd := 0, 0
+		for i := 0; i < len(sample); i += 2 {
+			if sample[i] == 0 {
+				even++
+			}
+			if sample[i+1] == 0 {
+				odd++
+			}
+		}
+		switch {
+		case odd*2 > units && even*10 < units:
+			return ENCODING_UTF16LE
+		case even*2 > units && odd*10 < units:
+			return ENCODING_UTF16BE
+		}
+	}
+
+	if utf8.Valid(text) {
+		return ENCODING_UTF8
+	}
+	return ENCODING_LATIN1
+}
+
+// decodeContent returns the text of a content as UTF-8, as WriteText
+// decodes it: in the forced encoding, if not "", or else in the encoding
+// detected. It returns the encoding, and whether there was a byte order
+// mark.
+func decodeContent(text []byte, force string) (decoded []byte, name string, bom bool) {
+	name = force
+	if name == "" {
+		name = detectEncoding(text)
+	}
+	decoded, bom = decodeText(text, name)
+	return decoded, name, bom
+}
+
+// decodeText returns the text, in the named encoding, as UTF-8 without
+// any byte order mark, and whether there was one. Text which can't be
+// decoded is returned as it is.
+func decodeText(text []byte, name string) (decoded []byte, bom bool) {
+	if mark := byteOrderMarks[name]; len(mark) > 0 && bytes.HasPrefix(text, mark) {
+		text, bom = text[len(mark):], true
+	}
+	enc := encodings[name]
+	if enc == nil {
+		return text, bom
+	}
+	decoded, err := enc.NewDecoder().Bytes(text)
+	if err != nil {
+		return text, bom
+	}
+	return decoded, bom
+}
+
+// encodeText returns the UTF-8 text in the named encoding, starting with
+// its byte order mark if bom is set. Runes which the encoding lacks are
+// replaced, such as by '\x1a' for Latin-1.
+func encodeText(text []byte, name string, bom bool) []byte {
+	if enc := encodings[name]; enc != nil {
+		if encoded, err := encoding.ReplaceUnsupported(enc.NewEncoder()).Bytes(text); err == nil {
+			text = encoded
+		}
+	}
+	if mark := byteOrderMarks[name]; bom && len(mark) > 0 {
+		text = append(append([]byte{}, mark...), text...)
+	}
+	return text
+}
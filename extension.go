@@ -0,0 +1,104 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Extension is an opt-in module which adds a feature to the editor, such as
+// spellchecking, without the editor knowing about it.
+type Extension interface {
+	// Attach is called once, when the extension is added to the editor.
+	Attach(e *Editor)
+
+	// HandleCommand is called when a COMMAND key is pressed, such as "k",
+	// before the editor handles it. It returns true if it handled the key.
+	HandleCommand(e *Editor, command string) bool
+
+	// OnEdit is called after each edit of the content, and after text is
+	// appended with AppendText. It isn't called for the edits of a tutorial
+	// (see PlayTutorial), which leave the content as it was.
+	OnEdit(e *Editor)
+
+	// OnDraw renders onto the editor's image, above the text but
+	// below any overlays.
+	OnDraw(e *Editor, screen *ebiten.Image)
+}
+
+// WithExtension adds an extension, which is attached once the editor
+// has been created.
+func WithExtension(ext Extension) EditorOption {
+	return func(e *Editor) {
+		e.extensions = append(e.extensions, ext)
+	}
+}
+
+// AddExtension adds and attaches an extension to the editor.
+func (e *Editor) AddExtension(ext Extension) {
+	e.extensions = append(e.extensions, ext)
+	ext.Attach(e)
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// Extensions returns the extensions added to the editor.
+func (e *Editor) Extensions() []Extension {
+	return e.extensions
+}
+
+// extensionCommand offers a command to the extensions, in the order they
+// were added. It returns true if one handled it.
+func (e *Editor) extensionCommand(command string) bool {
+	for _, ext := range e.extensions {
+		if ext.HandleCommand(e, command) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyEdit tells the extensions of an edit, after reporting the data of
+// any removed lines and finding the matches of any find all panel again.
+// The edits of a tutorial are of its own document, so aren't told.
+func (e *Editor) notifyEdit() {
+	if e.tutorial != nil {
+		return
+	}
+	e.checkLineData()
+	e.syncViews()
+	if e.findPanel != nil {
+		e.findPanel.refresh(e)
+	}
+	for _, ext := range e.extensions {
+		ext.OnEdit(e)
+	}
+}
+
+// drawExtensions lets the extensions render onto the image.
+func (e *Editor) drawExtensions() {
+	for _, ext := range e.extensions {
+		ext.OnDraw(e, e.screen)
+	}
+}
@@ -0,0 +1,48 @@
+package noter
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type testExtension struct {
+	attached int
+	edits    int
+	draws    int
+	commands []string
+}
+
+func (x *testExtension) Attach(e *Editor) { x.attached++ }
+
+func (x *testExtension) HandleCommand(e *Editor, command string) bool {
+	x.commands = append(x.commands, command)
+	return command == "k"
+}
+
+func (x *testExtension) OnEdit(e *Editor) { x.edits++ }
+
+func (x *testExtension) OnDraw(e *Editor, screen *ebiten.Image) { x.draws++ }
+
+func TestExtension(t *testing.T) {
+	ext := &testExtension{}
+	editor := NewEditor(WithExtension(ext))
+	if ext.attached != 1 {
+		t.Fatalf("Expected the extension to be attached once, got: %v", ext.attached)
+	}
+
+	editor.storeUndoAction(editor.fnTypeRune('a'))
+	if ext.edits != 1 {
+		t.Fatalf("Expected one edit, got: %v", ext.edits)
+	}
+
+	draws := ext.draws
+	editor.updateImage()
+	if ext.draws != draws+1 {
+		t.Fatalf("Expected the extension to draw")
+	}
+
+	if !editor.extensionCommand("k") || editor.extensionCommand("z") {
+		t.Fatalf("Expected only the k command to be handled, got: %v", ext.commands)
+	}
+}
@@ -0,0 +1,347 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"image"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// FIND_PANEL_ROWS is the number of results the find all panel shows
+// before scrolling.
+const FIND_PANEL_ROWS = 8
+
+// findResult is a match of a find all, from the rune x up to end.
+type findResult struct {
+	line   *editorLine
+	x, end int
+}
+
+// lineMatcher returns the start and end of each match in the runes of a
+// line, without its '\n'. It's safe to call from any goroutine.
+type lineMatcher func(values []rune) [][2]int
+
+// matcher returns the matcher of the term, with the options of the search:
+// regular expressions, case sensitivity and whole words. Matches don't
+// overlap. It returns nil for an empty term or an invalid pattern.
+func (e *Editor) matcher(term []rune) lineMatcher {
+	if len(term) == 0 {
+		return nil
+	}
+	term = append([]rune{}, term...)
+	matchCase, word := e.search_case, e.search_word
+
+	if e.regex_search {
+		pattern := string(term)
+		if !matchCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil
+		}
+		return func(values []rune) (spans [][2]int) {
+			s := string(values)
+			runeAt := runeOffsets(s)
+			for _, m := range re.FindAllStringIndex(s, -1) {
+				if m[0] < m[1] && (!word || isWholeWord(values, runeAt[m[0]], runeAt[m[1]])) {
+					spans = append(spans, [2]int{runeAt[m[0]], runeAt[m[1]]})
+				}
+			}
+			return spans
+		}
+	}
+
+	return func(values []rune) (spans [][2]int) {
+	next:
+		for x := 0; x+len(term) <= len(values); x++ {
+			for i, r := range term {
+				if r != values[x+i] && (matchCase || unicode.ToLower(r) != unicode.ToLower(values[x+i])) {
+					continue next
+				}
+			}
+			if word && !isWholeWord(values, x, x+len(term)) {
+				continue
+			}
+			spans = append(spans, [2]int{x, x + len(term)})
+			x += len(term) - 1
+		}
+		return spans
+	}
+}
+
+// findMatches returns the matches of the term in the content, in order,
+// with the options of the search.
+func (e *Editor) findMatches(term []rune) (results []findResult) {
+	match := e.matcher(term)
+	if match == nil {
+		return nil
+	}
+	for line := e.start; line != nil; line = line.next {
+		for _, span := range match(line.values[:len(line.values)-1]) {
+			results = append(results, findResult{line, span[0], span[1]})
+		}
+	}
+	return results
+}
+
+// findPanel is an overlay listing the matches of a find all, docked above
+// the bottom bar. The results follow edits to the content. While the panel
+// has focus, Up and Down select a result, and Enter moves the cursor to it,
+// leaving the panel open and giving the focus back to the text. A click
+// on the panel focuses it, and moves to the result clicked. Other input is
+// left for the editor, and a key the panel doesn't use takes its focus.
+type findPanel struct {
+	term     []rune
+	results  []findResult
+	selected int
+	first    int
+	focused  bool
+
+	// workspace is the search whose matches are listed, if the panel
+	// lists the matches of a workspace search rather than the content.
+	workspace *workspaceSearch
+}
+
+// ShowFindAll lists the matches of the term in a panel, with the options
+// of the search. It replaces any panel already shown.
+func (e *Editor) ShowFindAll(term string) {
+	e.showFindPanel(&findPanel{term: []rune(term)})
+}
+
+// showFindPanel shows the panel, replacing any panel already shown.
+func (e *Editor) showFindPanel(p *findPanel) {
+	if e.findPanel != nil {
+		e.RemoveOverlay(e.findPanel)
+		e.findPanel.Dismiss(e)
+	}
+	e.findPanel = p
+	p.focused = true
+	p.refresh(e)
+	e.PushOverlay(p)
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// refresh finds the matches again, keeping the selection in range.
+func (p *findPanel) refresh(e *Editor) {
+	if p.workspace == nil {
+		p.results = e.findMatches(p.term)
+	}
+	p.move(0)
+}
+
+// count returns the number of results.
+func (p *findPanel) count() int {
+	if p.workspace != nil {
+		return len(p.workspace.matches)
+	}
+	return len(p.results)
+}
+
+// rows returns the number of result rows shown, below the heading row.
+func (p *findPanel) rows() int {
+	if p.count() < FIND_PANEL_ROWS {
+		return p.count()
+	}
+	return FIND_PANEL_ROWS
+}
+
+// move changes the selected result, scrolling to keep it visible.
+func (p *findPanel) move(delta int) {
+	p.selected += delta
+	if p.selected >= p.count() {
+		p.selected = p.count() - 1
+	}
+	if p.selected < 0 {
+		p.selected = 0
+	}
+
+	switch {
+	case p.selected < p.first:
+		p.first = p.selected
+	case p.selected >= p.first+FIND_PANEL_ROWS:
+		p.first = p.selected - FIND_PANEL_ROWS + 1
+	}
+}
+
+// jump moves the cursor to the selected result, highlighting it.
+func (p *findPanel) jump(e *Editor) {
+	if p.selected >= p.count() {
+		return
+	}
+	if p.workspace != nil {
+		p.workspace.open(e, p.workspace.matches[p.selected])
+		return
+	}
+
+	result := p.results[p.selected]
+	row := e.getLineNumberFromLine(result.line) - 1
+	if row >= e.lineCount() {
+		// The line has been removed since the results were found.
+		p.refresh(e)
+		return
+	}
+	e.showMatch(row, result.x, result.end)
+}
+
+// showMatch moves the cursor to the match on the row, from the rune x up
+// to end, highlighting it and centering it in the view.
+func (e *Editor) showMatch(row, x, end int) {
+	e.editMode()
+	e.resetHighlight()
+	pos, _ := e.TryMoveCursor(row, x)
+	for ; x < end && x < len(e.cursor.line.values)-1; x++ {
+		e.highlight(e.cursor.line, x)
+	}
+	e.CenterOn(pos.Row)
+}
+
+// heading returns the text above the results.
+func (p *findPanel) heading(e *Editor) string {
+	if p.workspace != nil {
+		return p.workspace.heading(e, string(p.term))
+	}
+	return e.tr("%d matches of %q", len(p.results), string(p.term))
+}
+
+// item returns the text of a result: its line number and the line.
+func (p *findPanel) item(e *Editor, index int) string {
+	var item string
+	if p.workspace != nil {
+		m := p.workspace.matches[index]
+		item = fmt.Sprintf("%s:%d: %s", m.name, m.row+1, m.line)
+	} else {
+		result := p.results[index]
+		preview := strings.TrimSpace(string(result.line.values))
+		item = fmt.Sprintf("%d: %s", e.getLineNumberFromLine(result.line), preview)
+	}
+	rs := []rune(item)
+	return string(rs[:e.columnEnd(rs, 0, e.textColumns())])
+}
+
+func (p *findPanel) Bounds(e *Editor) image.Rectangle {
+	height := (p.rows()+1)*e.font_info.yUnit + e.width_padding*2
+	bottom := e.height - e.bot_padding
+	top := bottom - height
+	if top < e.top_padding {
+		top = e.top_padding
+	}
+	return image.Rect(0, top, e.width, bottom)
+}
+
+func (p *findPanel) Update(e *Editor) bool {
+	switch {
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		// Clicks outside of the panel have already dismissed it.
+		p.focused = true
+		_, y := ebiten.CursorPosition()
+		row := (y - p.Bounds(e).Min.Y - e.width_padding) / e.font_info.yUnit
+		if row < 1 {
+			return true
+		}
+		p.move(p.first + row - 1 - p.selected)
+		p.jump(e)
+	case !p.focused:
+		return false
+	case isKeyJustPressedOrRepeating(ebiten.KeyArrowUp):
+		p.move(-1)
+	case isKeyJustPressedOrRepeating(ebiten.KeyArrowDown):
+		p.move(1)
+	case isKeyJustPressedOrRepeating(ebiten.KeyPageUp):
+		p.move(-FIND_PANEL_ROWS)
+	case isKeyJustPressedOrRepeating(ebiten.KeyPageDown):
+		p.move(FIND_PANEL_ROWS)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) && e.mode == EDIT_MODE:
+		p.jump(e)
+		p.focused = false
+	default:
+		if otherKeyPressed() {
+			p.focused = false
+		}
+		return false
+	}
+
+	// Update the backing image.
+	e.updateImage()
+	return true
+}
+
+func (p *findPanel) Draw(e *Editor, screen *ebiten.Image) {
+	bounds := p.Bounds(e)
+	pad := e.width_padding
+	ebitenutil.DrawRect(screen, float64(bounds.Min.X), float64(bounds.Min.Y),
+		float64(bounds.Dx()), float64(bounds.Dy()), e.popupBackground())
+	ebitenutil.DrawLine(screen, float64(bounds.Min.X), float64(bounds.Min.Y),
+		float64(bounds.Max.X), float64(bounds.Min.Y), e.font_color)
+
+	top := bounds.Min.Y + pad
+	text.Draw(screen, p.heading(e), e.font_info.face, pad, top+e.font_info.ascent, e.barColor())
+
+	for row := 0; row < p.rows(); row++ {
+		index := p.first + row
+		if index >= p.count() {
+			break
+		}
+		top += e.font_info.yUnit
+		if index == p.selected {
+			ebitenutil.DrawRect(screen, float64(bounds.Min.X), float64(top),
+				float64(bounds.Dx()), float64(e.font_info.yUnit), e.select_color)
+		}
+		text.Draw(screen, p.item(e, index), e.font_info.face,
+			pad, top+e.font_info.ascent, e.font_color)
+	}
+}
+
+// otherKeyPressed returns true if a key other than a modifier was pressed.
+func otherKeyPressed() bool {
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		switch key {
+		case ebiten.KeyShift, ebiten.KeyShiftLeft, ebiten.KeyShiftRight,
+			ebiten.KeyControl, ebiten.KeyControlLeft, ebiten.KeyControlRight,
+			ebiten.KeyAlt, ebiten.KeyAltLeft, ebiten.KeyAltRight,
+			ebiten.KeyMeta, ebiten.KeyMetaLeft, ebiten.KeyMetaRight:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func (p *findPanel) Dismiss(e *Editor) {
+	if e.findPanel == p {
+		e.findPanel = nil
+	}
+	if p.workspace != nil {
+		p.workspace.cancel()
+	}
+}
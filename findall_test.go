@@ -0,0 +1,74 @@
+package noter
+
+import "testing"
+
+func TestFindMatches(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("Cat cat\ncatalog\nscat\n"))
+
+	table := [](struct {
+		term         string
+		regex, word  bool
+		rows, starts []int
+	}){
+		{"cat", false, false, []int{0, 0, 1, 2}, []int{0, 4, 0, 1}},
+		{"cat", false, true, []int{0, 0}, []int{0, 4}},
+		{"^c\\w+", true, false, []int{0, 1}, []int{0, 0}},
+		{"(", true, false, nil, nil},
+	}
+
+	for _, entry := range table {
+		editor.SetRegexSearch(entry.regex)
+		editor.SetSearchWholeWord(entry.word)
+		results := editor.findMatches([]rune(entry.term))
+		if len(results) != len(entry.rows) {
+			t.Fatalf("Incorrect matches of %q, expected %v, got %v", entry.term, len(entry.rows), len(results))
+		}
+		for i, result := range results {
+			row := editor.getLineNumberFromLine(result.line) - 1
+			if row != entry.rows[i] || result.x != entry.starts[i] {
+				t.Fatalf("Incorrect match %v of %q, expected (%v,%v), got (%v,%v)", i, entry.term, entry.rows[i], entry.starts[i], row, result.x)
+			}
+		}
+	}
+}
+
+func TestFindPanel(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one\ntwo one\nthree\n"))
+	editor.ShowFindAll("one")
+
+	panel := editor.findPanel
+	if panel == nil || editor.TopOverlay() != panel || len(panel.results) != 2 {
+		t.Fatalf("Expected a panel of 2 matches")
+	}
+	if !panel.focused {
+		t.Fatalf("Expected the panel to have focus when shown")
+	}
+	if item := panel.item(editor, 1); item != "2: two one" {
+		t.Fatalf("Incorrect result item, got %q", item)
+	}
+
+	panel.move(1)
+	panel.jump(editor)
+	if row, col := editor.Cursor(); row != 1 || col != 4 {
+		t.Fatalf("Expected a jump to (1,4), got (%v,%v)", row, col)
+	}
+
+	// The results follow edits.
+	editor.resetHighlight()
+	editor.MoveCursor(2, 0)
+	editor.storeUndoAction(editor.fnHandleRuneMulti([]rune("one ")))
+	if len(panel.results) != 3 {
+		t.Fatalf("Expected the results to follow the edit, got %v", len(panel.results))
+	}
+	editor.RunCommand("Undo")
+	if len(panel.results) != 2 {
+		t.Fatalf("Expected the results to follow the undo, got %v", len(panel.results))
+	}
+
+	editor.DismissOverlay()
+	if editor.findPanel != nil {
+		t.Fatalf("Expected the panel to be dismissed")
+	}
+}
@@ -0,0 +1,115 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+)
+
+// WithFocusMode enables the distraction-free mode, where the text is kept
+// to a column of the given width, centered within the editor with generous
+// margins. If set to <= 0, the text fills the width of the editor.
+func WithFocusMode(cols int) EditorOption {
+	return func(e *Editor) {
+		e.focus_cols = cols
+	}
+}
+
+// WithFocusDimming dims all but the paragraph at the cursor.
+func WithFocusDimming(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.focus_dimming = enabled
+	}
+}
+
+// FocusMode returns the width of the centered column, or 0 if the
+// distraction-free mode is disabled.
+func (e *Editor) FocusMode() int {
+	if e.focus_cols < 0 {
+		return 0
+	}
+	return e.focus_cols
+}
+
+// SetFocusMode sets the width of the centered column of the distraction-free
+// mode. If set to <= 0, the mode is disabled.
+func (e *Editor) SetFocusMode(cols int) {
+	e.focus_cols = cols
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// SetFocusDimming enables or disables dimming all but the paragraph at the cursor.
+func (e *Editor) SetFocusDimming(enabled bool) {
+	e.focus_dimming = enabled
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// textColumns returns the number of columns of text which are visible,
+// leaving a column for any scrollbar.
+func (e *Editor) textColumns() int {
+	cols := (e.width-e.width_padding*2)/e.font_info.xUnit - e.gutterCols
+	if e.scrollbar {
+		cols--
+	}
+	if e.focus_cols > 0 && e.focus_cols < cols {
+		cols = e.focus_cols
+	}
+	return cols
+}
+
+// textLeft returns the left edge of the text, in pixels.
+func (e *Editor) textLeft() int {
+	gutter := e.gutterCols * e.font_info.xUnit
+	if e.focus_cols > 0 {
+		margin := (e.width - e.textColumns()*e.font_info.xUnit - gutter) / 2
+		if margin > e.width_padding {
+			return margin + gutter
+		}
+	}
+	return e.width_padding + gutter
+}
+
+// focusParagraph returns the lines of the paragraph at the cursor.
+func (e *Editor) focusParagraph() map[*editorLine]bool {
+	paragraph := map[*editorLine]bool{e.cursor.line: true}
+	if isBlankLine(e.cursor.line.values) {
+		return paragraph
+	}
+	for line := e.cursor.line.prev; line != nil && !isBlankLine(line.values); line = line.prev {
+		paragraph[line] = true
+	}
+	for line := e.cursor.line.next; line != nil && !isBlankLine(line.values); line = line.next {
+		paragraph[line] = true
+	}
+	return paragraph
+}
+
+// dimColor fades the color to a third of its opacity.
+func dimColor(c color.Color) color.Color {
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{uint16(r / 3), uint16(g / 3), uint16(b / 3), uint16(a / 3)}
+}
@@ -0,0 +1,36 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestFocusModeLayout(t *testing.T) {
+	editor := NewEditor(
+		WithColumns(80),
+		WithFocusMode(40),
+	)
+
+	xUnit := editor.font_info.xUnit
+	if cols := editor.textColumns(); cols != 40 {
+		t.Fatalf("Incorrect focus columns, expected 40, got: %v", cols)
+	}
+	if left := editor.textLeft(); left != (editor.width-40*xUnit)/2 {
+		t.Fatalf("Incorrect focus margin, got: %v", left)
+	}
+
+	editor.SetFocusMode(0)
+	if left := editor.textLeft(); left != editor.width_padding {
+		t.Fatalf("Incorrect margin without focus mode, got: %v", left)
+	}
+}
+
+func TestFocusParagraph(t *testing.T) {
+	editor := NewEditor(WithFocusMode(40), WithFocusDimming(true))
+	editor.WriteText([]byte("one\n\ntwo\nthree\n\nfour\n"))
+
+	editor.MoveCursor(3, 0)
+	paragraph := editor.focusParagraph()
+	if len(paragraph) != 2 || !paragraph[editor.start.next.next] || !paragraph[editor.cursor.line] {
+		t.Fatalf("Incorrect focus paragraph, got %v lines", len(paragraph))
+	}
+}
@@ -0,0 +1,170 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// editingCommands are the commands which change the content.
+var editingCommands = map[string]bool{
+	"Undo":              true,
+	"Paste":             true,
+	"PastePlain":        true,
+	"Cut":               true,
+	"Reflow":            true,
+	"Align":             true,
+	"DeleteToLineStart": true,
+	"DeleteLine":        true,
+	"InsertLineBelow":   true,
+	"InsertLineAbove":   true,
+	"Duplicate":         true,
+	"UpperCase":         true,
+	"LowerCase":         true,
+	"Reindent":          true,
+	"TitleCase":         true,
+	"ToggleLineEnding":  true,
+	"IncrementNumber":   true,
+	"DecrementNumber":   true,
+	"PickColor":         true,
+}
+
+// WithReadOnly prevents the content from being edited by the user.
+// The cursor can still move, select, copy and search.
+func WithReadOnly(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.read_only = enabled
+	}
+}
+
+// ReadOnly returns true if the content can't be edited by the user.
+func (e *Editor) ReadOnly() bool {
+	return e.read_only
+}
+
+// SetReadOnly sets whether the content can be edited by the user. While
+// the content is loading, see WithStreamingLoad, it stays read-only, and
+// the setting applies once it has loaded.
+func (e *Editor) SetReadOnly(enabled bool) {
+	if e.Loading() {
+		e.loadReadOnly = enabled
+		return
+	}
+	e.read_only = enabled
+}
+
+// WithFollow enables the read-only log-follow mode. The view stays pinned
+// to the last line as text is added with AppendText, until the cursor is
+// moved off the last line. Moving back to the last line resumes following.
+func WithFollow(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.follow = enabled
+		e.following = enabled
+		if enabled {
+			e.read_only = true
+		}
+	}
+}
+
+// Following returns true if the view is pinned to the last line.
+func (e *Editor) Following() bool {
+	return e.follow && e.following
+}
+
+// ResumeFollowing moves the cursor to the last line, pinning the view there.
+func (e *Editor) ResumeFollowing() {
+	e.resetHighlight()
+	e.cursor.line = e.lastLine()
+	e.cursor.x = 0
+	e.fixPosition()
+	e.following = true
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// lastLine returns the last line of the content.
+func (e *Editor) lastLine() *editorLine {
+	return e.lineAt(e.lineCount() - 1)
+}
+
+// updateFollowing pauses following when the cursor leaves the last line,
+// and resumes it when the cursor returns.
+func (e *Editor) updateFollowing() {
+	if e.follow {
+		e.following = e.cursor.line.next == nil
+	}
+}
+
+// AppendText adds text to the end of the content, without moving the cursor
+// unless following. Appending isn't an edit, so it can't be undone. While a
+// tutorial plays, the text is appended once it stops.
+func (e *Editor) AppendText(text []byte) {
+	if e.tutorial != nil {
+		e.tutorial.appended = append(e.tutorial.appended, text...)
+		return
+	}
+
+	// Continue the final line, in place of its virtual `\n`.
+	current := e.lastLine()
+	current.values = current.values[:len(current.values)-1]
+	e.invalidateHighlight(current)
+
+	for _, char := range e.stripLineEndings(string(text)) {
+		current.values = append(current.values, char)
+		if char == '\n' {
+			nextLine := &editorLine{prev: current, values: make([]rune, 0)}
+			current.next = nextLine
+			current = nextLine
+			e.indexAppended(nextLine)
+		}
+	}
+	current.values = append(current.values, '\n')
+	e.notifyEdit()
+
+	if e.Following() {
+		e.cursor.line = current
+		e.cursor.x = 0
+	}
+	e.fixPosition()
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// drawFollowIndicator draws whether the view is following at the right
+// of the top bar.
+func (e *Editor) drawFollowIndicator(screen *ebiten.Image, textColor color.Color) {
+	indicator := e.tr("following")
+	if !e.following {
+		indicator = e.tr("paused: COMMAND-DOWN to follow")
+	}
+	x := e.width - e.width_padding - utf8.RuneCountInString(indicator)*e.font_info.xUnit
+	text.Draw(screen, indicator, e.font_info.face,
+		x, e.font_info.ascent,
+		textColor)
+}
@@ -0,0 +1,43 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestAppendText(t *testing.T) {
+	editor := NewEditor(WithFollow(true))
+
+	editor.AppendText([]byte("one\ntw"))
+	editor.AppendText([]byte("o\nthree\n"))
+	editor.AppendText(nil)
+
+	want := "one\ntwo\nthree\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect appended text, expected %q, got: %q", want, got)
+	}
+	if row, _ := editor.Cursor(); row != 3 {
+		t.Fatalf("Expected cursor to follow the last line, got row: %d", row)
+	}
+}
+
+func TestFollowPaused(t *testing.T) {
+	editor := NewEditor(WithFollow(true), WithRows(2))
+	editor.AppendText([]byte("one\ntwo\nthree\n"))
+
+	// Moving off the last line pauses following.
+	editor.MoveCursor(0, 0)
+	editor.updateFollowing()
+	editor.AppendText([]byte("four\n"))
+	if editor.Following() {
+		t.Fatalf("Expected following to be paused")
+	}
+	if row, _ := editor.Cursor(); row != 0 || editor.firstVisible != 0 {
+		t.Fatalf("Expected the view to stay put, got row: %d, first visible: %d", row, editor.firstVisible)
+	}
+
+	editor.ResumeFollowing()
+	editor.AppendText([]byte("five\n"))
+	if row, _ := editor.Cursor(); row != 5 || editor.firstVisible != 4 {
+		t.Fatalf("Expected the view to follow, got row: %d, first visible: %d", row, editor.firstVisible)
+	}
+}
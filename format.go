@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+const (
+	LINE_ENDING_LF   = "LF"
+	LINE_ENDING_CRLF = "CRLF"
+
+	ENCODING_UTF8    = "UTF-8"
+	ENCODING_UTF16LE = "UTF-16LE"
+	ENCODING_UTF16BE = "UTF-16BE"
+	ENCODING_LATIN1  = "Latin-1"
+)
+
+// detectLineEnding returns the line ending of the text, which is CRLF when
+// most lines end with "\r\n".
+func detectLineEnding(text []byte) string {
+	crlf := bytes.Count(text, []byte("\r\n"))
+	if crlf > 0 && crlf >= bytes.Count(text, []byte("\n"))-crlf {
+		return LINE_ENDING_CRLF
+	}
+	return LINE_ENDING_LF
+}
+
+// WithFinalNewline sets whether ReadText ends the text with a new line,
+// adding one to content which lacks it. When disabled, the text is read as
+// it was written, and a final line without a new line is marked as such.
+// The default is enabled.
+func WithFinalNewline(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.final_newline = enabled
+	}
+}
+
+// LineEnding returns the line ending detected when the content was
+// written, either LINE_ENDING_LF or LINE_ENDING_CRLF.
+func (e *Editor) LineEnding() string {
+	return e.lineEnding
+}
+
+// SetLineEnding changes the line ending of the content, which ReadText and
+// Save use, as an edit which can be undone.
+func (e *Editor) SetLineEnding(ending string) {
+	e.storeUndoAction(e.fnSetLineEnding(ending))
+}
+
+// fnSetLineEnding changes the line ending. Converting to CRLF also removes
+// any '\r' left at the end of lines, so it isn't written twice.
+func (e *Editor) fnSetLineEnding(ending string) func() bool {
+	old := e.lineEnding
+	if ending == old || (ending != LINE_ENDING_LF && ending != LINE_ENDING_CRLF) {
+		return noop
+	}
+
+	undos := make([]func() bool, 0)
+	if ending == LINE_ENDING_CRLF {
+		curRow, curX := e.Cursor()
+		row := 0
+		for line := e.start; line.next != nil; line, row = line.next, row+1 {
+			if n := len(line.values); n > 1 && line.values[n-2] == '\r' {
+				values := append(append([]rune{}, line.values[:n-2]...), '\n')
+				undos = append(undos, e.fnReplaceLines(row, 1, [][]rune{values}))
+			}
+		}
+		e.TryMoveCursor(curRow, curX)
+	}
+	e.lineEnding = ending
+	e.setModified()
+
+	undo := fnCompound(undos...)
+	return func() bool {
+		undo()
+		e.lineEnding = old
+		return true
+	}
+}
+
+// stripLineEndings returns the text with the line ending of the content,
+// if it's CRLF, replaced by '\n'.
+func (e *Editor) stripLineEndings(text string) string {
+	if e.lineEnding == LINE_ENDING_CRLF {
+		return strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	return text
+}
+
+// addLineEndings returns the text with each '\n' replaced by the line
+// ending of the content.
+func (e *Editor) addLineEndings(text string) string {
+	if e.lineEnding == LINE_ENDING_CRLF {
+		return strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	return text
+}
+
+// Encoding returns the encoding of the content, detected when it was
+// written or set by WithEncoding, such as ENCODING_UTF8.
+func (e *Editor) Encoding() string {
+	return e.encoding
+}
+
+// drawNoNewlineMarker marks the final line, at row y, as having no new line
+// after the row from start to end, unless the line is empty or the row
+// is clipped before its end.
+func (e *Editor) drawNoNewlineMarker(y int, line *editorLine, view *lineView, start, end int, markerColor color.Color) {
+	if line.next != nil || len(line.values) < 2 || end != len(view.runes)-1 {
+		return
+	}
+	width := font.MeasureString(e.font_info.face, string(view.runes[start:end])).Ceil()
+	x := e.textLeft() + width + e.font_info.xUnit
+	text.Draw(e.screen, e.tr("no newline at end of file"), e.font_info.face,
+		x, e.top_padding+y*e.font_info.yUnit+e.font_info.ascent,
+		markerColor)
+}
+
+// drawStatusIndicator draws the mode, line ending and encoding, with any
+// byte order mark being kept, at the right of the bottom bar.
+func (e *Editor) drawStatusIndicator(screen *ebiten.Image, textColor color.Color) {
+	mode := strings.ToUpper(e.tr(e.ModeName(e.Mode())))
+	encoding := e.encoding
+	if e.bom && e.keep_bom {
+		encoding += " BOM"
+	}
+	indicator := fmt.Sprintf("%s | %s | %s", mode, e.lineEnding, encoding)
+	if e.selecting {
+		indicator = strings.ToUpper(e.tr("select")) + " | " + indicator
+	}
+	x := e.width - e.width_padding - utf8.RuneCountInString(indicator)*e.font_info.xUnit
+	text.Draw(screen, indicator, e.font_info.face,
+		x, e.height-e.font_info.yUnit+e.font_info.ascent,
+		textColor)
+}
@@ -0,0 +1,135 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	table := [](struct {
+		text       string
+		lineEnding string
+		encoding   string
+	}){
+		{"", LINE_ENDING_LF, ENCODING_UTF8},
+		{"a\nb\n", LINE_ENDING_LF, ENCODING_UTF8},
+		{"a\r\nb\r\n", LINE_ENDING_CRLF, ENCODING_UTF8},
+		{"a\r\nb\nc\n", LINE_ENDING_LF, ENCODING_UTF8},
+		{"caf\xe9\n", LINE_ENDING_LF, ENCODING_LATIN1},
+		{"\xff\xfea\x00\r\x00\n\x00", LINE_ENDING_CRLF, ENCODING_UTF16LE},
+		{"\x00a\x00b\x00\n", LINE_ENDING_LF, ENCODING_UTF16BE},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte(entry.text))
+		if editor.LineEnding() != entry.lineEnding || editor.Encoding() != entry.encoding {
+			t.Fatalf("Incorrect format for %q, expected %s %s, got %s %s",
+				entry.text, entry.lineEnding, entry.encoding, editor.LineEnding(), editor.Encoding())
+		}
+	}
+}
+
+func TestFinalNewline(t *testing.T) {
+	table := [](struct {
+		enabled bool
+		text    string
+		want    string
+	}){
+		{true, "a\nb", "a\nb\n"},
+		{true, "a\nb\n", "a\nb\n"},
+		{false, "a\nb", "a\nb"},
+		{false, "a\nb\n", "a\nb\n"},
+		{false, "", ""},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithFinalNewline(entry.enabled))
+		editor.WriteText([]byte(entry.text))
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect text for %q with final newline %v, expected %q, got %q",
+				entry.text, entry.enabled, entry.want, got)
+		}
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	table := [](struct {
+		text    string
+		line    string
+		toggled string
+	}){
+		{"a\r\nb\r\n", "a\n", "a\nb\n"},
+		{"a\r\nb\nc\n", "a\r\n", "a\r\nb\r\nc\r\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte(entry.text))
+		if got := string(editor.lineAt(0).values); got != entry.line {
+			t.Fatalf("Incorrect first line of %q, expected %q, got %q", entry.text, entry.line, got)
+		}
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect text, expected %q, got %q", entry.text, got)
+		}
+
+		editor.RunCommand("ToggleLineEnding")
+		if got := string(editor.ReadText()); got != entry.toggled {
+			t.Fatalf("Incorrect converted text of %q, expected %q, got %q", entry.text, entry.toggled, got)
+		}
+
+		editor.RunCommand("Undo")
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect undo of conversion, expected %q, got %q", entry.text, got)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	table := [](struct {
+		force string
+		text  string
+		line  string
+	}){
+		{"", "\xff\xfeh\x00i\x00\n\x00", "hi\n"},
+		{"", "\xfe\xff\x00h\x00i\x00\n", "hi\n"},
+		{"", "h\x00i\x00\n\x00", "hi\n"},
+		{"", "\xef\xbb\xbfhi\n", "hi\n"},
+		{"", "caf\xe9\n", "café\n"},
+		{ENCODING_LATIN1, "café\n", "cafÃ©\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithEncoding(entry.force))
+		editor.WriteText([]byte(entry.text))
+		if got := string(editor.lineAt(0).values); got != entry.line {
+			t.Fatalf("Incorrect decoding of %q, expected %q, got %q", entry.text, entry.line, got)
+		}
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect encoding as %s, expected %q, got %q", editor.Encoding(), entry.text, got)
+		}
+	}
+}
+
+func TestKeepBOM(t *testing.T) {
+	table := [](struct {
+		keep bool
+		text string
+		want string
+	}){
+		{true, "\xef\xbb\xbfhi\n", "\xef\xbb\xbfhi\n"},
+		{false, "\xef\xbb\xbfhi\n", "hi\n"},
+		{false, "\xff\xfeh\x00i\x00\n\x00", "h\x00i\x00\n\x00"},
+		{true, "hi\n", "hi\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithKeepBOM(entry.keep))
+		editor.WriteText([]byte(entry.text))
+		if editor.BOM() != (entry.text[0] > 0x7f) {
+			t.Fatalf("Incorrect BOM detection for %q, got %v", entry.text, editor.BOM())
+		}
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect text for %q keeping the BOM %v, expected %q, got %q", entry.text, entry.keep, entry.want, got)
+		}
+	}
+}
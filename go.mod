@@ -6,6 +6,7 @@ require (
 	github.com/hajimehoshi/bitmapfont/v3 v3.0.0
 	github.com/hajimehoshi/ebiten/v2 v2.6.6
 	golang.org/x/image v0.15.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -19,5 +20,4 @@ require (
 	golang.org/x/mobile v0.0.0-20230922142353-e2f452493d57 // indirect
 	golang.org/x/sync v0.3.0 // indirect
 	golang.org/x/sys v0.12.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 )
@@ -0,0 +1,70 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// WithLineNumbers enables a column of line numbers to the left of the text.
+// The text has fewer columns to make room for the numbers.
+func WithLineNumbers(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.line_numbers = enabled
+	}
+}
+
+// LineNumbers returns true if line numbers are shown.
+func (e *Editor) LineNumbers() bool {
+	return e.line_numbers
+}
+
+// SetLineNumbers shows or hides the line numbers.
+func (e *Editor) SetLineNumbers(enabled bool) {
+	e.line_numbers = enabled
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// gutterColumns returns the columns needed for the line numbers,
+// including a space to separate them from the text.
+func (e *Editor) gutterColumns() int {
+	if !e.line_numbers {
+		return 0
+	}
+	lines := e.getLineNumberFromLine(nil) - 1
+	return len(strconv.Itoa(lines)) + 1
+}
+
+// drawLineNumber draws the number, right aligned in the gutter, at row y.
+func (e *Editor) drawLineNumber(y int, number int, numberColor color.Color) {
+	label := strconv.Itoa(number)
+	x := e.textLeft() - (len(label)+1)*e.font_info.xUnit
+	text.Draw(e.screen, label, e.font_info.face,
+		x, e.top_padding+y*e.font_info.yUnit+e.font_info.ascent,
+		numberColor)
+}
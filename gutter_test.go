@@ -0,0 +1,27 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGutterColumns(t *testing.T) {
+	editor := NewEditor(WithLineNumbers(true), WithColumns(40))
+	editor.WriteText([]byte(strings.Repeat("x\n", 120)))
+
+	// 121 lines, including the final empty line, need 3 digits and a space.
+	if editor.gutterCols != 4 {
+		t.Fatalf("Incorrect gutter columns, got: %v", editor.gutterCols)
+	}
+	if cols := editor.textColumns(); cols != 36 {
+		t.Fatalf("Expected the text to shrink to fit the gutter, got: %v", cols)
+	}
+	if left := editor.textLeft(); left != editor.width_padding+4*editor.font_info.xUnit {
+		t.Fatalf("Expected the text to start after the gutter, got: %v", left)
+	}
+
+	editor.SetLineNumbers(false)
+	if editor.gutterCols != 0 || editor.textColumns() != 40 {
+		t.Fatalf("Expected no gutter, got: %v", editor.gutterCols)
+	}
+}
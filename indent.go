@@ -0,0 +1,255 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "strings"
+
+// WithIndent sets what the Tab key inserts, and Shift-Tab removes: a tab
+// if useTabs is true, or else width spaces. The width is also that of
+// tabs, as WithTabWidth. The default is EDITOR_DEFAULT_TAB_WIDTH spaces.
+func WithIndent(useTabs bool, width int) EditorOption {
+	return func(e *Editor) {
+		e.indent_tabs = useTabs
+		WithTabWidth(width)(e)
+	}
+}
+
+// WithAutoIndent sets whether Enter starts the new line with the leading
+// whitespace of the line before. The default is disabled.
+func WithAutoIndent(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.auto_indent = enabled
+	}
+}
+
+// WithIndentAfter sets the runes, such as "{:", after which auto-indent
+// adds an indent to the new line. The default is none.
+func WithIndentAfter(runes string) EditorOption {
+	return func(e *Editor) {
+		e.indent_after = runes
+	}
+}
+
+// newLineRunes returns the runes which Enter inserts at the cursor: a new
+// line, followed by any auto-indent.
+func (e *Editor) newLineRunes() []rune {
+	rs := []rune{'\n'}
+	if !e.auto_indent {
+		return rs
+	}
+
+	values := e.cursor.line.values[:e.cursor.x]
+	for _, r := range values {
+		if r != ' ' && r != '\t' {
+			break
+		}
+		rs = append(rs, r)
+	}
+
+	// Add an indent after an opening rune, such as '{'.
+	last := len(values) - 1
+	for last >= 0 && (values[last] == ' ' || values[last] == '\t') {
+		last--
+	}
+	if last >= 0 && strings.ContainsRune(e.indent_after, values[last]) {
+		rs = append(rs, e.indent()...)
+	}
+	return rs
+}
+
+// fnNewLine inserts a new line at the cursor, as Enter, as a single undo
+// step.
+func (e *Editor) fnNewLine() func() bool {
+	rs := e.newLineRunes()
+	if len(rs) == 1 {
+		return e.fnHandleRuneSingle('\n')
+	}
+	return e.fnHandleRuneMulti(rs)
+}
+
+// indent returns the runes of one indent.
+func (e *Editor) indent() []rune {
+	if e.indent_tabs {
+		return []rune{'\t'}
+	}
+	spaces := make([]rune, e.tab_width)
+	for i := range spaces {
+		spaces[i] = ' '
+	}
+	return spaces
+}
+
+// Reindent re-expresses the leading whitespace of each line with the
+// indent of WithIndent, such as tabs for four spaces, keeping its width.
+// Any width short of a whole indent is kept as spaces. The lines are
+// changed as queued work, see Queue, and undone as a single step.
+func (e *Editor) Reindent() {
+	if !e.canEdit() {
+		return
+	}
+	indent := e.indent()
+	e.queueEdit(e.tr("reindent"), func(values []rune) []rune {
+		width, count := 0, 0
+		for _, r := range values {
+			if r == '\t' {
+				width += e.tab_width - width%e.tab_width
+			} else if r == ' ' {
+				width++
+			} else {
+				break
+			}
+			count++
+		}
+
+		reindented := make([]rune, 0, len(values))
+		for i := 0; i < width/e.tab_width; i++ {
+			reindented = append(reindented, indent...)
+		}
+		for i := 0; i < width%e.tab_width; i++ {
+			reindented = append(reindented, ' ')
+		}
+		if string(reindented) == string(values[:count]) {
+			return nil
+		}
+		return append(reindented, values[count:]...)
+	})
+}
+
+// fnInsertIndent inserts an indent at the cursor, as a single undo step.
+func (e *Editor) fnInsertIndent() func() bool {
+	return e.fnHandleRuneMulti(e.indent())
+}
+
+// inIndentation returns true if the cursor is preceded only by spaces
+// and tabs.
+func (e *Editor) inIndentation() bool {
+	if e.cursor.x == 0 {
+		return false
+	}
+	for _, r := range e.cursor.line.values[:e.cursor.x] {
+		if r != ' ' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// fnDeleteIndent deletes a tab before the cursor, or the spaces back to
+// the previous tab stop, as a single undo step.
+func (e *Editor) fnDeleteIndent() func() bool {
+	values := e.cursor.line.values
+	count := 1
+	if values[e.cursor.x-1] == ' ' {
+		count = e.cursor.x % e.tab_width
+		if count == 0 {
+			count = e.tab_width
+		}
+		for i := 1; i < count; i++ {
+			if values[e.cursor.x-1-i] != ' ' {
+				count = i
+				break
+			}
+		}
+	}
+	deleted := append([]rune{}, values[e.cursor.x-count:e.cursor.x]...)
+
+	for i := 0; i < count; i++ {
+		e.deletePrevious()
+	}
+
+	lineNum := e.getLineNumber()
+	curX := e.cursor.x
+	return func() bool {
+		e.MoveCursor(lineNum, curX)
+		for _, r := range deleted {
+			e.handleRune(r)
+		}
+		return true
+	}
+}
+
+// dedentWidth returns the number of runes of the first indent of the line:
+// a tab, or up to an indent's worth of spaces.
+func (e *Editor) dedentWidth(values []rune) int {
+	if len(values) > 0 && values[0] == '\t' {
+		return 1
+	}
+	count := 0
+	for count < e.tab_width && count < len(values) && values[count] == ' ' {
+		count++
+	}
+	return count
+}
+
+// fnDedentLine removes the first indent of the cursor line, as a single
+// undo step. The cursor stays with the text.
+func (e *Editor) fnDedentLine() func() bool {
+	values := e.cursor.line.values
+	count := e.dedentWidth(values)
+	if count == 0 {
+		return noop
+	}
+
+	x := e.cursor.x - count
+	if x < 0 {
+		x = 0
+	}
+	dedented := append([]rune{}, values[count:]...)
+	undo := e.fnReplaceLines(e.getLineNumber(), 1, [][]rune{dedented})
+	e.cursor.x = x
+	return undo
+}
+
+// fnIndentSelection indents (dir > 0) or dedents (dir < 0) each line of a
+// selection spanning several lines, as a single undo step. Blank lines
+// aren't indented. The lines stay selected, with the cursor at the end of
+// the last. It returns nil if the selection is within one line.
+func (e *Editor) fnIndentSelection(dir int) func() bool {
+	first, last, ok := e.selectedRows()
+	if !ok || first == last {
+		return nil
+	}
+
+	changed := false
+	lines := make([][]rune, 0, last-first+1)
+	for line, row := e.lineAt(first), first; row <= last; line, row = line.next, row+1 {
+		values := line.values
+		switch {
+		case dir > 0 && !isBlankLine(values):
+			values = append(e.indent(), values...)
+		case dir < 0:
+			values = values[e.dedentWidth(values):]
+		}
+		changed = changed || len(values) != len(line.values)
+		lines = append(lines, append([]rune{}, values...))
+	}
+	if !changed {
+		return noop
+	}
+
+	undo := e.fnReplaceLines(first, len(lines), lines)
+	lastLine := e.lineAt(last)
+	e.highlightBetween(e.lineAt(first), 0, lastLine, len(lastLine.values)-1)
+	e.cursor.line, e.cursor.x = lastLine, len(lastLine.values)-1
+	return undo
+}
@@ -0,0 +1,188 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestDeleteIndent(t *testing.T) {
+	table := [](struct {
+		text string
+		x    int
+		want string
+	}){
+		{"        foo\n", 8, "    foo\n"},
+		{"      foo\n", 6, "    foo\n"},
+		{"   foo\n", 3, "foo\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte(entry.text))
+		editor.MoveCursor(0, entry.x)
+
+		if !editor.inIndentation() {
+			t.Fatalf("Expected %q at %d to be in indentation", entry.text, entry.x)
+		}
+		undo := editor.fnDeleteIndent()
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect indent deletion, expected %q, got %q", entry.want, got)
+		}
+
+		undo()
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect undo of indent deletion, expected %q, got %q", entry.text, got)
+		}
+	}
+
+	editor := NewEditor()
+	editor.WriteText([]byte("  x foo\n"))
+	editor.MoveCursor(0, 4)
+	if editor.inIndentation() {
+		t.Fatalf("Expected cursor after text not to be in indentation")
+	}
+}
+
+func TestInsertIndent(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("foo\n"))
+
+	undo := editor.fnInsertIndent()
+	if got := string(editor.ReadText()); got != "    foo\n" {
+		t.Fatalf("Incorrect indent, got: %q", got)
+	}
+
+	undo()
+	if got := string(editor.ReadText()); got != "foo\n" {
+		t.Fatalf("Expected a single undo to remove the indent, got: %q", got)
+	}
+}
+
+func TestWithIndent(t *testing.T) {
+	editor := NewEditor(WithIndent(true, 8))
+	editor.WriteText([]byte("foo\n"))
+
+	editor.storeUndoAction(editor.fnInsertIndent())
+	if got := string(editor.ReadText()); got != "\tfoo\n" || editor.TabWidth() != 8 {
+		t.Fatalf("Expected a tab to be inserted, got: %q", got)
+	}
+
+	undo := editor.fnDeleteIndent()
+	if got := string(editor.ReadText()); got != "foo\n" {
+		t.Fatalf("Expected the tab to be deleted, got: %q", got)
+	}
+	undo()
+	if got := string(editor.ReadText()); got != "\tfoo\n" {
+		t.Fatalf("Expected the tab to be restored, got: %q", got)
+	}
+}
+
+func TestDedentLine(t *testing.T) {
+	table := [](struct {
+		text string
+		x    int
+		want string
+		wx   int
+	}){
+		{"      foo\n", 7, "  foo\n", 3},
+		{"\t\tfoo\n", 2, "\tfoo\n", 1},
+		{"  foo\n", 1, "foo\n", 0},
+		{"foo\n", 1, "foo\n", 1},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte(entry.text))
+		editor.MoveCursor(0, entry.x)
+
+		undo := editor.fnDedentLine()
+		if got := string(editor.ReadText()); got != entry.want || editor.cursor.x != entry.wx {
+			t.Fatalf("Incorrect dedent of %q, expected %q at %d, got %q at %d", entry.text, entry.want, entry.wx, got, editor.cursor.x)
+		}
+
+		undo()
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect undo of dedent, expected %q, got %q", entry.text, got)
+		}
+	}
+}
+
+func TestAutoIndent(t *testing.T) {
+	table := [](struct {
+		text string
+		x    int
+		want string
+	}){
+		{"  foo\n", 5, "  foo\n  \n"},
+		{"\tfoo {\n", 6, "\tfoo {\n\t    \n"},
+		{"if x:  \n", 7, "if x:  \n    \n"},
+		{"  foo\n", 1, " \n  foo\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithAutoIndent(true), WithIndentAfter("{:"))
+		editor.WriteText([]byte(entry.text))
+		editor.MoveCursor(0, entry.x)
+
+		undo := editor.fnNewLine()
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect new line in %q, expected %q, got %q", entry.text, entry.want, got)
+		}
+
+		undo()
+		if got := string(editor.ReadText()); got != entry.text {
+			t.Fatalf("Incorrect undo of new line, expected %q, got %q", entry.text, got)
+		}
+	}
+}
+
+func TestIndentSelection(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a\n\n  b\nc\n"))
+	editor.MoveCursor(0, 0)
+	editor.highlightBetween(editor.lineAt(0), 0, editor.lineAt(2), 1)
+
+	undo := editor.fnIndentSelection(1)
+	if got := string(editor.ReadText()); got != "    a\n\n      b\nc\n" {
+		t.Fatalf("Incorrect indent of the selection, got: %q", got)
+	}
+	if first, last, ok := editor.selectedRows(); !ok || first != 0 || last != 2 {
+		t.Fatalf("Expected the lines to stay selected, got %v to %v", first, last)
+	}
+
+	dedent := editor.fnIndentSelection(-1)
+	if got := string(editor.ReadText()); got != "a\n\n  b\nc\n" {
+		t.Fatalf("Incorrect dedent of the selection, got: %q", got)
+	}
+	dedent()
+	undo()
+	if got := string(editor.ReadText()); got != "a\n\n  b\nc\n" {
+		t.Fatalf("Incorrect undo of the indent, got: %q", got)
+	}
+
+	editor.resetHighlight()
+	editor.highlightBetween(editor.lineAt(0), 0, editor.lineAt(0), 1)
+	if editor.fnIndentSelection(1) != nil {
+		t.Fatalf("Expected no indent of a selection within a line")
+	}
+}
+
+func TestReindent(t *testing.T) {
+	table := [](struct {
+		useTabs  bool
+		text     string
+		expected string
+	}){
+		{true, "a\n    b\n      c\n\t d\n", "a\n\tb\n\t  c\n\t d\n"},
+		{false, "\ta\n\t\tb\n  \tc\n", "    a\n        b\n    c\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithIndent(entry.useTabs, 4))
+		editor.WriteText([]byte(entry.text))
+		editor.RunCommand("Reindent")
+		runAll(editor)
+		if got := string(editor.ReadText()); got != entry.expected {
+			t.Fatalf("Incorrect reindent, expected %q, got %q", entry.expected, got)
+		}
+	}
+}
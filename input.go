@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"golang.org/x/image/font"
+)
+
+// lineInput edits a single line of runes, such as the search term,
+// with a cursor and a selection.
+type lineInput struct {
+	runes  *[]rune // runes being edited, or nil if there is no input.
+	cursor int     // position of the cursor.
+	anchor int     // other end of the selection, or -1 if nothing is selected.
+}
+
+// newLineInput returns an input editing the runes, with the cursor at the end.
+func newLineInput(runes *[]rune) lineInput {
+	return lineInput{runes: runes, cursor: len(*runes), anchor: -1}
+}
+
+// clamp keeps the cursor and selection within the runes,
+// which may have been changed directly.
+func (in *lineInput) clamp() {
+	if in.cursor > len(*in.runes) {
+		in.cursor = len(*in.runes)
+	}
+	if in.anchor > len(*in.runes) {
+		in.anchor = len(*in.runes)
+	}
+}
+
+// selection returns the range of selected runes.
+func (in *lineInput) selection() (start, end int, ok bool) {
+	if in.runes == nil || in.anchor < 0 || in.anchor == in.cursor {
+		return 0, 0, false
+	}
+	in.clamp()
+	if in.anchor < in.cursor {
+		return in.anchor, in.cursor, true
+	}
+	return in.cursor, in.anchor, true
+}
+
+// selected returns the selected runes.
+func (in *lineInput) selected() []rune {
+	start, end, ok := in.selection()
+	if !ok {
+		return nil
+	}
+	return append([]rune{}, (*in.runes)[start:end]...)
+}
+
+// deleteSelection deletes the selected runes, returning true if there were any.
+func (in *lineInput) deleteSelection() bool {
+	start, end, ok := in.selection()
+	if !ok {
+		return false
+	}
+	*in.runes = append((*in.runes)[:start], (*in.runes)[end:]...)
+	in.cursor, in.anchor = start, -1
+	return true
+}
+
+// insert replaces the selection, if any, with the runes.
+func (in *lineInput) insert(rs []rune) {
+	if in.runes == nil {
+		return
+	}
+	in.deleteSelection()
+	in.clamp()
+
+	runes := append([]rune{}, (*in.runes)[:in.cursor]...)
+	runes = append(runes, rs...)
+	*in.runes = append(runes, (*in.runes)[in.cursor:]...)
+	in.cursor += len(rs)
+}
+
+// backspace deletes the selection, or the rune before the cursor.
+func (in *lineInput) backspace() {
+	if in.runes == nil || in.deleteSelection() {
+		return
+	}
+	in.clamp()
+	if in.cursor > 0 {
+		*in.runes = append((*in.runes)[:in.cursor-1], (*in.runes)[in.cursor:]...)
+		in.cursor--
+	}
+}
+
+// move moves the cursor, extending the selection if shift is held.
+func (in *lineInput) move(to int, shift bool) {
+	if in.runes == nil {
+		return
+	}
+	in.clamp()
+	if to < 0 {
+		to = 0
+	}
+	if to > len(*in.runes) {
+		to = len(*in.runes)
+	}
+
+	switch {
+	case shift && in.anchor < 0:
+		in.anchor = in.cursor
+	case !shift:
+		in.anchor = -1
+	}
+	in.cursor = to
+}
+
+// drawInput renders the selection and cursor of the input,
+// whose text is drawn in the top bar from x.
+func (e *Editor) drawInput(x int) {
+	if e.input.runes == nil {
+		return
+	}
+	e.input.clamp()
+	runes := *e.input.runes
+	fontFace := e.font_info.face
+	offset := func(i int) int {
+		return x + font.MeasureString(fontFace, string(runes[:i])).Floor()
+	}
+
+	if start, end, ok := e.input.selection(); ok {
+		ebitenutil.DrawRect(e.screen,
+			float64(offset(start)), 0,
+			float64(offset(end)-offset(start)), float64(e.font_info.yUnit),
+			e.select_color)
+	}
+
+	ebitenutil.DrawRect(e.screen,
+		float64(offset(e.input.cursor)), 0,
+		float64(e.font_info.xUnit), float64(e.font_info.yUnit),
+		e.cursorColor())
+}
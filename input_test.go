@@ -0,0 +1,34 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestLineInput(t *testing.T) {
+	runes := []rune("hello")
+	in := newLineInput(&runes)
+
+	in.move(1, false)
+	in.insert([]rune("ey"))
+	if got := string(runes); got != "heyello" {
+		t.Fatalf("Incorrect insert, got: %q", got)
+	}
+
+	// Select "yel" and type over it.
+	in.move(2, false)
+	in.move(5, true)
+	if got := string(in.selected()); got != "yel" {
+		t.Fatalf("Incorrect selection, got: %q", got)
+	}
+	in.insert([]rune("Y"))
+	if got := string(runes); got != "heYlo" || in.cursor != 3 {
+		t.Fatalf("Incorrect insert over selection, got: %q at %v", got, in.cursor)
+	}
+
+	in.backspace()
+	in.move(100, false)
+	in.backspace()
+	if got := string(runes); got != "hel" || in.cursor != 3 {
+		t.Fatalf("Incorrect backspace, got: %q at %v", got, in.cursor)
+	}
+}
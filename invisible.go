@@ -0,0 +1,106 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"image/color"
+	"unicode"
+)
+
+// invisibleColor is the color of the placeholders of invisible runes.
+var invisibleColor = color.RGBA{200, 0, 0, 255}
+
+// WithVisibleControls sets whether control runes, and zero-width or bidi
+// control runes, are displayed as a placeholder of their codepoint such as
+// <200B>, so that they can't hide in the content. The default is enabled.
+func WithVisibleControls(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.visible_controls = enabled
+	}
+}
+
+// isInvisible returns true if the rune is a control or format rune, other
+// than a tab or the end of a line.
+func isInvisible(r rune) bool {
+	if r == '\t' || r == '\n' {
+		return false
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
+
+// hasInvisible returns true if any of the runes are invisible.
+func hasInvisible(values []rune) bool {
+	for _, r := range values {
+		if isInvisible(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// controlLineView returns a view which displays each invisible rune as a
+// placeholder of its codepoint, in the invisible color.
+func controlLineView(values []rune) *lineView {
+	return withControls(newLineView(values))
+}
+
+// withControls returns the view with each invisible rune it displays shown
+// as a placeholder of its codepoint, in the invisible color, so that the
+// placeholders are shown in any view, such as of a table.
+func withControls(v *lineView) *lineView {
+	shown := &lineView{
+		runes:  make([]rune, 0, len(v.runes)),
+		index:  make([]int, len(v.index)),
+		colors: make([]color.Color, 0, len(v.runes)),
+	}
+
+	// moved[d] is the display position which d moves to.
+	moved := make([]int, len(v.runes)+1)
+	for d, r := range v.runes {
+		moved[d] = len(shown.runes)
+		if !isInvisible(r) {
+			shown.runes = append(shown.runes, r)
+			if v.colors != nil {
+				shown.colors = append(shown.colors, v.colors[d])
+			} else {
+				shown.colors = append(shown.colors, nil)
+			}
+			continue
+		}
+		for _, p := range fmt.Sprintf("<%02X>", r) {
+			shown.runes = append(shown.runes, p)
+			shown.colors = append(shown.colors, invisibleColor)
+		}
+	}
+	moved[len(v.runes)] = len(shown.runes)
+
+	for x, d := range v.index {
+		shown.index[x] = moved[d]
+	}
+	for _, t := range v.tokens {
+		t.display = moved[t.display]
+		shown.tokens = append(shown.tokens, t)
+	}
+	return shown
+}
@@ -0,0 +1,55 @@
+package noter
+
+import "testing"
+
+func TestControlLineView(t *testing.T) {
+	table := [](struct {
+		text    string
+		display string
+		index   int // display position of the end of the line.
+	}){
+		{"ab\n", "ab\n", 2},
+		{"a\u200Bb\n", "a<200B>b\n", 8},
+		{"\x1bx\n", "<1B>x\n", 5},
+		{"\u202Eab\n", "<202E>ab\n", 8},
+		{"a\tb\n", "a\tb\n", 3},
+	}
+
+	for _, entry := range table {
+		values := []rune(entry.text)
+		view := controlLineView(values)
+		if string(view.runes) != entry.display || len(view.colors) != len(view.runes) {
+			t.Fatalf("Incorrect view of %q, got %q", entry.text, string(view.runes))
+		}
+		if got := view.index[len(values)-1]; got != entry.index {
+			t.Fatalf("Incorrect index of the end of %q, expected %v, got %v", entry.text, entry.index, got)
+		}
+	}
+
+	editor := NewEditor()
+	editor.WriteText([]byte("a\u200Bb\n"))
+	if view := editor.lineView(editor.start); string(view.runes) != "a<200B>b\n" {
+		t.Fatalf("Expected invisible runes to be shown, got %q", string(view.runes))
+	}
+}
+
+func TestControlsInModes(t *testing.T) {
+	table := [](struct {
+		options []EditorOption
+		text    string
+		display string
+	}){
+		{[]EditorOption{WithTableMode(true)}, "a\u200B,b\n", "a<200B>,b\n"},
+		{[]EditorOption{WithANSIColors(true), WithReadOnly(true)}, "\x1b[31ma\u200B\x1b[0m\n", "a<200B>\n"},
+		{nil, "x a\u200B\n", "    a<200B>\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(entry.options...)
+		editor.RegisterToken("x", 3, nil)
+		editor.WriteText([]byte(entry.text))
+		if view := editor.modeLineView(editor.start); string(view.runes) != entry.display {
+			t.Fatalf("Incorrect view of %q, expected %q, got %q", entry.text, entry.display, string(view.runes))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// Keymap is a set of key bindings, layered over the default bindings, such
+// as KeymapVim.
+type Keymap struct {
+	name     string
+	bindings map[string]string // keystrokes bound to the names of commands.
+	attach   func(e *Editor)   // sets up the keymap on a new editor.
+}
+
+// KeymapDefault is the default bindings, described on Editor.
+var KeymapDefault = &Keymap{name: "default"}
+
+// NewKeymap returns a keymap which binds keystrokes to the names of
+// commands, as for Editor.Bind.
+func NewKeymap(name string, bindings map[string]string) (*Keymap, error) {
+	k := &Keymap{name: name, bindings: make(map[string]string)}
+	for keystroke, command := range bindings {
+		canonical, err := parseKeystroke(keystroke)
+		if err != nil {
+			return nil, err
+		}
+		k.bindings[canonical] = command
+	}
+	return k, nil
+}
+
+// Name returns the name of the keymap.
+func (k *Keymap) Name() string {
+	return k.name
+}
+
+// apply binds the keys of the keymap, then sets it up.
+func (k *Keymap) apply(e *Editor) {
+	for keystroke, command := range k.bindings {
+		e.Bind(keystroke, command)
+	}
+	if k.attach != nil {
+		k.attach(e)
+	}
+}
+
+// WithKeymap sets the key bindings.
+// If set to nil, KeymapDefault is used.
+func WithKeymap(opt *Keymap) EditorOption {
+	return func(e *Editor) {
+		if opt == nil {
+			opt = KeymapDefault
+		}
+		e.keymap = opt
+	}
+}
+
+// Keymap returns the key bindings in use.
+func (e *Editor) Keymap() *Keymap {
+	return e.keymap
+}
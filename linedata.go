@@ -0,0 +1,123 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// Line data lets hosts attach values to lines, such as a todo status or the
+// state of a step in a game. The values are kept on the line itself, so they
+// move with it as lines above are added or removed, and when it is swapped.
+// When a line is removed, such as when joined to the line above, its values
+// are passed to the removal function.
+
+// WithOnLineDataRemoved sets the function called with each value attached
+// to a line which has been removed. The default is no action.
+func WithOnLineDataRemoved(opt func(key string, value any)) EditorOption {
+	return func(e *Editor) {
+		e.on_data_removed = opt
+	}
+}
+
+// SetLineData attaches the value to the line at row, under the key.
+func (e *Editor) SetLineData(row int, key string, value any) {
+	line := e.lineAt(row)
+	if line.data == nil {
+		line.data = make(map[string]any)
+	}
+	line.data[key] = value
+	if e.dataLines == nil {
+		e.dataLines = make(map[*editorLine]bool)
+	}
+	e.dataLines[line] = true
+	e.Invalidate()
+}
+
+// LineData returns the value attached to the line at row under the key.
+func (e *Editor) LineData(row int, key string) (any, bool) {
+	value, ok := e.lineAt(row).data[key]
+	return value, ok
+}
+
+// DeleteLineData removes the value attached to the line at row under the
+// key, without calling the removal function.
+func (e *Editor) DeleteLineData(row int, key string) {
+	line := e.lineAt(row)
+	delete(line.data, key)
+	if len(line.data) == 0 {
+		line.data = nil
+		delete(e.dataLines, line)
+	}
+	e.Invalidate()
+}
+
+// LinesWithData returns the rows of the lines with a value under the key,
+// in order.
+func (e *Editor) LinesWithData(key string) []int {
+	var rows []int
+	row := 0
+	for line := e.start; line != nil; line = line.next {
+		if _, ok := line.data[key]; ok {
+			rows = append(rows, row)
+		}
+		row++
+	}
+	return rows
+}
+
+// removeLineData notes the lines with values in the tree of lines removed
+// by indexRemoved, for checkLineData to report.
+func (e *Editor) removeLineData(line *editorLine) {
+	if line == nil || len(e.dataLines) == 0 {
+		return
+	}
+	if e.dataLines[line] {
+		e.dataRemoved = append(e.dataRemoved, line)
+	}
+	e.removeLineData(line.tree.left)
+	e.removeLineData(line.tree.right)
+}
+
+// checkLineData reports the values of lines which have been removed. Only
+// the lines noted by removeLineData are checked, as a line may be added
+// again by the same edit, such as when lines are moved.
+func (e *Editor) checkLineData() {
+	removed := e.dataRemoved
+	e.dataRemoved = nil
+	if len(e.dataLines) == 0 {
+		return
+	}
+
+	for _, line := range removed {
+		if !e.dataLines[line] {
+			continue
+		}
+		if _, ok := e.rowOf(line); ok {
+			continue
+		}
+		delete(e.dataLines, line)
+		if e.on_data_removed == nil {
+			continue
+		}
+		for key, value := range line.data {
+			e.on_data_removed(key, value)
+		}
+	}
+}
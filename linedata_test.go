@@ -0,0 +1,47 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineData(t *testing.T) {
+	removed := map[string]any{}
+	editor := NewEditor(WithOnLineDataRemoved(func(key string, value any) {
+		removed[key] = value
+	}))
+	editor.WriteText([]byte("a\nb\nc\n"))
+	editor.SetLineData(1, "todo", true)
+	editor.SetLineData(2, "step", 3)
+
+	// Data moves with its line.
+	editor.MoveCursor(0, 0)
+	editor.storeUndoAction(editor.fnHandleRuneSingle('\n'))
+	editor.MoveCursor(2, 0)
+	editor.storeUndoAction(editor.fnSwapDown())
+	if rows := editor.LinesWithData("todo"); !reflect.DeepEqual(rows, []int{3}) {
+		t.Fatalf("Incorrect rows with data, got %v", rows)
+	}
+	if value, ok := editor.LineData(2, "step"); !ok || value != 3 {
+		t.Fatalf("Incorrect data after swap, got %v", value)
+	}
+
+	// Joining a line to the one above removes it.
+	editor.MoveCursor(3, 0)
+	editor.storeUndoAction(editor.fnDeleteSinglePrevious())
+	if value, ok := removed["todo"]; !ok || value != true {
+		t.Fatalf("Expected the removed data to be reported, got %v", removed)
+	}
+	if _, ok := removed["step"]; ok {
+		t.Fatalf("Unexpected data reported as removed: %v", removed)
+	}
+
+	// Replacing lines removes those past the replacements.
+	editor.storeUndoAction(editor.fnReplaceLines(1, 2, [][]rune{[]rune("d\n")}))
+	if value, ok := removed["step"]; !ok || value != 3 {
+		t.Fatalf("Expected the replaced data to be reported, got %v", removed)
+	}
+	if len(editor.dataLines) != 0 || len(editor.dataRemoved) != 0 {
+		t.Fatalf("Expected no lines with data, got %v", editor.dataLines)
+	}
+}
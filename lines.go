@@ -0,0 +1,230 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "math/rand"
+
+// lineIndex numbers the lines of the content, which also form a counted
+// tree: a treap in the order of the content, where each line counts the
+// lines of its subtree. Finding the line at a row, or the row of a line,
+// takes O(log n) time. The views of the content share its index.
+type lineIndex struct {
+	root *editorLine // nil when the lines must be numbered again.
+}
+
+// lineTree is the place of a line in the tree of its lineIndex.
+type lineTree struct {
+	left, right, parent *editorLine
+	size                int    // the number of lines in the subtree.
+	priority            uint32 // a random heap order, which balances the tree.
+}
+
+// treeSize returns the number of lines in the subtree.
+func treeSize(line *editorLine) int {
+	if line == nil {
+		return 0
+	}
+	return line.tree.size
+}
+
+// resize counts the lines of the subtree, adopting its children.
+func (line *editorLine) resize() {
+	line.tree.size = 1 + treeSize(line.tree.left) + treeSize(line.tree.right)
+	if line.tree.left != nil {
+		line.tree.left.tree.parent = line
+	}
+	if line.tree.right != nil {
+		line.tree.right.tree.parent = line
+	}
+}
+
+// mergeTrees joins two trees, the lines of a before those of b.
+func mergeTrees(a, b *editorLine) *editorLine {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.tree.priority > b.tree.priority:
+		a.tree.right = mergeTrees(a.tree.right, b)
+		a.resize()
+		return a
+	}
+	b.tree.left = mergeTrees(a, b.tree.left)
+	b.resize()
+	return b
+}
+
+// splitTree splits a tree into its first n lines and the rest.
+func splitTree(root *editorLine, n int) (*editorLine, *editorLine) {
+	if root == nil {
+		return nil, nil
+	}
+	if treeSize(root.tree.left) >= n {
+		first, rest := splitTree(root.tree.left, n)
+		root.tree.left = rest
+		root.resize()
+		return first, root
+	}
+	first, rest := splitTree(root.tree.right, n-treeSize(root.tree.left)-1)
+	root.tree.right = first
+	root.resize()
+	return root, rest
+}
+
+// setRoot sets the root of the tree, which has no parent.
+func (e *Editor) setRoot(root *editorLine) {
+	if root != nil {
+		root.tree.parent = nil
+	}
+	e.lines.root = root
+}
+
+// invalidateLines forgets the line numbers, after lines are added or
+// removed. They are indexed again when next needed, for any other views
+// too, which share the index.
+func (e *Editor) invalidateLines() {
+	if e.lines != nil {
+		e.lines.root = nil
+	}
+}
+
+// indexLines numbers the lines, if they have changed since last numbered,
+// building the tree of the lines in O(n) time.
+func (e *Editor) indexLines() {
+	if e.lines == nil {
+		e.lines = &lineIndex{}
+	}
+	if e.lines.root != nil {
+		return
+	}
+
+	// Each line is the right child of the nearest line before it with a
+	// higher priority, taking the lines of lower priority in between as
+	// its left subtree.
+	var spine []*editorLine
+	for line := e.start; line != nil; line = line.next {
+		line.tree = lineTree{priority: rand.Uint32()}
+		var left *editorLine
+		for len(spine) > 0 && spine[len(spine)-1].tree.priority < line.tree.priority {
+			left = spine[len(spine)-1]
+			spine = spine[:len(spine)-1]
+		}
+		line.tree.left = left
+		if len(spine) > 0 {
+			spine[len(spine)-1].tree.right = line
+		}
+		spine = append(spine, line)
+	}
+	if len(spine) > 0 {
+		resizeTree(spine[0])
+		e.setRoot(spine[0])
+	}
+}
+
+// resizeTree counts the lines of each subtree of a new tree.
+func resizeTree(line *editorLine) {
+	if line == nil || line.tree.size > 0 {
+		return
+	}
+	resizeTree(line.tree.left)
+	resizeTree(line.tree.right)
+	line.resize()
+}
+
+// lineCount returns the number of lines, including the final line.
+func (e *Editor) lineCount() int {
+	e.indexLines()
+	return treeSize(e.lines.root)
+}
+
+// rowOf returns the row of the line, and false if the line isn't in the
+// content, such as a line which has been removed.
+func (e *Editor) rowOf(line *editorLine) (int, bool) {
+	e.indexLines()
+	if line == nil {
+		return 0, false
+	}
+	row := treeSize(line.tree.left)
+	for ; line != e.lines.root; line = line.tree.parent {
+		parent := line.tree.parent
+		switch {
+		case parent == nil:
+			return 0, false
+		case parent.tree.right == line:
+			row += treeSize(parent.tree.left) + 1
+		case parent.tree.left != line:
+			return 0, false
+		}
+	}
+	return row, true
+}
+
+// indexAppended numbers a line added to the end of the content, without
+// numbering all of the lines again.
+func (e *Editor) indexAppended(line *editorLine) {
+	if e.lines == nil || e.lines.root == nil {
+		return
+	}
+	e.setRoot(mergeTrees(e.lines.root, newLineTree(line)))
+}
+
+// indexInserted numbers lines linked into the content after the line
+// prev, in O(log n) time for each.
+func (e *Editor) indexInserted(prev *editorLine, lines ...*editorLine) {
+	if e.lines == nil || e.lines.root == nil {
+		return
+	}
+	row, ok := e.rowOf(prev)
+	if !ok {
+		e.invalidateLines()
+		return
+	}
+	var inserted *editorLine
+	for _, line := range lines {
+		inserted = mergeTrees(inserted, newLineTree(line))
+	}
+	first, rest := splitTree(e.lines.root, row+1)
+	e.setRoot(mergeTrees(mergeTrees(first, inserted), rest))
+}
+
+// indexRemoved forgets count lines unlinked from the content at the row,
+// in O(log n) time.
+func (e *Editor) indexRemoved(row, count int) {
+	if e.lines == nil || e.lines.root == nil {
+		return
+	}
+	first, rest := splitTree(e.lines.root, row)
+	removed, rest := splitTree(rest, count)
+	if removed != nil {
+		removed.tree.parent = nil
+		e.removeLineData(removed)
+	}
+	e.setRoot(mergeTrees(first, rest))
+}
+
+// newLineTree returns the line as a tree of itself.
+func newLineTree(line *editorLine) *editorLine {
+	line.tree = lineTree{size: 1, priority: rand.Uint32()}
+	return line
+}
@@ -0,0 +1,79 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndexLines(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a\nb\nc\n"))
+
+	// Each edit adds or removes a line, which is numbered without numbering
+	// all of the lines again.
+	edits := []func(){
+		func() { editor.MoveCursor(1, 1); editor.handleRune('\n') },
+		func() { editor.MoveCursor(3, 0); editor.deletePrevious() },
+		func() { editor.storeUndoAction(editor.fnReplaceLines(0, 1, [][]rune{[]rune("x\n"), []rune("y\n")})) },
+		func() { editor.AppendText([]byte("d\ne\n")) },
+	}
+
+	for i, edit := range edits {
+		editor.lineCount()
+		edit()
+		if editor.lines.root == nil {
+			t.Fatalf("Expected the lines to stay indexed after edit %v", i)
+		}
+		row := 0
+		for line := editor.start; line != nil; line = line.next {
+			if got := editor.getLineNumberFromLine(line) - 1; got != row {
+				t.Fatalf("Incorrect row after edit %v, expected %v, got %v", i, row, got)
+			}
+			if editor.lineAt(row) != line {
+				t.Fatalf("Incorrect line at row %v after edit %v", row, i)
+			}
+			row++
+		}
+		if editor.lastLine().next != nil || editor.lineCount() != row {
+			t.Fatalf("Incorrect last line after edit %v", i)
+		}
+	}
+}
+
+func TestLineTree(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte(strings.Repeat("a\n", 1000)))
+
+	row := 0
+	for line := editor.start; line != nil; line = line.next {
+		if got, ok := editor.rowOf(line); !ok || got != row {
+			t.Fatalf("Incorrect row, expected %v, got %v", row, got)
+		}
+		if editor.lineAt(row) != line {
+			t.Fatalf("Incorrect line at row %v", row)
+		}
+		row++
+	}
+
+	// A line which has been removed isn't numbered.
+	removed := editor.lineAt(500)
+	editor.MoveCursor(500, 0)
+	editor.deletePrevious()
+	if _, ok := editor.rowOf(removed); ok {
+		t.Fatalf("Expected the removed line not to be numbered")
+	}
+	if editor.lineCount() != 1000 {
+		t.Fatalf("Incorrect line count, got %v", editor.lineCount())
+	}
+}
+
+func BenchmarkMoveCursor(b *testing.B) {
+	editor := NewEditor()
+	editor.WriteText([]byte(strings.Repeat("lorem ipsum\n", 100000)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		editor.MoveCursor(i%100000, 0)
+		editor.getLineNumber()
+	}
+}
@@ -0,0 +1,267 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// WithListEditing enables the editing of Markdown lists, where Enter
+// continues a list item with the next bullet or number, or ends the list
+// on an empty item, and Tab/Shift-Tab change the nesting of an item.
+// Numbered items are renumbered to follow on from each other.
+// The default is disabled.
+func WithListEditing(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.list_editing = enabled
+	}
+}
+
+// listItem describes a line beginning a Markdown list item.
+type listItem struct {
+	indent   int  // the number of leading spaces and tabs.
+	bullet   rune // '-', '*' or '+', or 0 if the item is numbered.
+	number   int
+	delim    rune // '.' or ')' following the number.
+	content  int  // the index of the text of the item.
+	checkbox bool
+}
+
+// parseListItem returns the list item which begins the line, if any.
+func parseListItem(values []rune) (item listItem, ok bool) {
+	i := 0
+	for i < len(values) && (values[i] == ' ' || values[i] == '\t') {
+		i++
+	}
+	item.indent = i
+
+	switch {
+	case i < len(values) && (values[i] == '-' || values[i] == '*' || values[i] == '+'):
+		item.bullet = values[i]
+		i++
+	case i < len(values) && unicode.IsDigit(values[i]):
+		start := i
+		for i < len(values) && unicode.IsDigit(values[i]) {
+			i++
+		}
+		if i == len(values) || (values[i] != '.' && values[i] != ')') {
+			return item, false
+		}
+		item.number, _ = strconv.Atoi(string(values[start:i]))
+		item.delim = values[i]
+		i++
+	default:
+		return item, false
+	}
+
+	// The marker is followed by a space, or ends an empty item.
+	switch {
+	case i < len(values) && values[i] == ' ':
+		i++
+	case i < len(values) && values[i] == '\n':
+	default:
+		return item, false
+	}
+
+	item.content = i
+	if mark := checkboxMark(values); mark >= 0 {
+		item.checkbox = true
+		item.content = mark + 2
+		if values[item.content] == ' ' {
+			item.content++
+		}
+	}
+	return item, true
+}
+
+// prefix returns the indent and marker of the item, from the line.
+func (item listItem) prefix(values []rune) []rune {
+	prefix := append([]rune{}, values[:item.indent]...)
+	if item.bullet != 0 {
+		prefix = append(prefix, item.bullet)
+	} else {
+		prefix = append(prefix, []rune(strconv.Itoa(item.number))...)
+		prefix = append(prefix, item.delim)
+	}
+	prefix = append(prefix, ' ')
+	if item.checkbox {
+		prefix = append(prefix, []rune("[ ] ")...)
+	}
+	return prefix
+}
+
+// renumberList numbers the numbered items of the lines to follow on from
+// each other. The outermost items keep the number of the first item, and
+// nested items are numbered from 1.
+func renumberList(lines [][]rune) [][]rune {
+	outer := -1
+	numbers := make(map[int]int) // the previous number at each indent.
+	for i, values := range lines {
+		item, ok := parseListItem(values)
+		if !ok {
+			continue
+		}
+		if outer < 0 || item.indent < outer {
+			outer = item.indent
+		}
+		for indent := range numbers {
+			if indent > item.indent {
+				delete(numbers, indent)
+			}
+		}
+		if item.bullet != 0 {
+			delete(numbers, item.indent)
+			continue
+		}
+
+		number := item.number
+		if previous, ok := numbers[item.indent]; ok {
+			number = previous + 1
+		} else if item.indent > outer {
+			number = 1
+		}
+		numbers[item.indent] = number
+		if number != item.number {
+			renumbered := append([]rune{}, values[:item.indent]...)
+			renumbered = append(renumbered, []rune(strconv.Itoa(number))...)
+			lines[i] = append(renumbered, values[item.indent+len(strconv.Itoa(item.number)):]...)
+		}
+	}
+	return lines
+}
+
+// listBlock returns the rows of the list around the row, as the lines
+// between blank lines.
+func (e *Editor) listBlock(row int) (first int, lines [][]rune) {
+	line := e.lineAt(row)
+	first = row
+	for line.prev != nil && !isBlankLine(line.prev.values) {
+		line = line.prev
+		first--
+	}
+	for ; line != nil && !isBlankLine(line.values); line = line.next {
+		lines = append(lines, line.values)
+	}
+	return first, lines
+}
+
+// editList replaces the list around the cursor with the edited lines,
+// renumbered, as a single undoable action. The edit is passed the lines
+// and the index of the cursor line, and returns the new lines and the
+// cursor position within them.
+func (e *Editor) editList(edit func(lines [][]rune, i int) ([][]rune, int, int)) {
+	row := e.getLineNumber()
+	first, lines := e.listBlock(row)
+	count := len(lines)
+
+	copied := make([][]rune, len(lines))
+	for i, values := range lines {
+		copied[i] = append([]rune{}, values...)
+	}
+	edited, i, x := edit(copied, row-first)
+	before := edited[i]
+	edited = renumberList(edited)
+
+	// Keep the cursor beside the same text, if the number changed length.
+	if item, ok := parseListItem(before); ok && x > item.indent {
+		x += len(edited[i]) - len(before)
+	}
+
+	e.storeUndoAction(e.fnReplaceLines(first, count, edited))
+	e.MoveCursor(first+i, x)
+}
+
+// continueList continues the list item at the cursor onto a new line,
+// or ends the list if the item is empty. It returns false if the cursor
+// isn't after the marker of a list item.
+func (e *Editor) continueList() bool {
+	values := e.cursor.line.values
+	item, ok := parseListItem(values)
+	if !ok || e.cursor.x < item.content {
+		return false
+	}
+
+	// An empty item ends the list.
+	if isBlankLine(values[item.content:]) {
+		e.editList(func(lines [][]rune, i int) ([][]rune, int, int) {
+			lines[i] = []rune{'\n'}
+			return lines, i, 0
+		})
+		return true
+	}
+
+	x := e.cursor.x
+	e.editList(func(lines [][]rune, i int) ([][]rune, int, int) {
+		next := item
+		next.number++
+		continued := next.prefix(values)
+		rest := len(continued)
+		continued = append(continued, values[x:]...)
+
+		before := append(append([]rune{}, values[:x]...), '\n')
+		lines = append(lines[:i+1], lines[i:]...)
+		lines[i], lines[i+1] = before, continued
+		return lines, i + 1, rest
+	})
+	return true
+}
+
+// nestListItem indents (dir > 0) or dedents the list item at the cursor
+// by an indent's worth. It returns false if the cursor isn't on a list item.
+func (e *Editor) nestListItem(dir int) bool {
+	values := e.cursor.line.values
+	item, ok := parseListItem(values)
+	if !ok {
+		return false
+	}
+
+	change := e.tab_width
+	if dir < 0 {
+		change = 0
+		for change < e.tab_width && change < item.indent && values[change] == ' ' {
+			change++
+		}
+		if change == 0 {
+			return true
+		}
+	}
+
+	x := e.cursor.x
+	e.editList(func(lines [][]rune, i int) ([][]rune, int, int) {
+		if dir > 0 {
+			spaces := []rune{}
+			for j := 0; j < change; j++ {
+				spaces = append(spaces, ' ')
+			}
+			lines[i] = append(spaces, values...)
+			return lines, i, x + change
+		}
+		lines[i] = append([]rune{}, values[change:]...)
+		if x -= change; x < 0 {
+			x = 0
+		}
+		return lines, i, x
+	})
+	return true
+}
@@ -0,0 +1,85 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenumberList(t *testing.T) {
+	table := [](struct{ lines, expected []string }){
+		{[]string{"1. a\n", "1. b\n", "7. c\n"}, []string{"1. a\n", "2. b\n", "3. c\n"}},
+		{[]string{"3) a\n", "text\n", "9) b\n"}, []string{"3) a\n", "text\n", "4) b\n"}},
+		{[]string{"9. a\n", "10. b\n", "1. c\n"}, []string{"9. a\n", "10. b\n", "11. c\n"}},
+		{[]string{"1. a\n", "  5. b\n", "  6. c\n", "4. d\n"}, []string{"1. a\n", "  1. b\n", "  2. c\n", "2. d\n"}},
+		{[]string{"1. a\n", "- b\n", "1. c\n"}, []string{"1. a\n", "- b\n", "1. c\n"}},
+	}
+
+	for _, entry := range table {
+		lines := make([][]rune, len(entry.lines))
+		for i, line := range entry.lines {
+			lines[i] = []rune(line)
+		}
+		got := []string{}
+		for _, values := range renumberList(lines) {
+			got = append(got, string(values))
+		}
+		if !reflect.DeepEqual(got, entry.expected) {
+			t.Fatalf("Incorrect renumbering of %q, expected %q, got %q", entry.lines, entry.expected, got)
+		}
+	}
+}
+
+func TestContinueList(t *testing.T) {
+	table := [](struct {
+		text     string
+		row, col int
+		expected string
+		erow     int
+		ecol     int
+	}){
+		{"- a\n", 0, 3, "- a\n- \n", 1, 2},
+		{"- [x] a\n", 0, 7, "- [x] a\n- [ ] \n", 1, 6},
+		{"1. ab\n2. c\n", 0, 4, "1. a\n2. b\n3. c\n", 1, 3},
+		{"9. a\n10. b\n", 0, 4, "9. a\n10. \n11. b\n", 1, 4},
+		{"- a\n- \n", 1, 2, "- a\n\n", 1, 0},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithListEditing(true))
+		editor.WriteText([]byte(entry.text))
+		editor.MoveCursor(entry.row, entry.col)
+		if !editor.continueList() {
+			t.Fatalf("Expected %q to continue", entry.text)
+		}
+		if got := string(editor.ReadText()); got != entry.expected {
+			t.Fatalf("Incorrect continuation of %q, expected %q, got %q", entry.text, entry.expected, got)
+		}
+		if row, col := editor.Cursor(); row != entry.erow || col != entry.ecol {
+			t.Fatalf("Incorrect cursor after %q, expected (%v,%v), got (%v,%v)", entry.text, entry.erow, entry.ecol, row, col)
+		}
+	}
+}
+
+func TestNestListItem(t *testing.T) {
+	editor := NewEditor(WithListEditing(true))
+	editor.WriteText([]byte("1. a\n2. b\n3. c\n"))
+	editor.MoveCursor(1, 4)
+
+	editor.nestListItem(1)
+	if got := string(editor.ReadText()); got != "1. a\n    1. b\n2. c\n" {
+		t.Fatalf("Incorrect nested text, got %q", got)
+	}
+	if _, col := editor.Cursor(); col != 8 {
+		t.Fatalf("Incorrect nested cursor, got %v", col)
+	}
+
+	editor.nestListItem(-1)
+	if got := string(editor.ReadText()); got != "1. a\n2. b\n3. c\n" {
+		t.Fatalf("Incorrect dedented text, got %q", got)
+	}
+
+	editor.runCommand("z")
+	if got := string(editor.ReadText()); got != "1. a\n    1. b\n2. c\n" {
+		t.Fatalf("Incorrect undone text, got %q", got)
+	}
+}
@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "bytes"
+
+// EDITOR_LOAD_CHUNK is the size of each chunk of a streaming load.
+const EDITOR_LOAD_CHUNK = 256 << 10
+
+// WithStreamingLoad enables streaming loads, so that huge files, such as
+// logs, don't stall the editor. Text written with WriteText which is
+// larger than EDITOR_LOAD_CHUNK is shown a chunk at a time: the first at
+// once, and the rest as queued work. The content is read-only until it
+// has loaded, but can be saved. The default is disabled.
+func WithStreamingLoad(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.streaming_load = enabled
+	}
+}
+
+// Loading returns true while the content is being loaded.
+func (e *Editor) Loading() bool {
+	return e.unloaded != nil
+}
+
+// chunkEnd returns the end of the chunk of text from start, which is
+// just after the first new line past EDITOR_LOAD_CHUNK.
+func chunkEnd(text []byte, start int) int {
+	end := start + EDITOR_LOAD_CHUNK
+	if end >= len(text) {
+		return len(text)
+	}
+	if i := bytes.IndexByte(text[end:], '\n'); i >= 0 {
+		return end + i + 1
+	}
+	return len(text)
+}
+
+// beginLoad queues the loading of the rest of the text, after the first
+// chunk, which ends with a new line.
+func (e *Editor) beginLoad(rest []byte) {
+	e.endLoad()
+	e.unloaded = rest
+	e.loadReadOnly, e.read_only = e.read_only, true
+
+	id := e.loadID
+	total := float64(len(rest))
+	e.Queue(e.tr("loading"), func() (progress float64, done bool) {
+		if id != e.loadID {
+			// The content was written again.
+			return 1, true
+		}
+		end := chunkEnd(e.unloaded, 0)
+		chunk := e.unloaded[:end]
+		e.unloaded = e.unloaded[end:]
+		e.AppendText(chunk)
+		e.moveToOpen()
+
+		if len(e.unloaded) == 0 {
+			e.endLoad()
+			return 1, true
+		}
+		return 1 - float64(len(e.unloaded))/total, false
+	})
+}
+
+// endLoad stops any load in progress, restoring the read-only setting of
+// before the load, or as set by SetReadOnly during it.
+func (e *Editor) endLoad() {
+	if e.unloaded == nil {
+		return
+	}
+	e.unloaded = nil
+	e.openAt = nil
+	e.read_only = e.loadReadOnly
+	e.loadID++
+}
@@ -0,0 +1,60 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStreamingLoad(t *testing.T) {
+	text := strings.Repeat("a line of a very large log file\n", 3*EDITOR_LOAD_CHUNK/32)
+	editor := NewEditor(WithStreamingLoad(true))
+	editor.WriteText([]byte(text))
+
+	if !editor.Loading() || !editor.ReadOnly() {
+		t.Fatalf("Expected the content to be loading, and read-only")
+	}
+	if loaded := len(editor.getAllRunes()); loaded >= len(text) {
+		t.Fatalf("Expected only the first chunk to be loaded, got %v of %v", loaded, len(text))
+	}
+	if got := string(editor.ReadText()); got != text {
+		t.Fatalf("Expected the whole text to be read while loading")
+	}
+
+	for i := 0; editor.Loading(); i++ {
+		if i > 10 {
+			t.Fatalf("Expected the load to finish")
+		}
+		editor.runQueue()
+	}
+	if editor.ReadOnly() || string(editor.ReadText()) != text {
+		t.Fatalf("Expected the whole text to be loaded, and editable")
+	}
+	if rows := editor.lineCount(); rows != 3*EDITOR_LOAD_CHUNK/32+1 {
+		t.Fatalf("Incorrect number of lines, got %v", rows)
+	}
+
+	// Writing again stops a load in progress.
+	editor.WriteText([]byte(text))
+	editor.WriteText([]byte("short\n"))
+	editor.runQueue()
+	if editor.Loading() || string(editor.ReadText()) != "short\n" {
+		t.Fatalf("Expected the earlier load to stop, got %q", editor.ReadText())
+	}
+}
+
+func TestStreamingLoadSetReadOnly(t *testing.T) {
+	text := strings.Repeat("a line of a very large log file\n", 3*EDITOR_LOAD_CHUNK/32)
+	editor := NewEditor(WithStreamingLoad(true))
+	editor.WriteText([]byte(text))
+
+	editor.SetReadOnly(true)
+	if !editor.ReadOnly() {
+		t.Fatalf("Expected the content to be read-only while loading")
+	}
+	for editor.Loading() {
+		editor.runQueue()
+	}
+	if !editor.ReadOnly() {
+		t.Fatalf("Expected the content to stay read-only once loaded")
+	}
+}
@@ -0,0 +1,124 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "fmt"
+
+// Localizer translates the text of the editor itself, such as the bottom
+// bar, prompts, messages and announcements, so that it can be shown in
+// the language of the host.
+type Localizer interface {
+	// Translate returns the translation of the message, which is the
+	// English text, one of Messages. A message with verbs, such as "%d",
+	// is formatted with fmt.Sprintf, and its translation should have the
+	// same verbs, which may be reordered with "%[2]d" and so on. It returns
+	// false if there is no translation, and the English text is shown.
+	Translate(message string) (string, bool)
+}
+
+// Strings is a Localizer of a table of translations, keyed by the English
+// text of each message.
+type Strings map[string]string
+
+// Translate returns the translation in the table, if any.
+func (s Strings) Translate(message string) (string, bool) {
+	translation, ok := s[message]
+	return translation, ok && translation != ""
+}
+
+// Messages are the English text of the messages of the editor, which a
+// Localizer may translate.
+var Messages = []string{
+	"(x)cut (c)opy (v)paste (s)ave (q)uit (f)search [%v:%v:%v] ",
+	"(modified)",
+	"(%s) waiting for the next key of the chord...",
+	"(%s %s) is not a chord",
+	"regex",
+	"word",
+	" (invalid pattern)",
+	" (no matches)",
+	"align on: ",
+	"can't align on %q: %v",
+	"find all: ",
+	"%d matches of %q",
+	"search files: ",
+	"no newline at end of file",
+	"%d matches of %q in %d files",
+	"%d matches of %q in %d files, searching...",
+	"Open: ",
+	"Paste",
+	"Paste as plain text",
+	"save the changes before opening %s",
+	"line %d is read-only",
+	"loading",
+	"search",
+	"replace all",
+	"reindent",
+	"wait for %s to finish",
+	"%s: done",
+	"%s: %d%%...",
+	"line %d, column %d",
+	"inserted %s",
+	"deleted %s",
+	"new line",
+	"space",
+	"tab",
+	"checked",
+	"unchecked",
+	"%s mode",
+	"edit",
+	"prompt",
+	"select",
+	"following",
+	"paused: COMMAND-DOWN to follow",
+	"Welcome to noter! Press Escape to leave the tutorial.",
+	"Type to insert text.",
+	"checks or unchecks a task.",
+	"selects all.",
+	"cuts the selection.",
+	"pastes.",
+	"undoes.",
+	"deletes to the start of the line.",
+	"That's all! Your text is back as it was.",
+}
+
+// WithLocalizer sets the translations of the text of the editor itself.
+// If nil, or for messages without a translation, English is shown.
+func WithLocalizer(opt Localizer) EditorOption {
+	return func(e *Editor) {
+		e.localizer = opt
+	}
+}
+
+// tr returns the translation of the message, formatted with the args.
+func (e *Editor) tr(message string, args ...any) string {
+	if e.localizer != nil {
+		if translation, ok := e.localizer.Translate(message); ok {
+			message = translation
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,25 @@
+package noter
+
+import "testing"
+
+func TestWithLocalizer(t *testing.T) {
+	editor := NewEditor(WithLocalizer(Strings{
+		"line %d is read-only": "la ligne %d est en lecture seule",
+		"(modified)":           "",
+	}))
+	editor.WriteText([]byte("ab\n"))
+	editor.SetLinesReadOnly(0, 0, true)
+
+	editor.canEdit()
+	if notice, _ := editor.currentNotice(); notice != "la ligne 1 est en lecture seule" {
+		t.Fatalf("Expected a translated notice, got %q", notice)
+	}
+
+	// Messages without a translation fall back to English.
+	if got := editor.tr("(modified)"); got != "(modified)" {
+		t.Fatalf("Expected an empty translation to fall back, got %q", got)
+	}
+	if got := editor.tr("%s: done", "loading"); got != "loading: done" {
+		t.Fatalf("Expected a missing translation to fall back, got %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// customMode is a mode registered by a host or extension.
+type customMode struct {
+	name   string
+	update func(e *Editor) bool
+}
+
+// String returns the name of a built-in mode.
+func (m Mode) String() string {
+	switch m {
+	case EDIT_MODE:
+		return "edit"
+	case SEARCH_MODE:
+		return "search"
+	case PROMPT_MODE:
+		return "prompt"
+	}
+	return fmt.Sprintf("mode(%d)", uint(m))
+}
+
+// WithOnModeChange sets the function called when the mode changes.
+// The default is no action.
+func WithOnModeChange(opt func(from, to Mode)) EditorOption {
+	return func(e *Editor) {
+		if opt == nil {
+			opt = func(from, to Mode) {}
+		}
+		e.on_mode_change = opt
+	}
+}
+
+// WithModeCursorColor tints the cursor while in the mode, so that it's
+// clear where keystrokes go. Other modes use the WithCursorColor color.
+func WithModeCursorColor(m Mode, c color.Color) EditorOption {
+	return func(e *Editor) {
+		if e.mode_colors == nil {
+			e.mode_colors = make(map[Mode]color.Color)
+		}
+		e.mode_colors[m] = c
+	}
+}
+
+// cursorColor returns the color of the cursor in the current mode.
+// High-contrast colors take precedence over the mode colors.
+func (e *Editor) cursorColor() color.Color {
+	if c, ok := e.mode_colors[e.Mode()]; ok && !e.high_contrast {
+		return c
+	}
+	return e.cursor_color
+}
+
+// RegisterMode adds a custom mode, such as a "command" mode, returning the
+// Mode to pass to SetMode. While the mode is set, update is called on each
+// Update before the default EDIT_MODE key handling, and returns true if it
+// handled the input. Escape returns to EDIT_MODE.
+func (e *Editor) RegisterMode(name string, update func(e *Editor) bool) Mode {
+	e.customModes = append(e.customModes, customMode{name, update})
+	return customModeStart + Mode(len(e.customModes)-1)
+}
+
+// ModeName returns the name of a built-in or registered mode.
+func (e *Editor) ModeName(m Mode) string {
+	if c := e.lookupMode(m); c != nil {
+		return c.name
+	}
+	return m.String()
+}
+
+// Mode returns the current mode. The search and prompt modes take
+// precedence over a custom mode, which resumes when they end.
+func (e *Editor) Mode() Mode {
+	if e.mode == EDIT_MODE && e.customMode != EDIT_MODE {
+		return e.customMode
+	}
+	return e.mode
+}
+
+// SetMode changes the mode. Setting EDIT_MODE also ends any custom mode.
+// Setting PROMPT_MODE prompts for input which is discarded.
+func (e *Editor) SetMode(m Mode) {
+	switch m {
+	case EDIT_MODE:
+		e.editMode()
+		old := e.Mode()
+		e.customMode = EDIT_MODE
+		e.modeChanged(old)
+	case SEARCH_MODE:
+		e.searchMode()
+	case PROMPT_MODE:
+		e.promptMode("", nil)
+	default:
+		if e.lookupMode(m) == nil {
+			return
+		}
+		e.editMode()
+		old := e.Mode()
+		e.customMode = m
+		e.modeChanged(old)
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// lookupMode returns the registered mode, or nil.
+func (e *Editor) lookupMode(m Mode) *customMode {
+	if m < customModeStart || int(m-customModeStart) >= len(e.customModes) {
+		return nil
+	}
+	return &e.customModes[m-customModeStart]
+}
+
+// updateCustomMode lets the current custom mode handle the input first.
+// It returns true if the input was handled.
+func (e *Editor) updateCustomMode() bool {
+	if e.mode != EDIT_MODE {
+		return false
+	}
+	if c := e.lookupMode(e.customMode); c != nil {
+		return c.update(e)
+	}
+	return false
+}
+
+// modeChanged calls the mode change function, if the mode has changed.
+func (e *Editor) modeChanged(old Mode) {
+	if m := e.Mode(); m != old {
+		e.on_mode_change(old, m)
+		e.announce("%s mode", e.tr(e.ModeName(m)))
+	}
+}
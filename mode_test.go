@@ -0,0 +1,77 @@
+package noter
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCustomMode(t *testing.T) {
+	var changes []string
+	editor := NewEditor(WithOnModeChange(func(from, to Mode) {
+		changes = append(changes, from.String()+">"+to.String())
+	}))
+
+	updates := 0
+	command := editor.RegisterMode("command", func(e *Editor) bool {
+		updates++
+		return true
+	})
+	if editor.ModeName(command) != "command" {
+		t.Fatalf("Incorrect mode name, got: %v", editor.ModeName(command))
+	}
+
+	editor.SetMode(command)
+	editor.Update()
+	if editor.Mode() != command || updates != 1 {
+		t.Fatalf("Expected the custom mode to handle the update, got: %v, %v", editor.Mode(), updates)
+	}
+
+	// Search takes precedence, then the custom mode resumes.
+	editor.searchMode()
+	if editor.Mode() != SEARCH_MODE {
+		t.Fatalf("Expected search mode, got: %v", editor.Mode())
+	}
+	editor.editMode()
+	if editor.Mode() != command {
+		t.Fatalf("Expected the custom mode to resume, got: %v", editor.Mode())
+	}
+
+	editor.SetMode(EDIT_MODE)
+	want := []string{"edit>mode(3)", "mode(3)>search", "search>mode(3)", "mode(3)>edit"}
+	if len(changes) != len(want) {
+		t.Fatalf("Incorrect mode changes, expected %v, got: %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("Incorrect mode changes, expected %v, got: %v", want, changes)
+		}
+	}
+
+	// Leaving search for EDIT_MODE ends the custom mode after it resumes.
+	editor.SetMode(command)
+	editor.searchMode()
+	changes = nil
+	editor.SetMode(EDIT_MODE)
+	want = []string{"search>mode(3)", "mode(3)>edit"}
+	if len(changes) != len(want) {
+		t.Fatalf("Incorrect mode changes, expected %v, got: %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("Incorrect mode changes, expected %v, got: %v", want, changes)
+		}
+	}
+}
+
+func TestModeCursorColor(t *testing.T) {
+	searchColor := color.RGBA{0, 200, 0, 90}
+	editor := NewEditor(WithModeCursorColor(SEARCH_MODE, searchColor))
+
+	if editor.cursorColor() != editor.cursor_color {
+		t.Fatalf("Expected the default cursor color in edit mode")
+	}
+	editor.searchMode()
+	if editor.cursorColor() != searchColor {
+		t.Fatalf("Expected the search cursor color, got: %v", editor.cursorColor())
+	}
+}
@@ -0,0 +1,98 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ModifierPolicy declares which modifier key is treated as COMMAND.
+type ModifierPolicy uint
+
+const (
+	MODIFIER_META_OR_CONTROL ModifierPolicy = iota // Either Meta or Control (the default).
+	MODIFIER_META                                  // Meta only, leaving Control to the host.
+	MODIFIER_CONTROL                               // Control only, leaving Meta to the host.
+	MODIFIER_ALT                                   // Alt, which is then not used as OPTION.
+	MODIFIER_NONE                                  // No modifier; use function keys or Escape.
+)
+
+// escapeChord is the pending chord while waiting for a command key after
+// Escape, when Escape begins commands.
+const escapeChord = "esc"
+
+// WithModifierPolicy sets which modifier key is treated as COMMAND, for
+// hosts such as games which reserve the usual modifiers for themselves.
+func WithModifierPolicy(opt ModifierPolicy) EditorOption {
+	return func(e *Editor) {
+		e.modifier_policy = opt
+	}
+}
+
+// WithEscapeCommands lets Escape followed by a command key, such as Escape
+// then "s", run the command as if COMMAND were held. The key must follow
+// within EDITOR_CHORD_TIMEOUT. Escape still leaves the search and prompt
+// modes, and custom modes.
+func WithEscapeCommands(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.escape_commands = enabled
+	}
+}
+
+// BindFunctionKey binds a key, such as ebiten.KeyF2, to the command of a
+// COMMAND key, such as "s" to save. The key runs the command when pressed
+// without modifiers, taking precedence over its own action.
+func (e *Editor) BindFunctionKey(key ebiten.Key, command string) {
+	if e.functionKeys == nil {
+		e.functionKeys = make(map[ebiten.Key]string)
+	}
+	e.functionKeys[key] = command
+}
+
+// modifiers returns whether the COMMAND and OPTION modifiers are held,
+// according to the modifier policy.
+func (e *Editor) modifiers() (command, option bool) {
+	meta := ebiten.IsKeyPressed(ebiten.KeyMeta)
+	control := ebiten.IsKeyPressed(ebiten.KeyControl)
+	alt := ebiten.IsKeyPressed(ebiten.KeyAlt)
+
+	switch e.modifier_policy {
+	case MODIFIER_META:
+		return meta, alt
+	case MODIFIER_CONTROL:
+		return control, alt
+	case MODIFIER_ALT:
+		return alt, false
+	case MODIFIER_NONE:
+		return false, alt
+	}
+	return meta || control, alt
+}
+
+// beginEscapeCommand waits for a command key following Escape.
+func (e *Editor) beginEscapeCommand() {
+	e.pendingChord = escapeChord
+	e.chordUntil = time.Now().Add(EDITOR_CHORD_TIMEOUT)
+}
@@ -0,0 +1,39 @@
+package noter
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestRunCommand(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("abc\n"))
+	editor.BindFunctionKey(ebiten.KeyF2, "t")
+
+	editor.runCommand(editor.functionKeys[ebiten.KeyF2])
+	if !editor.table_mode {
+		t.Fatalf("Expected the bound command to toggle table mode")
+	}
+
+	editor.SetReadOnly(true)
+	editor.runCommand("u")
+	if got := string(editor.ReadText()); got != "abc\n" {
+		t.Fatalf("Expected editing commands to be ignored when read-only, got: %q", got)
+	}
+}
+
+func TestEscapeCommand(t *testing.T) {
+	editor := NewEditor(WithEscapeCommands(true))
+	editor.beginEscapeCommand()
+	if editor.PendingChord() != escapeChord {
+		t.Fatalf("Expected a pending escape command, got: %q", editor.PendingChord())
+	}
+
+	// A chord isn't completed by a key following Escape.
+	called := false
+	editor.BindChord("k", "c", func() { called = true })
+	if editor.handleChord("c") || called {
+		t.Fatalf("Expected the key not to be taken as a chord")
+	}
+}
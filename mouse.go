@@ -0,0 +1,204 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"golang.org/x/image/font"
+)
+
+// screenRow is a row of text drawn on the screen.
+type screenRow struct {
+	line       *editorLine
+	view       *lineView
+	start, end int  // display runes drawn.
+	last       bool // last row of the line.
+}
+
+// WithDragScrollSpeed sets how fast the view scrolls when selecting with
+// the mouse past the top or bottom edge of the text, in rows per second
+// for each row that the mouse is past the edge.
+// The default is EDITOR_DEFAULT_DRAG_SCROLL_SPEED.
+func WithDragScrollSpeed(speed float64) EditorOption {
+	return func(e *Editor) {
+		e.drag_speed = speed
+	}
+}
+
+// screenRowAt returns the row drawn nearest to a point on the screen.
+func (e *Editor) screenRowAt(py int) screenRow {
+	row := 0
+	if py > e.top_padding {
+		row = (py - e.top_padding) / e.font_info.yUnit
+	}
+	if row > len(e.screenRows)-1 {
+		row = len(e.screenRows) - 1
+	}
+	return e.screenRows[row]
+}
+
+// positionAt returns the text position nearest to a point on the screen.
+func (e *Editor) positionAt(px, py int) (line *editorLine, x int) {
+	sr := e.screenRowAt(py)
+
+	// The cursor can't be placed after the final rune of the row,
+	// which is either the new line or begins the next row.
+	limit := sr.end - 1
+	if sr.last {
+		limit = len(sr.view.runes) - 1
+	}
+
+	// Find the display rune whose center is after the point.
+	left := e.textLeft()
+	d := sr.start
+	for d < limit {
+		before := font.MeasureString(e.font_info.face, string(sr.view.runes[sr.start:d])).Floor()
+		after := font.MeasureString(e.font_info.face, string(sr.view.runes[sr.start:d+1])).Floor()
+		if px < left+(before+after)/2 {
+			break
+		}
+		d++
+	}
+	return sr.line, sr.view.position(d)
+}
+
+// dragScrollRate returns the rows to scroll each tick, when the mouse is
+// dragged the distance in pixels past the edge of the text. With reduced
+// motion, the rate is that of dragging a single row past the edge.
+func (e *Editor) dragScrollRate(distance int) float64 {
+	rows := float64(distance) / float64(e.font_info.yUnit)
+	if e.reduced_motion {
+		rows = 1
+	}
+	return e.drag_speed * rows / float64(ebiten.TPS())
+}
+
+// updateMouse moves the cursor when clicking the text, and selects text
+// when dragging, scrolling when dragged past the top or bottom edge.
+// It returns true if the mouse was handled.
+func (e *Editor) updateMouse() bool {
+	mx, my := ebiten.CursorPosition()
+	top := e.top_padding
+	bottom := top + e.rows*e.font_info.yUnit
+
+	// Right-click offers to paste as plain text.
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		if my < top || my >= bottom || e.mode != EDIT_MODE {
+			return false
+		}
+		e.showPasteMenu()
+		return true
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if my < top || my >= bottom || len(e.screenRows) == 0 {
+			return false
+		}
+		if e.clickScrollbar(mx, my) {
+			return true
+		}
+		e.editMode()
+
+		// Command-click adds a caret.
+		if command, _ := e.modifiers(); command {
+			line, x := e.positionAt(mx, my)
+			e.AddCaret(e.getLineNumberFromLine(line)-1, x)
+			return true
+		}
+
+		// Clicking a color swatch opens the color picker.
+		if e.clickSwatch(mx, my) {
+			return true
+		}
+
+		e.resetHighlight()
+		e.ClearCarets()
+		e.cursor.line, e.cursor.x = e.positionAt(mx, my)
+
+		// Clicking a task list checkbox toggles it.
+		if e.clickCheckbox(e.cursor.line, e.cursor.x) {
+			return true
+		}
+		e.dragAnchor = *e.cursor
+		e.dragging = true
+		e.dragScroll = 0
+		return true
+	}
+
+	if e.dragScrollbar(my, ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)) {
+		return true
+	}
+	if !e.dragging {
+		return false
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		e.dragging = false
+		return false
+	}
+
+	// Scroll faster the further the mouse is past the edge.
+	switch {
+	case my < top:
+		e.dragScroll -= e.dragScrollRate(top - my)
+	case my >= bottom:
+		e.dragScroll += e.dragScrollRate(my - bottom + 1)
+	}
+	if rows := int(e.dragScroll); rows != 0 {
+		e.dragScroll -= float64(rows)
+		e.scrollBy(rows)
+	}
+
+	e.cursor.line, e.cursor.x = e.positionAt(mx, my)
+	e.selectFromAnchor()
+	return true
+}
+
+// scrollBy scrolls the view by a number of lines, without moving the cursor.
+func (e *Editor) scrollBy(rows int) {
+	lines := e.getLineNumberFromLine(nil) - 1
+	e.firstVisible += rows
+	if e.firstVisible > lines-e.rows {
+		e.firstVisible = lines - e.rows
+	}
+	if e.firstVisible < 0 {
+		e.firstVisible = 0
+	}
+
+	// Update the rows on the screen.
+	e.updateImage()
+}
+
+// selectFromAnchor selects the text between the drag anchor and the cursor.
+func (e *Editor) selectFromAnchor() {
+	e.resetHighlight()
+
+	anchor := e.dragAnchor
+	anchorRow := e.getLineNumberFromLine(anchor.line)
+	cursorRow := e.getLineNumberFromLine(e.cursor.line)
+	if anchorRow < cursorRow || (anchorRow == cursorRow && anchor.x < e.cursor.x) {
+		e.highlightBetween(anchor.line, anchor.x, e.cursor.line, e.cursor.x)
+	} else {
+		e.highlightBetween(e.cursor.line, e.cursor.x, anchor.line, anchor.x)
+	}
+}
@@ -0,0 +1,50 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestPositionAt(t *testing.T) {
+	editor := NewEditor(WithRows(2))
+	editor.WriteText([]byte("hello\nworld\nagain\n"))
+
+	xUnit, yUnit := editor.font_info.xUnit, editor.font_info.yUnit
+	left, top := editor.textLeft(), editor.top_padding
+
+	table := [](struct {
+		px, py int
+		row    int
+		x      int
+	}){
+		{left, top, 0, 0},
+		{left + xUnit*2 + xUnit/2 + 1, top, 0, 3},
+		{left + xUnit*100, top + yUnit, 1, 5},
+		{left + xUnit, top + yUnit*5, 1, 1}, // Below the text is the last row.
+		{0, 0, 0, 0},                        // Above the text is the first row.
+	}
+
+	for _, entry := range table {
+		line, x := editor.positionAt(entry.px, entry.py)
+		if row := editor.getLineNumberFromLine(line) - 1; row != entry.row || x != entry.x {
+			t.Fatalf("Incorrect position at %v,%v, expected %v:%v, got %v:%v", entry.px, entry.py, entry.row, entry.x, row, x)
+		}
+	}
+}
+
+func TestSelectFromAnchor(t *testing.T) {
+	editor := NewEditor(WithRows(2))
+	editor.WriteText([]byte("hello\nworld\n"))
+
+	editor.dragAnchor = editorCursor{editor.start.next, 2}
+	editor.cursor.line, editor.cursor.x = editor.start, 3
+	editor.selectFromAnchor()
+	if got := string(editor.getHighlightedRunes()); got != "lo\nwo" {
+		t.Fatalf("Incorrect drag selection, got: %q", got)
+	}
+
+	// Scrolling is clamped to the last page.
+	editor.scrollBy(10)
+	if editor.firstVisible != 1 {
+		t.Fatalf("Incorrect scroll, expected first visible 1, got: %v", editor.firstVisible)
+	}
+}
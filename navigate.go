@@ -0,0 +1,221 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// Search finds the term in the content, as if typed into the search bar,
+// and moves to the first match.
+func (e *Editor) Search(term string) bool {
+	if e.mode != SEARCH_MODE {
+		e.searchMode()
+	}
+	e.searchTerm = []rune(term)
+	e.input = newLineInput(&e.searchTerm)
+	e.searchIndex = 0
+	return e.gotoSearchIndex()
+}
+
+// GotoMatch moves the cursor to the nth match, counting from zero, of the
+// current search term, and centers it in the view. It returns false if
+// there is no such match.
+func (e *Editor) GotoMatch(n int) bool {
+	if n < 0 || n >= e.searchMatches {
+		return false
+	}
+	e.searchIndex = n
+	return e.gotoSearchIndex()
+}
+
+// NextMatch moves to the next match of the current search term, wrapping
+// around to the first match. It returns false if there are no matches.
+func (e *Editor) NextMatch() bool {
+	e.searchIndex++
+	return e.gotoSearchIndex()
+}
+
+// PrevMatch moves to the previous match of the current search term, wrapping
+// around to the last match. It returns false if there are no matches.
+func (e *Editor) PrevMatch() bool {
+	if e.searchIndex > -1 {
+		e.searchIndex--
+	}
+	return e.gotoSearchIndex()
+}
+
+// MatchIndex returns the index of the current match of the search term.
+func (e *Editor) MatchIndex() int {
+	return e.searchIndex
+}
+
+// gotoSearchIndex searches, moving to the match at the search index.
+func (e *Editor) gotoSearchIndex() bool {
+	e.runSearch(false)
+	if e.searchMatches == 0 {
+		// Update the backing image.
+		e.updateImage()
+		return false
+	}
+	e.CenterOn(e.getLineNumber())
+	return true
+}
+
+// CenterOn scrolls the view so that the row is in the middle, as far as
+// possible. The cursor isn't moved.
+func (e *Editor) CenterOn(row int) {
+	// Count the lines, by numbering the line after the last.
+	lines := e.getLineNumberFromLine(nil) - 1
+	if row > lines-1 {
+		row = lines - 1
+	}
+
+	e.firstVisible = row - e.rows/2
+	if e.firstVisible < 0 {
+		e.firstVisible = 0
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// Option scanning finds the next emptyType after hitting a non-emptyType
+// TODO: the characters that we filter for needs improving
+var emptyTypes = map[rune]bool{' ': true, '.': true, ',': true}
+
+// moveWordRight moves the cursor to the end of the word, as does
+// OPTION-RIGHT, selecting as it goes if shift.
+func (e *Editor) moveWordRight(shift bool) {
+	// Find the next empty
+	for e.cursor.x < len(e.cursor.line.values)-2 {
+		if shift {
+			e.highlight(e.cursor.line, e.cursor.x)
+		}
+		e.cursor.x++
+		if ok := emptyTypes[e.cursor.line.values[e.cursor.x]]; !ok {
+		} else {
+			break
+		}
+		if shift {
+			e.highlight(e.cursor.line, e.cursor.x)
+		}
+	}
+}
+
+// moveWordLeft moves the cursor to the start of the word, as does
+// OPTION-LEFT, selecting as it goes if shift.
+func (e *Editor) moveWordLeft(shift bool) {
+	// Find the next non-empty
+	for e.cursor.x > 0 {
+		e.cursor.x--
+		if shift {
+			e.highlight(e.cursor.line, e.cursor.x)
+		}
+		if ok := emptyTypes[e.cursor.line.values[e.cursor.x]]; !ok {
+			break
+		}
+	}
+
+	// Find the next empty
+	for e.cursor.x > 0 {
+		if ok := emptyTypes[e.cursor.line.values[e.cursor.x-1]]; !ok {
+			if shift {
+				e.highlight(e.cursor.line, e.cursor.x)
+			}
+		} else {
+			break
+		}
+		e.cursor.x--
+		if shift {
+			e.highlight(e.cursor.line, e.cursor.x)
+		}
+	}
+}
+
+// moveLineEnd moves the cursor to the end of the line, as does
+// COMMAND-RIGHT or End, selecting as it goes if shift.
+func (e *Editor) moveLineEnd(shift bool) {
+	for e.cursor.x < len(e.cursor.line.values)-1 {
+		if shift {
+			e.highlight(e.cursor.line, e.cursor.x)
+		}
+		e.cursor.x++
+	}
+}
+
+// moveLineStart moves the cursor to the start of the line, as does
+// COMMAND-LEFT or Home, selecting as it goes if shift.
+func (e *Editor) moveLineStart(shift bool) {
+	for e.cursor.x > 0 {
+		e.cursor.x--
+		if shift {
+			e.highlight(e.cursor.line, e.cursor.x)
+		}
+	}
+}
+
+// Position is a location in the content, counting rows and columns from zero.
+type Position struct {
+	Row, Col int
+}
+
+// TryMoveCursor moves the cursor to the position, clamped to the nearest
+// valid position. It returns the position moved to, and false if the
+// position had to be clamped.
+func (e *Editor) TryMoveCursor(row, col int) (Position, bool) {
+	pos := Position{row, col}
+
+	lines := e.getLineNumberFromLine(nil) - 1
+	if pos.Row > lines-1 {
+		pos.Row = lines - 1
+	}
+	if pos.Row < 0 {
+		pos.Row = 0
+	}
+
+	line := e.lineAt(pos.Row)
+	if pos.Col > len(line.values)-1 {
+		pos.Col = len(line.values) - 1
+	}
+	if pos.Col < 0 {
+		pos.Col = 0
+	}
+
+	e.resetHighlight()
+	e.MoveCursor(pos.Row, pos.Col)
+
+	// Update the backing image.
+	e.updateImage()
+	return pos, pos.Row == row && pos.Col == col
+}
+
+// ViewInfo describes the visible part of the content, as last rendered.
+type ViewInfo struct {
+	FirstLine        int // first visible line, counting from zero.
+	LastLine         int // last visible line, which may be partly visible.
+	HorizontalOffset int // display columns scrolled past on the cursor line.
+	Columns          int // visible columns of text.
+}
+
+// ViewInfo returns the visible part of the content, so that a host can
+// draw companions in sync with it, such as a blame column.
+func (e *Editor) ViewInfo() ViewInfo {
+	return e.viewInfo
+}
@@ -0,0 +1,122 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGotoMatch(t *testing.T) {
+	editor := NewEditor(WithRows(4))
+	editor.WriteText([]byte("a\nfoo\nb\nc\nd\ne\nfoo\nf\ng\nh\n"))
+
+	if !editor.Search("foo") || editor.MatchCount() != 2 {
+		t.Fatalf("Expected two matches, got %d", editor.MatchCount())
+	}
+
+	table := [](struct {
+		move         func() bool
+		row          int
+		firstVisible int
+	}){
+		{func() bool { return editor.GotoMatch(1) }, 6, 4},
+		{editor.NextMatch, 1, 0},
+		{editor.PrevMatch, 6, 4},
+		{editor.PrevMatch, 1, 0},
+	}
+
+	for i, entry := range table {
+		if !entry.move() {
+			t.Fatalf("Expected move %d to find a match", i)
+		}
+		if row, _ := editor.Cursor(); row != entry.row || editor.firstVisible != entry.firstVisible {
+			t.Fatalf("Incorrect move %d, expected row %d first visible %d, got %d %d",
+				i, entry.row, entry.firstVisible, row, editor.firstVisible)
+		}
+	}
+
+	if editor.GotoMatch(2) {
+		t.Fatalf("Expected no third match")
+	}
+}
+
+func TestCenterOn(t *testing.T) {
+	editor := NewEditor(WithRows(4))
+	editor.WriteText([]byte("a\nb\nc\nd\ne\nf\n"))
+
+	for _, entry := range [](struct{ row, firstVisible int }){
+		{0, 0},
+		{3, 1},
+		{100, 4},
+	} {
+		editor.CenterOn(entry.row)
+		if editor.firstVisible != entry.firstVisible {
+			t.Fatalf("Incorrect centering on %d, expected %d, got %d", entry.row, entry.firstVisible, editor.firstVisible)
+		}
+	}
+}
+
+func TestTryMoveCursor(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one\ntwo\n"))
+
+	table := [](struct {
+		row, col int
+		want     Position
+		ok       bool
+	}){
+		{1, 2, Position{1, 2}, true},
+		{1, 3, Position{1, 3}, true},
+		{0, 10, Position{0, 3}, false},
+		{10, 1, Position{2, 0}, false},
+		{-1, -1, Position{0, 0}, false},
+	}
+
+	for _, entry := range table {
+		pos, ok := editor.TryMoveCursor(entry.row, entry.col)
+		if pos != entry.want || ok != entry.ok {
+			t.Fatalf("Incorrect move to %v:%v, expected %v %v, got %v %v", entry.row, entry.col, entry.want, entry.ok, pos, ok)
+		}
+		if row, col := editor.Cursor(); row != pos.Row || col != pos.Col {
+			t.Fatalf("Expected the cursor at %v, got %v:%v", pos, row, col)
+		}
+	}
+}
+
+func TestViewInfo(t *testing.T) {
+	editor := NewEditor(WithRows(3), WithColumns(10))
+	editor.WriteText([]byte("a\nb\nc\nd\ne\n" + strings.Repeat("x", 25) + "\n"))
+
+	editor.MoveCursor(5, 20)
+	editor.updateImage()
+
+	want := ViewInfo{FirstLine: 3, LastLine: 5, HorizontalOffset: 20, Columns: 10}
+	if got := editor.ViewInfo(); got != want {
+		t.Fatalf("Incorrect view info, expected %+v, got: %+v", want, got)
+	}
+}
+
+func TestSearchStatus(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a b a b a\n"))
+
+	table := [](struct {
+		move   func() bool
+		status string
+	}){
+		{func() bool { return editor.Search("a") }, " (1/3)"},
+		{editor.NextMatch, " (2/3)"},
+		{editor.NextMatch, " (3/3)"},
+		{editor.NextMatch, " (1/3)"},
+		{editor.PrevMatch, " (3/3)"},
+		{func() bool { return !editor.Search("c") }, " (no matches)"},
+	}
+
+	for i, entry := range table {
+		if !entry.move() {
+			t.Fatalf("Unexpected result of move %v", i)
+		}
+		if status, _ := editor.searchStatus(); status != entry.status {
+			t.Fatalf("Incorrect status after move %v, expected %q, got %q", i, entry.status, status)
+		}
+	}
+}
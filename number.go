@@ -0,0 +1,169 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// numberPattern matches a hexadecimal or decimal number, such as "0x1F",
+// "12" or "0.25".
+var numberPattern = regexp.MustCompile(`0[xX][0-9a-fA-F]+|[0-9]*\.?[0-9]+`)
+
+// WithNumberStep sets the amount by which IncrementNumber and
+// DecrementNumber change a number. The default is
+// EDITOR_DEFAULT_NUMBER_STEP.
+func WithNumberStep(step float64) EditorOption {
+	return func(e *Editor) {
+		if step <= 0 || math.IsInf(step, 0) || math.IsNaN(step) {
+			step = EDITOR_DEFAULT_NUMBER_STEP
+		}
+		e.number_step = step
+	}
+}
+
+// numberAt returns the number touching position x of the line, as the
+// runes [start, end), including a leading minus sign.
+func numberAt(values []rune, x int) (start, end int, ok bool) {
+	s := string(values)
+	for _, loc := range numberPattern.FindAllStringIndex(s, -1) {
+		start = utf8.RuneCountInString(s[:loc[0]])
+		end = start + utf8.RuneCountInString(s[loc[0]:loc[1]])
+		if x < start {
+			break
+		}
+		if x > end {
+			continue
+		}
+
+		// A minus sign, unless it follows a word, as in "x-1".
+		if start > 0 && values[start-1] == '-' &&
+			(start == 1 || !(unicode.IsLetter(values[start-2]) || unicode.IsDigit(values[start-2]))) {
+			start--
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// decimals returns the number of digits after the decimal point.
+func decimals(number string) int {
+	if _, fraction, ok := strings.Cut(number, "."); ok {
+		return len(fraction)
+	}
+	return 0
+}
+
+// stepNumber returns the number changed by delta. Hexadecimal numbers keep
+// their case and width, and decimals keep their precision, or that of
+// delta if greater.
+func stepNumber(number string, delta float64) (string, bool) {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	if len(number) > 2 && (number[:2] == "0x" || number[:2] == "0X") {
+		digits := number[2:]
+		v, err := strconv.ParseInt(sign+digits, 16, 64)
+		if err != nil {
+			return "", false
+		}
+		step := int64(math.Round(delta))
+		if step == 0 {
+			step = 1
+			if delta < 0 {
+				step = -1
+			}
+		}
+		v += step
+
+		sign = ""
+		if v < 0 {
+			sign, v = "-", -v
+		}
+		out := strconv.FormatInt(v, 16)
+		if strings.ToUpper(digits) == digits {
+			out = strings.ToUpper(out)
+		}
+		if len(out) < len(digits) {
+			out = strings.Repeat("0", len(digits)-len(out)) + out
+		}
+		return sign + number[:2] + out, true
+	}
+
+	precision := decimals(number)
+	if d := decimals(strconv.FormatFloat(math.Abs(delta), 'f', -1, 64)); d > precision {
+		precision = d
+	}
+	if precision == 0 {
+		v, err := strconv.ParseInt(sign+number, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(v+int64(delta), 10), true
+	}
+	v, err := strconv.ParseFloat(sign+number, 64)
+	if err != nil {
+		return "", false
+	}
+	out := strconv.FormatFloat(v+delta, 'f', precision, 64)
+	if strings.Trim(out, "-0.") == "" {
+		// Not "-0.0".
+		out = strings.TrimPrefix(out, "-")
+	}
+	return out, true
+}
+
+// fnStepNumber changes the number at the cursor by the step of
+// WithNumberStep, up (dir > 0) or down (dir < 0). The cursor stays within
+// the number.
+func (e *Editor) fnStepNumber(dir int) func() bool {
+	values := e.cursor.line.values
+	start, end, ok := numberAt(values, e.cursor.x)
+	if !ok {
+		return noop
+	}
+	number, ok := stepNumber(string(values[start:end]), float64(dir)*e.number_step)
+	if !ok {
+		return noop
+	}
+
+	line := make([]rune, 0, len(values))
+	line = append(line, values[:start]...)
+	line = append(line, []rune(number)...)
+	line = append(line, values[end:]...)
+
+	x := e.cursor.x
+	if limit := start + utf8.RuneCountInString(number); x > limit {
+		x = limit
+	}
+	undo := e.fnReplaceLines(e.getLineNumber(), 1, [][]rune{line})
+	e.cursor.x = x
+	return undo
+}
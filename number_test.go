@@ -0,0 +1,79 @@
+package noter
+
+import "testing"
+
+func TestStepNumber(t *testing.T) {
+	table := [](struct {
+		number string
+		delta  float64
+		want   string
+	}){
+		{"9", 1, "10"},
+		{"0", -1, "-1"},
+		{"-1", 1, "0"},
+		{"0.25", 1, "1.25"},
+		{"1", 0.5, "1.5"},
+		{"0.1", -0.1, "0.0"},
+		{"0x0f", 1, "0x10"},
+		{"0x00FF", -1, "0x00FE"},
+	}
+
+	for _, entry := range table {
+		got, ok := stepNumber(entry.number, entry.delta)
+		if !ok || got != entry.want {
+			t.Fatalf("Incorrect step of %q by %v, expected %q, got %q", entry.number, entry.delta, entry.want, got)
+		}
+	}
+}
+
+func TestIncrementNumber(t *testing.T) {
+	editor := NewEditor(WithNumberStep(10))
+	editor.WriteText([]byte("speed = -5 # x-15\n"))
+	editor.MoveCursor(0, 10)
+
+	editor.RunCommand("IncrementNumber")
+	if got := string(editor.ReadText()); got != "speed = 5 # x-15\n" {
+		t.Fatalf("Expected the number to be incremented, got: %q", got)
+	}
+	if _, col := editor.Cursor(); col != 9 {
+		t.Fatalf("Expected the cursor to stay within the number, got column %d", col)
+	}
+
+	editor.MoveCursor(0, 14)
+	editor.RunCommand("DecrementNumber")
+	if got := string(editor.ReadText()); got != "speed = 5 # x-5\n" {
+		t.Fatalf("Expected the number to be decremented, got: %q", got)
+	}
+
+	editor.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != "speed = 5 # x-15\n" {
+		t.Fatalf("Expected the decrement to be undone, got: %q", got)
+	}
+}
+
+func TestStepNumberBindings(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("1\n2\n"))
+	editor.MoveCursor(0, 0)
+
+	if !editor.runKeystroke("alt+pageup") || string(editor.ReadText()) != "2\n2\n" {
+		t.Fatalf("Expected OPTION-PAGEUP to increment the number, got %q", editor.ReadText())
+	}
+	if !editor.runKeystroke("alt+pagedown") || string(editor.ReadText()) != "1\n2\n" {
+		t.Fatalf("Expected OPTION-PAGEDOWN to decrement the number, got %q", editor.ReadText())
+	}
+
+	// COMMAND-UP and COMMAND-DOWN are left to move to the first and last
+	// line, under each modifier policy.
+	for _, policy := range []ModifierPolicy{MODIFIER_META_OR_CONTROL, MODIFIER_CONTROL, MODIFIER_META} {
+		WithModifierPolicy(policy)(editor)
+		for _, keystroke := range []string{"ctrl+arrowup", "ctrl+arrowdown", "meta+arrowup", "meta+arrowdown"} {
+			if editor.runKeystroke(keystroke) {
+				t.Fatalf("Expected %q to move the cursor, not run a command", keystroke)
+			}
+		}
+	}
+	if got := string(editor.ReadText()); got != "1\n2\n" {
+		t.Fatalf("Expected the numbers to be unchanged, got %q", got)
+	}
+}
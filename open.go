@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseAddress splits an address such as "notes.txt:12:5", as used in
+// compiler messages and by other tools, into the content name and the
+// position it addresses. The line and column count from one, and either
+// may be omitted. The position returned counts from zero.
+func ParseAddress(address string) (name string, pos Position) {
+	var numbers []int
+	for len(numbers) < 2 {
+		i := strings.LastIndexByte(address, ':')
+		if i < 0 {
+			break
+		}
+		n, err := strconv.Atoi(address[i+1:])
+		if err != nil || n < 1 {
+			break
+		}
+		numbers = append([]int{n - 1}, numbers...)
+		address = address[:i]
+	}
+
+	switch len(numbers) {
+	case 1:
+		pos.Row = numbers[0]
+	case 2:
+		pos.Row, pos.Col = numbers[0], numbers[1]
+	}
+	return address, pos
+}
+
+// OpenAt loads the content, named name, and moves the cursor to the row
+// and column, clamped to the content, centering it in the view. With
+// WithStreamingLoad, a row which hasn't loaded yet is moved to once it
+// has.
+func (e *Editor) OpenAt(content Content, name string, row, col int) {
+	e.SetContent(content)
+	e.SetContentName(name)
+	e.Load()
+
+	e.openAt, e.openEnd = &Position{row, col}, 0
+	e.moveToOpen()
+}
+
+// moveToOpen moves the cursor to the position passed to OpenAt, and
+// centers it, once its row has loaded or there's no more to load. A match
+// from the position up to openEnd is highlighted.
+func (e *Editor) moveToOpen() {
+	if e.openAt == nil || e.openAt.Row >= e.lineCount()-1 && len(e.unloaded) > 0 {
+		return
+	}
+	if e.openEnd > e.openAt.Col {
+		e.showMatch(e.openAt.Row, e.openAt.Col, e.openEnd)
+	} else {
+		pos, _ := e.TryMoveCursor(e.openAt.Row, e.openAt.Col)
+		e.CenterOn(pos.Row)
+	}
+	e.openAt, e.openEnd = nil, 0
+}
@@ -0,0 +1,56 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	table := [](struct {
+		address string
+		name    string
+		pos     Position
+	}){
+		{"notes.txt", "notes.txt", Position{0, 0}},
+		{"notes.txt:12", "notes.txt", Position{11, 0}},
+		{"notes.txt:12:5", "notes.txt", Position{11, 4}},
+		{"a:b.txt:3", "a:b.txt", Position{2, 0}},
+		{"notes.txt:0", "notes.txt:0", Position{0, 0}},
+		{"notes.txt:", "notes.txt:", Position{0, 0}},
+	}
+
+	for _, entry := range table {
+		name, pos := ParseAddress(entry.address)
+		if name != entry.name || pos != entry.pos {
+			t.Fatalf("Incorrect parse of %q, expected %q %v, got %q %v", entry.address, entry.name, entry.pos, name, pos)
+		}
+	}
+}
+
+func TestOpenAt(t *testing.T) {
+	editor := NewEditor()
+	editor.OpenAt(&dummyContent{"ab\ncdef\n"}, "notes.txt", 1, 2)
+	if row, col := editor.Cursor(); row != 1 || col != 2 || editor.ContentName() != "notes.txt" {
+		t.Fatalf("Expected to open at (1,2), got (%v,%v)", row, col)
+	}
+
+	editor.OpenAt(&dummyContent{"ab\n"}, "notes.txt", 9, 9)
+	if row, col := editor.Cursor(); row != 1 || col != 0 {
+		t.Fatalf("Expected the position to be clamped, got (%v,%v)", row, col)
+	}
+
+	// A row which hasn't loaded yet is moved to once it has.
+	lines := 3 * EDITOR_LOAD_CHUNK / 32
+	text := strings.Repeat("a line of a very large log file\n", lines)
+	editor = NewEditor(WithStreamingLoad(true))
+	editor.OpenAt(&dummyContent{text}, "log.txt", lines-1, 5)
+	for editor.Loading() {
+		if row, _ := editor.Cursor(); row != 0 || editor.firstVisible != 0 {
+			t.Fatalf("Expected to stay at the top until the row has loaded, got row %v", row)
+		}
+		editor.runQueue()
+	}
+	if row, col := editor.Cursor(); row != lines-1 || col != 5 {
+		t.Fatalf("Expected to open at (%v,5) once loaded, got (%v,%v)", lines-1, row, col)
+	}
+}
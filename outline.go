@@ -0,0 +1,118 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"strings"
+	"time"
+)
+
+// Section is a Markdown heading of the content, with the text up to the
+// next heading.
+type Section struct {
+	Heading string // the text of the heading, without the '#' marks.
+	Level   int    // 1 for "#" to 6 for "######", or 0 before the first heading.
+	Row     int    // the row of the heading.
+	Words   int    // the number of words following the heading.
+
+	// Modified is the time the section was last edited, or zero if it
+	// hasn't been since the content was written.
+	Modified time.Time
+}
+
+// Outline returns the sections of the content, in order, such as for a
+// sidebar of the headings of a note. Any text before the first heading
+// is a section of level 0. Lines within fenced code blocks aren't headings.
+func (e *Editor) Outline() (sections []Section) {
+	var section *Section
+	fence := ""
+	row := 0
+	for line := e.start; line != nil; line, row = line.next, row+1 {
+		text := strings.TrimSuffix(string(line.values), "\n")
+
+		if marker := codeFence(text); marker != "" && (fence == "" || marker == fence) {
+			if fence == "" {
+				fence = marker
+			} else {
+				fence = ""
+			}
+		} else if level, heading := atxHeading(text); level > 0 && fence == "" {
+			sections = append(sections, Section{Heading: heading, Level: level, Row: row})
+			section = &sections[len(sections)-1]
+			section.Modified = line.edited
+			continue
+		}
+
+		words := len(strings.Fields(text))
+		if section == nil {
+			if words == 0 {
+				continue
+			}
+			sections = append(sections, Section{})
+			section = &sections[len(sections)-1]
+		}
+		section.Words += words
+		if line.edited.After(section.Modified) {
+			section.Modified = line.edited
+		}
+	}
+	return sections
+}
+
+// atxHeading returns the level and text of a heading such as "## Notes",
+// or a level of 0 if the line isn't a heading.
+func atxHeading(text string) (level int, heading string) {
+	trimmed := strings.TrimLeft(text, " ")
+	if len(text)-len(trimmed) > 3 {
+		return 0, ""
+	}
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, ""
+	}
+
+	// Closing '#' marks are not part of the heading.
+	heading = strings.TrimSpace(rest)
+	if closed := strings.TrimRight(heading, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+		heading = strings.TrimSpace(closed)
+	}
+	return level, heading
+}
+
+// codeFence returns the marker of a line which opens or closes a fenced
+// code block, "```" or "~~~", or an empty string.
+func codeFence(text string) string {
+	trimmed := strings.TrimLeft(text, " ")
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			return marker
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOutline(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("intro words\n# Title #\none two three\n```\n# not a heading\n```\n## C#\n\n###no\n####### seven\n"))
+
+	expected := []Section{
+		{Heading: "", Level: 0, Row: 0, Words: 2},
+		{Heading: "Title", Level: 1, Row: 1, Words: 9},
+		{Heading: "C#", Level: 2, Row: 6, Words: 3},
+	}
+	if sections := editor.Outline(); !reflect.DeepEqual(sections, expected) {
+		t.Fatalf("Incorrect outline, expected %+v, got %+v", expected, sections)
+	}
+}
+
+func TestOutlineModified(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("# One\na\n# Two\nb\n"))
+
+	before := time.Now()
+	editor.MoveCursor(3, 1)
+	editor.handleRune('c')
+
+	sections := editor.Outline()
+	if !sections[0].Modified.IsZero() {
+		t.Fatalf("Expected the first section to be unmodified, got %v", sections[0].Modified)
+	}
+	if sections[1].Modified.Before(before) {
+		t.Fatalf("Expected the second section to be modified, got %v", sections[1].Modified)
+	}
+}
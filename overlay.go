@@ -0,0 +1,128 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Overlay is a transient element drawn over the editor, such as a popup,
+// a menu or an input bar. Overlays are kept in a stack: the topmost overlay
+// is offered input first, and Escape (or a click outside of its bounds)
+// dismisses the topmost overlay before any other.
+type Overlay interface {
+	// Bounds returns the area of the editor image covered by the overlay.
+	Bounds(e *Editor) image.Rectangle
+	// Update handles input, returning true if the editor should ignore it.
+	Update(e *Editor) bool
+	// Draw renders the overlay over the editor image.
+	Draw(e *Editor, screen *ebiten.Image)
+	// Dismiss is called once the overlay has been removed from the stack.
+	Dismiss(e *Editor)
+}
+
+// PushOverlay shows the overlay above all others.
+func (e *Editor) PushOverlay(o Overlay) {
+	e.overlays = append(e.overlays, o)
+	e.Invalidate()
+}
+
+// TopOverlay returns the topmost overlay, or nil if there are none.
+func (e *Editor) TopOverlay() Overlay {
+	if len(e.overlays) == 0 {
+		return nil
+	}
+	return e.overlays[len(e.overlays)-1]
+}
+
+// DismissOverlay removes the topmost overlay. It returns false if there
+// were no overlays to dismiss.
+func (e *Editor) DismissOverlay() bool {
+	o := e.TopOverlay()
+	if o == nil {
+		return false
+	}
+	e.overlays = e.overlays[:len(e.overlays)-1]
+	o.Dismiss(e)
+	e.Invalidate()
+	return true
+}
+
+// RemoveOverlay removes the overlay, wherever it is in the stack,
+// without dismissing it.
+func (e *Editor) RemoveOverlay(o Overlay) {
+	for i := range e.overlays {
+		if e.overlays[i] == o {
+			e.overlays = append(e.overlays[:i], e.overlays[i+1:]...)
+			e.Invalidate()
+			return
+		}
+	}
+}
+
+// updateOverlays routes input to the topmost overlay. It returns true if
+// the input was consumed, and the editor should not handle it.
+func (e *Editor) updateOverlays() bool {
+	o := e.TopOverlay()
+	if o == nil {
+		return false
+	}
+
+	// Clicking outside of the topmost overlay dismisses it.
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if !image.Pt(ebiten.CursorPosition()).In(o.Bounds(e)) {
+			e.DismissOverlay()
+			return true
+		}
+	}
+
+	return o.Update(e)
+}
+
+// drawOverlays renders the overlays, from the bottom of the stack up.
+func (e *Editor) drawOverlays() {
+	for _, o := range e.overlays {
+		o.Draw(e, e.screen)
+	}
+}
+
+// modeOverlay is the input bar of the SEARCH_MODE and PROMPT_MODE modes,
+// which is drawn in the top bar. Input is handled by the editor itself.
+type modeOverlay struct{}
+
+func (o *modeOverlay) Bounds(e *Editor) image.Rectangle {
+	return image.Rect(0, 0, e.width, e.top_padding)
+}
+
+func (o *modeOverlay) Update(e *Editor) bool {
+	return false
+}
+
+func (o *modeOverlay) Draw(e *Editor, screen *ebiten.Image) {}
+
+func (o *modeOverlay) Dismiss(e *Editor) {
+	e.editMode()
+}
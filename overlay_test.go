@@ -0,0 +1,55 @@
+package noter
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type testOverlay struct {
+	dismissed bool
+}
+
+func (o *testOverlay) Bounds(e *Editor) image.Rectangle     { return image.Rect(0, 0, 10, 10) }
+func (o *testOverlay) Update(e *Editor) bool                { return true }
+func (o *testOverlay) Draw(e *Editor, screen *ebiten.Image) {}
+func (o *testOverlay) Dismiss(e *Editor)                    { o.dismissed = true }
+
+func TestDismissOverlayOrder(t *testing.T) {
+	editor := NewEditor()
+
+	editor.searchMode()
+	popup := &testOverlay{}
+	editor.PushOverlay(popup)
+
+	// The popup is above the search bar, so is dismissed first.
+	if !editor.DismissOverlay() || !popup.dismissed {
+		t.Fatalf("Expected the topmost overlay to be dismissed")
+	}
+	if editor.mode != SEARCH_MODE {
+		t.Fatalf("Expected to remain in search mode")
+	}
+
+	if !editor.DismissOverlay() || editor.mode != EDIT_MODE {
+		t.Fatalf("Expected the search bar to be dismissed")
+	}
+	if editor.DismissOverlay() {
+		t.Fatalf("Expected no overlays to remain")
+	}
+}
+
+func TestEditModeRemovesModeOverlay(t *testing.T) {
+	editor := NewEditor()
+
+	editor.promptMode("test: ", nil)
+	editor.searchMode()
+	if len(editor.overlays) != 1 {
+		t.Fatalf("Expected a single mode overlay, got: %v", len(editor.overlays))
+	}
+
+	editor.editMode()
+	if editor.TopOverlay() != nil {
+		t.Fatalf("Expected the mode overlay to be removed")
+	}
+}
@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// ImageContent is an optional interface for a clipboard which can also hold
+// images. The encoding of the image (such as PNG) is up to the clipboard.
+type ImageContent interface {
+	ReadImage() []byte // Read the image on the clipboard, if any.
+}
+
+// WithOnPasteImage sets the function called when pasting while the clipboard
+// holds an image rather than text. It may store the image, and returns the
+// text to insert in its place, such as a markdown link. The clipboard must
+// implement ImageContent.
+func WithOnPasteImage(opt func(image []byte) string) EditorOption {
+	return func(e *Editor) {
+		e.on_paste_image = opt
+	}
+}
+
+// plainRunes maps the typographic runes of rich text, which look alike
+// but break config files and code, to their plain ASCII equivalents.
+// Zero-width runes map to -1, and are removed.
+var plainRunes = map[rune]rune{
+	'\u2018': '\'', // left single quote
+	'\u2019': '\'', // right single quote
+	'\u201A': '\'', // single low quote
+	'\u201B': '\'', // single high reversed quote
+	'\u2032': '\'', // prime
+	'\u201C': '"',  // left double quote
+	'\u201D': '"',  // right double quote
+	'\u201E': '"',  // double low quote
+	'\u201F': '"',  // double high reversed quote
+	'\u2033': '"',  // double prime
+	'\u00A0': ' ',  // no-break space
+	'\u2007': ' ',  // figure space
+	'\u202F': ' ',  // narrow no-break space
+	'\u00AD': -1,   // soft hyphen
+	'\u200B': -1,   // zero-width space
+	'\u200C': -1,   // zero-width non-joiner
+	'\u200D': -1,   // zero-width joiner
+	'\u2060': -1,   // word joiner
+	'\uFEFF': -1,   // zero-width no-break space
+}
+
+// WithPlainPaste normalizes pasted text to plain ASCII quotes and spaces,
+// removing zero-width runes. The PastePlain command (COMMAND-SHIFT-V, or
+// a right-click) does so for a single paste.
+func WithPlainPaste(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.plain_paste = enabled
+	}
+}
+
+// plainText returns the runes with smart quotes and non-breaking spaces
+// replaced, and zero-width runes removed.
+func plainText(rs []rune) []rune {
+	plain := make([]rune, 0, len(rs))
+	for _, r := range rs {
+		if p, ok := plainRunes[r]; ok {
+			if p < 0 {
+				continue
+			}
+			r = p
+		}
+		plain = append(plain, r)
+	}
+	return plain
+}
+
+// normalizeNewlines returns the runes with each "\r\n", or lone '\r', as a
+// '\n', the line ending of the content, so that text copied on Windows
+// doesn't paste stray '\r' runes. Text of whole lines may be copied with
+// the indent of the line after, which is stripped, so that it pastes the
+// same whichever line ending it was copied with.
+func normalizeNewlines(rs []rune) []rune {
+	normal := make([]rune, 0, len(rs))
+	for i, r := range rs {
+		if r == '\r' {
+			if i+1 < len(rs) && rs[i+1] == '\n' {
+				continue
+			}
+			r = '\n'
+		}
+		normal = append(normal, r)
+	}
+
+	// Strip a trailing partial line of only spaces and tabs.
+	end := len(normal)
+	for end > 0 && (normal[end-1] == ' ' || normal[end-1] == '\t') {
+		end--
+	}
+	if end > 0 && end < len(normal) && normal[end-1] == '\n' {
+		normal = normal[:end]
+	}
+	return normal
+}
+
+// paste inserts the runes at the cursor and any carets.
+func (e *Editor) paste(rs []rune) {
+	e.storeUndoAction(e.atCarets(func() func() bool {
+		return e.fnHandleRuneMulti(rs)
+	}))
+	e.setModified()
+}
+
+// showPasteMenu shows a menu of the ways to paste, at the cursor.
+func (e *Editor) showPasteMenu() {
+	commands := []string{"Paste", "PastePlain"}
+	e.ShowPopup(&Popup{
+		Items: []string{e.tr("Paste"), e.tr("Paste as plain text")},
+		OnChoose: func(e *Editor, index int) {
+			e.RunCommand(commands[index])
+		},
+	})
+}
+
+// clipboardRunes returns the runes to paste from the clipboard, with its
+// line endings normalized. If there is no text, an image is passed to the
+// paste image handler.
+func (e *Editor) clipboardRunes() []rune {
+	rs := normalizeNewlines([]rune(string(e.clipboard.ReadText())))
+	if e.plain_paste {
+		rs = plainText(rs)
+	}
+	if len(rs) > 0 || e.on_paste_image == nil {
+		return rs
+	}
+
+	images, ok := e.clipboard.(ImageContent)
+	if !ok {
+		return rs
+	}
+	image := images.ReadImage()
+	if len(image) == 0 {
+		return rs
+	}
+	return normalizeNewlines([]rune(e.on_paste_image(image)))
+}
@@ -0,0 +1,105 @@
+package noter
+
+import (
+	"testing"
+)
+
+type imageClipboard struct {
+	dummyContent
+	image []byte
+}
+
+func (c *imageClipboard) ReadImage() []byte {
+	return c.image
+}
+
+func TestClipboardRunes(t *testing.T) {
+	clipboard := &imageClipboard{image: []byte{1, 2, 3}}
+	pasted := 0
+	editor := NewEditor(
+		WithClipboard(clipboard),
+		WithOnPasteImage(func(image []byte) string {
+			pasted = len(image)
+			return "![](image.png)"
+		}),
+	)
+
+	if rs := string(editor.clipboardRunes()); rs != "![](image.png)" || pasted != 3 {
+		t.Fatalf("Incorrect image paste, got %q for %v bytes", rs, pasted)
+	}
+
+	clipboard.WriteText([]byte("text"))
+	pasted = 0
+	if rs := string(editor.clipboardRunes()); rs != "text" || pasted != 0 {
+		t.Fatalf("Expected text to be pasted over the image, got %q", rs)
+	}
+}
+
+func TestPasteNewlines(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("<>\n"))
+	editor.MoveCursor(0, 1)
+	editor.clipboard.WriteText([]byte("a\r\nb\rc\r\n"))
+
+	editor.RunCommand("Paste")
+	if got := string(editor.ReadText()); got != "<a\nb\nc\n>\n" {
+		t.Fatalf("Expected the line endings to be normalized, got %q", got)
+	}
+
+	editor.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != "<>\n" {
+		t.Fatalf("Expected the paste to be undone at once, got %q", got)
+	}
+}
+
+func TestNormalizeNewlines(t *testing.T) {
+	table := [](struct {
+		text     string
+		expected string
+	}){
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\rb\r", "a\nb\n"},
+		{"a\nb\n", "a\nb\n"},
+		{"a\r\nb\r\n  \t", "a\nb\n"},
+		{"a\rb\r    ", "a\nb\n"},
+		{"a\nb\n\t", "a\nb\n"},
+		{"a\n  b", "a\n  b"},
+		{"a  ", "a  "},
+		{"  ", "  "},
+		{"", ""},
+	}
+
+	for _, entry := range table {
+		if got := string(normalizeNewlines([]rune(entry.text))); got != entry.expected {
+			t.Fatalf("Incorrect normalization of %q, expected %q, got %q", entry.text, entry.expected, got)
+		}
+	}
+}
+
+func TestPlainPaste(t *testing.T) {
+	editor := NewEditor(WithPlainPaste(true))
+	editor.clipboard.WriteText([]byte("\u201Cname\u201D:\u00A0\u2018it\u2019s\u200B\u2019"))
+
+	if rs := string(editor.clipboardRunes()); rs != `"name": 'it's'` {
+		t.Fatalf("Expected plain text to be pasted, got %q", rs)
+	}
+
+	editor = NewEditor()
+	editor.clipboard.WriteText([]byte("\u201Cq\u201D\u2060"))
+	editor.RunCommand("PastePlain")
+	if got := string(editor.ReadText()); got != "\"q\"\n" {
+		t.Fatalf("Expected PastePlain to paste plain text, got %q", got)
+	}
+}
+
+func TestPastePlainBinding(t *testing.T) {
+	editor := NewEditor()
+	editor.clipboard.WriteText([]byte("\u201Cq\u201D"))
+
+	if !editor.runKeystroke("ctrl+shift+v") {
+		t.Fatalf("Expected ctrl+shift+v to run the cmd+shift+v binding")
+	}
+	if got := string(editor.ReadText()); got != "\"q\"\n" {
+		t.Fatalf("Expected cmd+shift+v to paste plain text, got %q", got)
+	}
+}
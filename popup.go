@@ -0,0 +1,232 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// POPUP_DEFAULT_ROWS is the number of items a popup shows before scrolling.
+const POPUP_DEFAULT_ROWS = 8
+
+// Popup is a scrollable list overlay, such as a completion list or a menu,
+// shown near the cursor and kept within the bounds of the editor.
+//
+// Up and Down change the selected item, Enter (or a click) chooses it.
+// Any other input is left for the editor to handle.
+type Popup struct {
+	Items    []string // Items to list.
+	Selected int      // Index of the selected item.
+	Rows     int      // Number of items visible at once. Defaults to POPUP_DEFAULT_ROWS.
+
+	// Anchor is the area the popup is shown next to. If empty, the popup
+	// is shown next to the cursor.
+	Anchor image.Rectangle
+
+	// OnChoose is called with the chosen item, after the popup is closed.
+	OnChoose func(e *Editor, index int)
+	// OnDismiss is called when the popup is closed without a choice.
+	OnDismiss func(e *Editor)
+
+	first  int // Index of the first visible item.
+	chosen bool
+}
+
+// ShowPopup shows the popup above all other overlays.
+func (e *Editor) ShowPopup(p *Popup) {
+	if p.Anchor.Empty() {
+		p.Anchor = e.caretBounds
+	}
+	if p.Rows <= 0 {
+		p.Rows = POPUP_DEFAULT_ROWS
+	}
+	p.chosen = false
+	p.move(0)
+	e.PushOverlay(p)
+}
+
+// visibleRows returns the number of rows the popup occupies.
+func (p *Popup) visibleRows() int {
+	if len(p.Items) < p.Rows {
+		return len(p.Items)
+	}
+	return p.Rows
+}
+
+// Bounds places the popup below its anchor, or above if there is not
+// enough room below, clamped to the editor.
+func (p *Popup) Bounds(e *Editor) image.Rectangle {
+	pad := e.width_padding
+	width := 0
+	for _, item := range p.Items {
+		if w := font.MeasureString(e.font_info.face, item).Ceil(); w > width {
+			width = w
+		}
+	}
+	width += pad * 2
+	height := p.visibleRows()*e.font_info.yUnit + pad*2
+
+	if width > e.width {
+		width = e.width
+	}
+	if height > e.height {
+		height = e.height
+	}
+
+	x, y := p.Anchor.Min.X, p.Anchor.Max.Y
+	if y+height > e.height && p.Anchor.Min.Y-height >= 0 {
+		y = p.Anchor.Min.Y - height
+	}
+	if x+width > e.width {
+		x = e.width - width
+	}
+	if y+height > e.height {
+		y = e.height - height
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// move changes the selected item, scrolling to keep it visible.
+func (p *Popup) move(delta int) {
+	p.Selected += delta
+	if p.Selected >= len(p.Items) {
+		p.Selected = len(p.Items) - 1
+	}
+	if p.Selected < 0 {
+		p.Selected = 0
+	}
+
+	switch {
+	case p.Selected < p.first:
+		p.first = p.Selected
+	case p.Selected >= p.first+p.Rows:
+		p.first = p.Selected - p.Rows + 1
+	}
+}
+
+// choose closes the popup and reports the selected item.
+func (p *Popup) choose(e *Editor) {
+	if len(p.Items) == 0 {
+		e.DismissOverlay()
+		return
+	}
+	p.chosen = true
+	e.RemoveOverlay(p)
+	if p.OnChoose != nil {
+		p.OnChoose(e, p.Selected)
+	}
+}
+
+func (p *Popup) Update(e *Editor) bool {
+	switch {
+	case isKeyJustPressedOrRepeating(ebiten.KeyArrowUp):
+		p.move(-1)
+	case isKeyJustPressedOrRepeating(ebiten.KeyArrowDown):
+		p.move(1)
+	case isKeyJustPressedOrRepeating(ebiten.KeyPageUp):
+		p.move(-p.Rows)
+	case isKeyJustPressedOrRepeating(ebiten.KeyPageDown):
+		p.move(p.Rows)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		p.choose(e)
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		// Clicks outside of the popup have already dismissed it.
+		_, y := ebiten.CursorPosition()
+		bounds := p.Bounds(e)
+		p.move(p.first + (y-bounds.Min.Y-e.width_padding)/e.font_info.yUnit - p.Selected)
+		p.choose(e)
+	default:
+		if _, wheel := ebiten.Wheel(); wheel != 0 {
+			if wheel > 0 {
+				p.move(-1)
+			} else {
+				p.move(1)
+			}
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func (p *Popup) Draw(e *Editor, screen *ebiten.Image) {
+	bounds := p.Bounds(e)
+	pad := e.width_padding
+
+	// Background and border.
+	ebitenutil.DrawRect(screen, float64(bounds.Min.X), float64(bounds.Min.Y),
+		float64(bounds.Dx()), float64(bounds.Dy()), e.popupBackground())
+	x0, y0 := float64(bounds.Min.X), float64(bounds.Min.Y)
+	x1, y1 := float64(bounds.Max.X-1), float64(bounds.Max.Y-1)
+	ebitenutil.DrawLine(screen, x0, y0, x1, y0, e.font_color)
+	ebitenutil.DrawLine(screen, x1, y0, x1, y1, e.font_color)
+	ebitenutil.DrawLine(screen, x1, y1, x0, y1, e.font_color)
+	ebitenutil.DrawLine(screen, x0, y1, x0, y0, e.font_color)
+
+	for row := 0; row < p.visibleRows(); row++ {
+		index := p.first + row
+		if index >= len(p.Items) {
+			break
+		}
+		top := bounds.Min.Y + pad + row*e.font_info.yUnit
+		if index == p.Selected {
+			ebitenutil.DrawRect(screen, float64(bounds.Min.X+1), float64(top),
+				float64(bounds.Dx()-2), float64(e.font_info.yUnit), e.select_color)
+		}
+		text.Draw(screen, p.Items[index], e.font_info.face,
+			bounds.Min.X+pad, top+e.font_info.ascent, e.font_color)
+	}
+}
+
+func (p *Popup) Dismiss(e *Editor) {
+	if !p.chosen && p.OnDismiss != nil {
+		p.OnDismiss(e)
+	}
+}
+
+// popupBackgroundColor is the background of popups, over a background
+// image rather than a color.
+var popupBackgroundColor color.Color = color.White
+
+// popupBackground returns the background color of popups, which is that
+// of the editor.
+func (e *Editor) popupBackground() color.Color {
+	if e.background_color != nil {
+		return e.background_color
+	}
+	return popupBackgroundColor
+}
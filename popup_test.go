@@ -0,0 +1,51 @@
+package noter
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPopupBounds(t *testing.T) {
+	editor := NewEditor(WithWidth(200), WithHeight(100))
+	yUnit := editor.font_info.yUnit
+
+	popup := &Popup{
+		Items:  []string{"one", "two", "three"},
+		Anchor: image.Rect(190, 90, 196, 90+yUnit),
+	}
+	editor.ShowPopup(popup)
+
+	bounds := popup.Bounds(editor)
+	if !bounds.In(image.Rect(0, 0, 200, 100)) {
+		t.Fatalf("Expected popup to be clamped to the editor, got: %v", bounds)
+	}
+	if bounds.Max.Y > popup.Anchor.Min.Y {
+		t.Fatalf("Expected popup to be shown above its anchor, got: %v", bounds)
+	}
+}
+
+func TestPopupScrollAndChoose(t *testing.T) {
+	editor := NewEditor()
+
+	chosen := -1
+	popup := &Popup{
+		Items:    []string{"a", "b", "c", "d", "e"},
+		Rows:     2,
+		OnChoose: func(e *Editor, index int) { chosen = index },
+	}
+	editor.ShowPopup(popup)
+
+	popup.move(3)
+	if popup.Selected != 3 || popup.first != 2 {
+		t.Fatalf("Incorrect popup scroll, got selected %v, first %v", popup.Selected, popup.first)
+	}
+	popup.move(10)
+	if popup.Selected != 4 {
+		t.Fatalf("Expected selection to be clamped, got: %v", popup.Selected)
+	}
+
+	popup.choose(editor)
+	if chosen != 4 || editor.TopOverlay() != nil {
+		t.Fatalf("Expected the popup to close with item 4, got: %v", chosen)
+	}
+}
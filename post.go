@@ -0,0 +1,53 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// PostEdit queues a function to be run at the start of the next Update.
+//
+// The Editor is not safe for concurrent use: its methods must be called
+// from the goroutine running the game (such as from Update or Draw), or
+// before the game is run. PostEdit is the exception, and may be called from
+// any goroutine, so that text arriving from the network can be inserted,
+// such as with AppendText. Functions are run in the order they were posted.
+func (e *Editor) PostEdit(edit func(e *Editor)) {
+	e.postMu.Lock()
+	defer e.postMu.Unlock()
+	e.posted = append(e.posted, edit)
+}
+
+// runPosted runs the functions queued by PostEdit, other than while a
+// tutorial plays, which they wait for.
+func (e *Editor) runPosted() {
+	if e.tutorial != nil {
+		return
+	}
+	e.postMu.Lock()
+	posted := e.posted
+	e.posted = nil
+	e.postMu.Unlock()
+
+	for _, edit := range posted {
+		e.dirty = true
+		edit(e)
+	}
+}
@@ -0,0 +1,27 @@
+package noter
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPostEdit(t *testing.T) {
+	editor := NewEditor()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			editor.PostEdit(func(e *Editor) {
+				e.AppendText([]byte("x"))
+			})
+		}()
+	}
+	wg.Wait()
+
+	editor.runPosted()
+	if got := string(editor.ReadText()); got != "xxxxxxxxxx\n" {
+		t.Fatalf("Expected the posted edits to be applied, got: %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// WithProseMode enables prose mode, where SHIFT-Enter enters a soft break.
+// A soft break breaks the line in the editor, marked as wrapped, but isn't
+// written: the lines are joined by a space when the content is read or
+// saved. Enter still enters a hard break, which is written as a new line.
+func WithProseMode(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.prose_mode = enabled
+	}
+}
+
+// ProseMode returns true if prose mode is enabled.
+func (e *Editor) ProseMode() bool {
+	return e.prose_mode
+}
+
+// fnSoftBreak breaks the line at the cursor with a soft break.
+func (e *Editor) fnSoftBreak() func() bool {
+	e.resetHighlight()
+	e.handleRune('\n')
+	e.cursor.line.prev.soft = true
+
+	row := e.getLineNumber()
+	return func() bool {
+		e.MoveCursor(row, 0)
+		e.deletePrevious()
+		return true
+	}
+}
+
+// joinSoftBreaks replaces the new line ending each soft broken line of
+// the runes, which are all of the lines in order, with a space. No space
+// is added where there is already one either side of the break.
+func (e *Editor) joinSoftBreaks(all []rune) []rune {
+	joined := all[:0]
+	i := 0
+	for line := e.start; line != nil; line = line.next {
+		end := i + len(line.values)
+		joined = append(joined, all[i:end-1]...)
+		switch {
+		case !line.soft || line.next == nil:
+			joined = append(joined, '\n')
+		case end-1 > i && isSpace(all[end-2]), isSpace(all[end]):
+		default:
+			joined = append(joined, ' ')
+		}
+		i = end
+	}
+	return joined
+}
+
+// isSpace returns true for a space or tab.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
@@ -0,0 +1,58 @@
+package noter
+
+import "testing"
+
+func TestSoftBreak(t *testing.T) {
+	editor := NewEditor(WithProseMode(true))
+	editor.WriteText([]byte("one two three\nfour\n"))
+
+	editor.MoveCursor(0, 3)
+	editor.storeUndoAction(editor.fnSoftBreak())
+	editor.MoveCursor(1, 4)
+	editor.storeUndoAction(editor.fnSoftBreak())
+	if got := string(editor.getAllRunes()); got != "one\n two\n three\nfour\n\n" {
+		t.Fatalf("Incorrect lines, got %q", got)
+	}
+	if got := string(editor.ReadText()); got != "one two three\nfour\n" {
+		t.Fatalf("Expected soft breaks to be joined, got %q", got)
+	}
+
+	// A hard break within a soft broken line keeps the soft break after it.
+	editor.MoveCursor(0, 1)
+	editor.storeUndoAction(editor.fnHandleRuneSingle('\n'))
+	if got := string(editor.ReadText()); got != "o\nne two three\nfour\n" {
+		t.Fatalf("Incorrect text after a hard break, got %q", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		editor.runCommand("z")
+	}
+	if got := string(editor.getAllRunes()); got != "one two three\nfour\n\n" {
+		t.Fatalf("Incorrect lines after undo, got %q", got)
+	}
+}
+
+func TestSoftBreakSwap(t *testing.T) {
+	editor := NewEditor(WithProseMode(true))
+	editor.WriteText([]byte("one two\nthree\n"))
+
+	editor.MoveCursor(0, 3)
+	editor.storeUndoAction(editor.fnSoftBreak())
+	edited := editor.start.edited
+	editor.MoveCursor(0, 0)
+	editor.storeUndoAction(editor.fnSwapDown())
+	if got := string(editor.getAllRunes()); got != " two\none\nthree\n\n" {
+		t.Fatalf("Incorrect lines after swap, got %q", got)
+	}
+	if got := string(editor.ReadText()); got != " two\none three\n" {
+		t.Fatalf("Expected the soft break to move with its line, got %q", got)
+	}
+	if !editor.start.next.edited.Equal(edited) {
+		t.Fatalf("Expected the edit time to move with its line")
+	}
+
+	editor.runCommand("z")
+	if got := string(editor.ReadText()); got != "one two\nthree\n" {
+		t.Fatalf("Incorrect text after undo, got %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"time"
+)
+
+const EDITOR_NOTICE_DURATION = 3 * time.Second
+
+// WithOnError sets the function called with errors which the editor has
+// recovered from, such as a panic while updating or rendering.
+// If set to nil, errors are written to the logger.
+func WithOnError(opt func(err error)) EditorOption {
+	return func(e *Editor) {
+		if opt == nil {
+			opt = func(err error) {
+				e.logger.Print(err)
+			}
+		}
+		e.on_error = opt
+	}
+}
+
+// Notify shows a message in the bottom bar for a few seconds,
+// in place of the help text.
+func (e *Editor) Notify(message string) {
+	e.notice = message
+	e.noticeUntil = time.Now().Add(EDITOR_NOTICE_DURATION)
+}
+
+// currentNotice returns the message to show, if it hasn't expired.
+func (e *Editor) currentNotice() (string, bool) {
+	if e.notice == "" || time.Now().After(e.noticeUntil) {
+		return "", false
+	}
+	return e.notice, true
+}
+
+// Degraded returns true if rendering has fallen back to plain text, after
+// recovering from a panic while rendering.
+func (e *Editor) Degraded() bool {
+	return e.degraded
+}
+
+// recoverPanic recovers from a panic in the deferring function, reporting
+// it as an error. It must be called directly by a deferred statement.
+func (e *Editor) recoverPanic(where string) {
+	if r := recover(); r != nil {
+		e.reportPanic(where, r)
+	}
+}
+
+// reportPanic reports a recovered panic to the error handler, and the user.
+func (e *Editor) reportPanic(where string, r any) {
+	err := fmt.Errorf("recovered from panic during %s: %v", where, r)
+	e.on_error(err)
+	e.Notify(err.Error())
+}
@@ -0,0 +1,25 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecoverRenderPanic(t *testing.T) {
+	var errs []error
+	editor := NewEditor(WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+	editor.WriteText([]byte("ab\ncd\n"))
+
+	// An invalid cursor position panics when rendering.
+	editor.cursor.x = 100
+
+	editor.updateImage()
+	if len(errs) == 0 || !editor.Degraded() {
+		t.Fatalf("Expected the render panic to be reported, and to degrade, got: %v", errs)
+	}
+	if notice, ok := editor.currentNotice(); !ok || !strings.Contains(notice, "panic") {
+		t.Fatalf("Expected a notice of the panic, got: %q", notice)
+	}
+}
@@ -0,0 +1,149 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"strings"
+)
+
+// WithHardWrap enables automatically inserting a newline at the last word
+// boundary before the column while typing. It also sets the width used to
+// reflow paragraphs. If set to <= 0, hard wrapping is disabled.
+func WithHardWrap(column int) EditorOption {
+	return func(e *Editor) {
+		e.hard_wrap = column
+	}
+}
+
+// fnTypeRune inserts a typed rune, wrapping the line if required.
+// Typing an opening quote or bracket over a selection surrounds it instead.
+func (e *Editor) fnTypeRune(r rune) func() bool {
+	if close, ok := surroundPairs[r]; ok && e.auto_surround && e.mode == EDIT_MODE && len(e.highlighted) != 0 {
+		return e.fnSurroundSelection(r, close)
+	}
+	return fnCompound(e.fnHandleRuneSingle(r), e.fnHardWrap())
+}
+
+// fnHardWrap breaks the cursor line at the last space before the hard wrap
+// column, if the line has grown past it.
+func (e *Editor) fnHardWrap() func() bool {
+	if e.hard_wrap <= 0 || e.mode != EDIT_MODE {
+		return noop
+	}
+
+	values := e.cursor.line.values
+	if len(values)-1 <= e.hard_wrap {
+		return noop
+	}
+
+	// Find the last space which keeps the text before it within the column.
+	at := -1
+	for x := e.hard_wrap; x > 0; x-- {
+		if values[x] == ' ' {
+			at = x
+			break
+		}
+	}
+	if at < 0 {
+		// A single word is longer than the column.
+		return noop
+	}
+
+	curX := e.cursor.x
+	e.cursor.x = at + 1
+	e.deletePrevious()
+	e.handleRune('\n')
+	lineNum := e.getLineNumber()
+	if curX > at {
+		e.cursor.x = curX - (at + 1)
+	} else {
+		e.cursor.line = e.cursor.line.prev
+		e.cursor.x = curX
+	}
+	e.fixPosition()
+
+	return func() bool {
+		e.MoveCursor(lineNum, 0)
+		e.deletePrevious()
+		e.handleRune(' ')
+		e.MoveCursor(lineNum-1, curX)
+		return true
+	}
+}
+
+// isBlankLine returns true if the line only holds whitespace.
+func isBlankLine(values []rune) bool {
+	return len(strings.TrimSpace(string(values))) == 0
+}
+
+// wrapWords fills lines with words, each line prefixed by the indent,
+// without exceeding the width unless a single word is wider.
+func wrapWords(words []string, indent string, width int) (lines [][]rune) {
+	current := indent
+	for _, word := range words {
+		if current != indent && len([]rune(current))+1+len([]rune(word)) > width {
+			lines = append(lines, []rune(current+"\n"))
+			current = indent
+		}
+		if current != indent {
+			current += " "
+		}
+		current += word
+	}
+	return append(lines, []rune(current+"\n"))
+}
+
+// ReflowParagraph rewraps the paragraph at the cursor to the hard wrap
+// column, or to the width of the text if hard wrap is disabled. Paragraphs are
+// separated by blank lines, and keep the indent of their first line.
+// The reflow is a single undoable action.
+func (e *Editor) ReflowParagraph() {
+	if isBlankLine(e.cursor.line.values) {
+		return
+	}
+
+	width := e.hard_wrap
+	if width <= 0 {
+		width = e.textColumns()
+	}
+
+	// Find the start of the paragraph.
+	first := e.cursor.line
+	row := e.getLineNumber()
+	for first.prev != nil && !isBlankLine(first.prev.values) {
+		first = first.prev
+		row--
+	}
+
+	values := first.values
+	indent := string(values[:len(values)-len([]rune(strings.TrimLeft(string(values), " \t")))])
+
+	words := make([]string, 0)
+	count := 0
+	for line := first; line != nil && !isBlankLine(line.values); line = line.next {
+		words = append(words, strings.Fields(string(line.values))...)
+		count++
+	}
+
+	e.storeUndoAction(e.fnReplaceLines(row, count, wrapWords(words, indent, width)))
+}
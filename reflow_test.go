@@ -0,0 +1,51 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestHardWrap(t *testing.T) {
+	editor := NewEditor(WithHardWrap(10))
+	editor.WriteText([]byte("\n"))
+
+	for _, r := range "the quick brown fox" {
+		editor.storeUndoAction(editor.fnTypeRune(r))
+	}
+
+	want := "the quick\nbrown fox\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect hard wrap, expected %q, got: %q", want, got)
+	}
+	if row, col := editor.Cursor(); row != 1 || col != 9 {
+		t.Fatalf("Incorrect cursor after hard wrap, got (%v,%v)", row, col)
+	}
+
+	// Undo back to before the wrap.
+	for i := 0; i < 9; i++ {
+		editor.undoStack[len(editor.undoStack)-1]()
+		editor.undoStack = editor.undoStack[:len(editor.undoStack)-1]
+	}
+	want = "the quick \n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect undo of hard wrap, expected %q, got: %q", want, got)
+	}
+}
+
+func TestReflowParagraph(t *testing.T) {
+	editor := NewEditor(WithHardWrap(12))
+	editor.WriteText([]byte("title\n\n  one two\n  three four five six\n\nend\n"))
+
+	editor.MoveCursor(3, 0)
+	editor.ReflowParagraph()
+
+	want := "title\n\n  one two\n  three four\n  five six\n\nend\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect reflow, expected %q, got: %q", want, got)
+	}
+
+	editor.undoStack[len(editor.undoStack)-1]()
+	want = "title\n\n  one two\n  three four five six\n\nend\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect undo of reflow, expected %q, got: %q", want, got)
+	}
+}
@@ -0,0 +1,118 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"regexp"
+)
+
+// WithRegexSearch treats search terms as regular expressions, matched within
+// each line. If the pattern has groups, only the submatches are highlighted.
+// COMMAND-R toggles this while searching.
+func WithRegexSearch(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.regex_search = enabled
+	}
+}
+
+// RegexSearch returns true if search terms are regular expressions.
+func (e *Editor) RegexSearch() bool {
+	return e.regex_search
+}
+
+// SetRegexSearch enables or disables regular expression search, and
+// repeats any current search.
+func (e *Editor) SetRegexSearch(enabled bool) {
+	e.regex_search = enabled
+	if e.mode == SEARCH_MODE {
+		e.search()
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// searchPattern compiles the search term as a regular expression, with the
+// case sensitivity of the search. It returns nil for an invalid pattern.
+func (e *Editor) searchPattern() *regexp.Regexp {
+	pattern := string(e.searchTerm)
+	if !e.search_case {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	e.searchInvalid = err != nil
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// searchLineRegexp finds the matches of the pattern of the scan in a line,
+// adding the line and rune index of the start of each.
+func (e *Editor) searchLineRegexp(scan *searchScan, curLine *editorLine) {
+	s := string(curLine.values[:len(curLine.values)-1])
+	runeAt := runeOffsets(s)
+
+	for _, m := range scan.re.FindAllStringSubmatchIndex(s, -1) {
+		// Empty matches, such as of "a*", are skipped.
+		if m[0] == m[1] {
+			continue
+		}
+		if e.search_word && !isWholeWord(curLine.values, runeAt[m[0]], runeAt[m[1]]) {
+			continue
+		}
+		scan.lines = append(scan.lines, curLine)
+		scan.xs = append(scan.xs, runeAt[m[0]])
+
+		ranges := m[:2]
+		if len(m) > 2 {
+			ranges = m[2:]
+		}
+		for i := 0; i < len(ranges); i += 2 {
+			// Groups which did not participate are -1.
+			if ranges[i] < 0 {
+				continue
+			}
+			for x := runeAt[ranges[i]]; x < runeAt[ranges[i+1]]; x++ {
+				if _, ok := e.searchHighlights[curLine]; !ok {
+					e.searchHighlights[curLine] = make(map[int]bool)
+				}
+				e.searchHighlights[curLine][x] = true
+			}
+		}
+	}
+}
+
+// runeOffsets maps the byte offsets of the string to rune offsets, for
+// the matches of a regular expression. The offset of the end of the
+// string is included.
+func runeOffsets(s string) []int {
+	runeAt := make([]int, len(s)+1)
+	x := 0
+	for i := range s {
+		runeAt[i] = x
+		x++
+	}
+	runeAt[len(s)] = x
+	return runeAt
+}
@@ -0,0 +1,42 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegexSearch(t *testing.T) {
+	editor := NewEditor(WithRegexSearch(true))
+	editor.WriteText([]byte("id: 12\nname: é\nID: 345\n"))
+
+	table := [](struct {
+		term       string
+		matches    int
+		highlights map[int]map[int]bool
+	}){
+		{`\d+`, 2, map[int]map[int]bool{0: {4: true, 5: true}, 2: {4: true, 5: true, 6: true}}},
+		{`^id: (\d)`, 2, map[int]map[int]bool{0: {4: true}, 2: {4: true}}},
+		{`e: (é)$`, 1, map[int]map[int]bool{1: {6: true}}},
+		{`x*`, 0, map[int]map[int]bool{}},
+		{`(`, 0, map[int]map[int]bool{}},
+	}
+
+	for _, entry := range table {
+		editor.Search(entry.term)
+		if editor.searchMatches != entry.matches {
+			t.Fatalf("Incorrect matches for %q, expected %v, got %v", entry.term, entry.matches, editor.searchMatches)
+		}
+
+		highlights := map[int]map[int]bool{}
+		for line, xs := range editor.searchHighlights {
+			highlights[editor.getLineNumberFromLine(line)-1] = xs
+		}
+		if !reflect.DeepEqual(highlights, entry.highlights) {
+			t.Fatalf("Incorrect highlights for %q, expected %v, got %v", entry.term, entry.highlights, highlights)
+		}
+	}
+
+	if !editor.searchInvalid {
+		t.Fatalf("Expected an invalid pattern to be reported")
+	}
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// SetLinesReadOnly marks the lines from first to last, inclusive, as
+// read-only, or editable again, such as to protect a generated header.
+// The cursor can move through read-only lines, but edits which would
+// change them are rejected with a Notify message. Read-only lines move
+// with the edits around them.
+func (e *Editor) SetLinesReadOnly(first, last int, readOnly bool) {
+	line := e.lineAt(first)
+	for row := first; row <= last && line != nil; row++ {
+		line.protected = readOnly
+		line = line.next
+	}
+	e.Invalidate()
+}
+
+// LineReadOnly returns true if the line at the row is read-only.
+func (e *Editor) LineReadOnly(row int) bool {
+	return e.lineAt(row).protected
+}
+
+// canEdit returns true if the content can be edited at the cursor, the
+// selection, the carets and the given lines. Edits of read-only lines,
+// and edits while a queued edit of all of the lines runs, are rejected
+// with a Notify message. Otherwise undo is always allowed.
+func (e *Editor) canEdit(lines ...*editorLine) bool {
+	if e.read_only {
+		return false
+	}
+	if e.lineEdit != nil {
+		e.Notify(e.tr("wait for %s to finish", e.lineEdit.name))
+		return false
+	}
+	if e.undoing {
+		return true
+	}
+
+	lines = append(lines, e.cursor.line)
+	for line := range e.highlighted {
+		lines = append(lines, line)
+	}
+	for line := range e.caretLines() {
+		lines = append(lines, line)
+	}
+	for _, line := range lines {
+		if line != nil && line.protected {
+			e.Notify(e.tr("line %d is read-only", e.getLineNumberFromLine(line)))
+			return false
+		}
+	}
+	return true
+}
+
+// canEditRows returns true if the count lines from the row can be edited.
+func (e *Editor) canEditRows(row, count int) bool {
+	lines := make([]*editorLine, 0, count)
+	for line := e.lineAt(row); line != nil && len(lines) < count; line = line.next {
+		lines = append(lines, line)
+	}
+	return e.canEdit(lines...)
+}
+
+// joinedLine returns the previous line, if deleting back from the cursor
+// would join the cursor line to it.
+func (e *Editor) joinedLine() *editorLine {
+	if e.cursor.x == 0 && len(e.highlighted) == 0 {
+		return e.cursor.line.prev
+	}
+	return nil
+}
+
+// joinedLines returns the previous lines which deleting back from the
+// cursor and from each caret would join to their lines, as Backspace.
+func (e *Editor) joinedLines() []*editorLine {
+	lines := []*editorLine{e.joinedLine()}
+	if len(e.highlighted) != 0 {
+		return lines
+	}
+	for line, xs := range e.caretLines() {
+		for _, x := range xs {
+			if x == 0 {
+				lines = append(lines, line.prev)
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,59 @@
+package noter
+
+import "testing"
+
+func TestLinesReadOnly(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("header\nbody\n"))
+	editor.SetLinesReadOnly(0, 0, true)
+
+	editor.MoveCursor(0, 2)
+	if editor.canEdit() || editor.ToggleCheckbox() {
+		t.Fatalf("Expected the header to be read-only")
+	}
+	if editor.notice == "" {
+		t.Fatalf("Expected a notice of the rejected edit")
+	}
+
+	// Joining the body to the header is rejected too.
+	editor.MoveCursor(1, 0)
+	if editor.canEdit(editor.joinedLine()) {
+		t.Fatalf("Expected joining to the header to be rejected")
+	}
+
+	// Replacing lines which include the header is rejected.
+	editor.storeUndoAction(editor.fnReplaceLines(0, 2, [][]rune{[]rune("x\n")}))
+	if got := string(editor.ReadText()); got != "header\nbody\n" {
+		t.Fatalf("Expected the replacement to be rejected, got %q", got)
+	}
+
+	// The header moves with the lines added before it.
+	editor.MoveCursor(0, 0)
+	editor.SetLinesReadOnly(0, 0, false)
+	editor.storeUndoAction(editor.fnHandleRuneSingle('\n'))
+	editor.SetLinesReadOnly(1, 1, true)
+	editor.MoveCursor(0, 0)
+	editor.storeUndoAction(editor.fnHandleRuneSingle('\n'))
+	if !editor.LineReadOnly(2) || editor.LineReadOnly(1) {
+		t.Fatalf("Expected the header to move down a line")
+	}
+}
+
+func TestLinesReadOnlyCarets(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("header\nbody\nmore\n"))
+	editor.SetLinesReadOnly(0, 0, true)
+
+	// A caret at the start of the body would join it to the header.
+	editor.MoveCursor(2, 0)
+	editor.AddCaret(1, 0)
+	if editor.canEdit(editor.joinedLines()...) {
+		t.Fatalf("Expected joining at the caret to be rejected")
+	}
+
+	editor.ClearCarets()
+	editor.AddCaret(1, 2)
+	if !editor.canEdit(editor.joinedLines()...) {
+		t.Fatalf("Expected deleting within the body to be allowed")
+	}
+}
@@ -0,0 +1,52 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// ReplaceAll replaces each match of the term with the replacement, with
+// the options of the search: regular expressions, case sensitivity and
+// whole words. The lines are changed as queued work, see Queue, and the
+// replacement is undone as a single step. It returns false for an empty
+// term or an invalid pattern, or if the content can't be edited.
+func (e *Editor) ReplaceAll(term, replacement string) bool {
+	match := e.matcher([]rune(term))
+	if match == nil || !e.canEdit() {
+		return false
+	}
+
+	with := []rune(replacement)
+	e.queueEdit(e.tr("replace all"), func(values []rune) []rune {
+		spans := match(values)
+		if len(spans) == 0 {
+			return nil
+		}
+		replaced := make([]rune, 0, len(values))
+		x := 0
+		for _, span := range spans {
+			replaced = append(replaced, values[x:span[0]]...)
+			replaced = append(replaced, with...)
+			x = span[1]
+		}
+		return append(replaced, values[x:]...)
+	})
+	return true
+}
@@ -0,0 +1,37 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestReplaceAll(t *testing.T) {
+	table := [](struct {
+		text        string
+		term        string
+		replacement string
+		regex       bool
+		expected    string
+	}){
+		{"a cat\ncat cat\n", "cat", "dog", false, "a dog\ndog dog\n"},
+		{"a Cat\n", "cat", "dog", false, "a dog\n"},
+		{"a1 b22\n", "[0-9]+", "#", true, "a# b#\n"},
+		{"none\n", "cat", "dog", false, "none\n"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor(WithRegexSearch(entry.regex))
+		editor.WriteText([]byte(entry.text))
+		if !editor.ReplaceAll(entry.term, entry.replacement) {
+			t.Fatalf("Expected a replacement of %q", entry.term)
+		}
+		runAll(editor)
+		if got := string(editor.ReadText()); got != entry.expected {
+			t.Fatalf("Incorrect replacement of %q, expected %q, got %q", entry.term, entry.expected, got)
+		}
+	}
+
+	editor := NewEditor(WithRegexSearch(true))
+	if editor.ReplaceAll("(", "x") {
+		t.Fatalf("Expected no replacement of an invalid pattern")
+	}
+}
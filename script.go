@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EditOp is an edit for ApplyEdits, which replaces the text from Start up
+// to End with Text. Start and End are the same to insert, and Text is
+// empty to delete. A '\n' ends each line, so an edit which ends at the
+// start of the next line also replaces the end of the line.
+type EditOp struct {
+	Start, End Position
+	Text       string
+}
+
+// before returns true if the position is before q.
+func (p Position) before(q Position) bool {
+	return p.Row < q.Row || (p.Row == q.Row && p.Col < q.Col)
+}
+
+// ApplyEdits applies the edits as a single undoable action, with the same
+// semantics as editing interactively, such as for tools which edit the
+// content without a window. Positions are of the content before any of
+// the edits. If an edit is out of range, the edits overlap, or they would
+// change read-only content, an error is returned and nothing is edited.
+// The cursor is left at the start of the line of the first edit.
+func (e *Editor) ApplyEdits(ops []EditOp) error {
+	if e.read_only {
+		return fmt.Errorf("the content is read-only")
+	}
+
+	sorted := make([]EditOp, len(ops))
+	copy(sorted, ops)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.before(sorted[j].Start)
+	})
+
+	lines := e.lineCount()
+	for i, op := range sorted {
+		for _, pos := range []Position{op.Start, op.End} {
+			if pos.Row < 0 || pos.Row >= lines || pos.Col < 0 || pos.Col >= len(e.lineAt(pos.Row).values) {
+				return fmt.Errorf("edit position %v is out of range", pos)
+			}
+		}
+		if op.End.before(op.Start) {
+			return fmt.Errorf("edit ends at %v, before it starts at %v", op.End, op.Start)
+		}
+		if i > 0 && op.Start.before(sorted[i-1].End) {
+			return fmt.Errorf("edits at %v and %v overlap", sorted[i-1].Start, op.Start)
+		}
+		for row := op.Start.Row; row <= op.End.Row; row++ {
+			if e.lineAt(row).protected {
+				return fmt.Errorf("line %d is read-only", row+1)
+			}
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	// Edit from the end, so that the positions of the earlier edits hold.
+	// The cursor is moved to each edit, as it would be interactively.
+	e.resetHighlight()
+	e.ClearCarets()
+	fns := make([]func() bool, 0, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		op := sorted[i]
+		first, last := e.lineAt(op.Start.Row), e.lineAt(op.End.Row)
+
+		values := make([]rune, 0, op.Start.Col+len(op.Text)+len(last.values)-op.End.Col)
+		values = append(values, first.values[:op.Start.Col]...)
+		values = append(values, []rune(op.Text)...)
+		values = append(values, last.values[op.End.Col:]...)
+
+		e.cursor.line, e.cursor.x = first, 0
+		fns = append(fns, e.fnReplaceLines(op.Start.Row, op.End.Row-op.Start.Row+1, splitLines(values)))
+	}
+	e.storeUndoAction(fnCompound(fns...))
+
+	// Update the backing image.
+	e.updateImage()
+	return nil
+}
+
+// splitLines splits the runes, which end with '\n', into lines.
+func splitLines(values []rune) [][]rune {
+	var lines [][]rune
+	start := 0
+	for i, r := range values {
+		if r == '\n' {
+			lines = append(lines, values[start:i+1:i+1])
+			start = i + 1
+		}
+	}
+	return lines
+}
+
+// RunCommandSequence runs the named commands in order, as RunCommand, such
+// as to script edits without a window. If a command is unknown, an error
+// is returned and none are run.
+func (e *Editor) RunCommandSequence(names []string) error {
+	for _, name := range names {
+		if _, ok := e.commands[name]; !ok {
+			return fmt.Errorf("unknown command %q", name)
+		}
+	}
+	for _, name := range names {
+		e.RunCommand(name)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package noter
+
+import "testing"
+
+func TestApplyEdits(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("name = a\nsize = 1\nkind = b\n"))
+
+	err := editor.ApplyEdits([]EditOp{
+		{Start: Position{2, 7}, End: Position{2, 8}, Text: "c"},
+		{Start: Position{0, 0}, End: Position{0, 0}, Text: "# header\n"},
+		{Start: Position{1, 0}, End: Position{2, 0}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := string(editor.ReadText()); got != "# header\nname = a\nkind = c\n" {
+		t.Fatalf("Incorrect edits, got %q", got)
+	}
+
+	editor.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != "name = a\nsize = 1\nkind = b\n" {
+		t.Fatalf("Expected the edits to be undone at once, got %q", got)
+	}
+
+	table := [](struct {
+		name string
+		ops  []EditOp
+	}){
+		{"out of range", []EditOp{{Start: Position{9, 0}, End: Position{9, 0}}}},
+		{"past the end of the line", []EditOp{{Start: Position{0, 9}, End: Position{0, 9}}}},
+		{"reversed", []EditOp{{Start: Position{1, 0}, End: Position{0, 0}}}},
+		{"overlapping", []EditOp{
+			{Start: Position{0, 0}, End: Position{0, 4}},
+			{Start: Position{0, 2}, End: Position{0, 6}},
+		}},
+	}
+	for _, entry := range table {
+		if err := editor.ApplyEdits(entry.ops); err == nil {
+			t.Fatalf("Expected an error for edits %s", entry.name)
+		}
+	}
+
+	editor.SetLinesReadOnly(0, 0, true)
+	if err := editor.ApplyEdits([]EditOp{{Start: Position{0, 0}, End: Position{0, 1}}}); err == nil {
+		t.Fatalf("Expected an error for editing a read-only line")
+	}
+	if got := string(editor.ReadText()); got != "name = a\nsize = 1\nkind = b\n" {
+		t.Fatalf("Expected the content to be unchanged by failed edits, got %q", got)
+	}
+}
+
+func TestRunCommandSequence(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("ab\ncd\n"))
+
+	if err := editor.RunCommandSequence([]string{"DeleteLine", "Unknown"}); err == nil {
+		t.Fatalf("Expected an error for an unknown command")
+	}
+	if err := editor.RunCommandSequence([]string{"DeleteLine", "DeleteLine"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := string(editor.ReadText()); got != "" {
+		t.Fatalf("Expected both lines to be deleted, got %q", got)
+	}
+}
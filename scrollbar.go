@@ -0,0 +1,117 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// WithScrollbar shows a scrollbar at the right edge of the text, whose
+// thumb spans the lines in view. Clicking the bar moves the thumb there,
+// and dragging the thumb scrolls. The default is disabled.
+func WithScrollbar(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.scrollbar = enabled
+	}
+}
+
+// scrollbarLeft returns the left edge of the scrollbar, which is the
+// width of a column from the right edge.
+func (e *Editor) scrollbarLeft() int {
+	return e.width - e.font_info.xUnit
+}
+
+// scrollThumb returns the top and height of the thumb of the scrollbar,
+// in pixels. It returns false if all of the lines are in view.
+func (e *Editor) scrollThumb() (top, height int, ok bool) {
+	lines := e.lineCount()
+	if lines <= e.rows {
+		return 0, 0, false
+	}
+	track := float64(e.rows * e.font_info.yUnit)
+	top = e.top_padding + int(track*float64(e.firstVisible)/float64(lines))
+	height = int(math.Ceil(track * float64(e.rows) / float64(lines)))
+	if minHeight := e.font_info.yUnit / 2; height < minHeight {
+		height = minHeight
+	}
+	return top, height, true
+}
+
+// scrollThumbTo scrolls so that the top of the thumb is at y.
+func (e *Editor) scrollThumbTo(y int) {
+	track := float64(e.rows * e.font_info.yUnit)
+	first := int(math.Round(float64(y-e.top_padding) * float64(e.lineCount()) / track))
+	e.scrollBy(first - e.firstVisible)
+}
+
+// clickScrollbar starts dragging the thumb of the scrollbar if the point
+// is on the scrollbar, first moving the middle of the thumb to the point
+// if it's off the thumb. It returns true if the point is on the scrollbar.
+func (e *Editor) clickScrollbar(px, py int) bool {
+	if !e.scrollbar || px < e.scrollbarLeft() {
+		return false
+	}
+	top, height, ok := e.scrollThumb()
+	if !ok {
+		return true
+	}
+	if py < top || py >= top+height {
+		e.scrollThumbTo(py - height/2)
+		top, _, _ = e.scrollThumb()
+	}
+	e.thumbGrab = py - top
+	e.thumbDragging = true
+	return true
+}
+
+// dragScrollbar scrolls while the thumb of the scrollbar is dragged.
+// It returns true if the thumb is being dragged.
+func (e *Editor) dragScrollbar(py int, pressed bool) bool {
+	if !e.thumbDragging {
+		return false
+	}
+	if !pressed {
+		e.thumbDragging = false
+		return false
+	}
+	e.scrollThumbTo(py - e.thumbGrab)
+	return true
+}
+
+// drawScrollbar renders the thumb of the scrollbar, if enabled.
+func (e *Editor) drawScrollbar() {
+	if !e.scrollbar {
+		return
+	}
+	top, height, ok := e.scrollThumb()
+	if !ok {
+		return
+	}
+	left := e.scrollbarLeft() + e.font_info.xUnit/4
+	ebitenutil.DrawRect(e.screen,
+		float64(left), float64(top),
+		float64(e.font_info.xUnit/2), float64(height),
+		e.lineNumberColor())
+}
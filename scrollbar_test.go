@@ -0,0 +1,37 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScrollbar(t *testing.T) {
+	editor := NewEditor(WithRows(10), WithScrollbar(true))
+	editor.WriteText([]byte(strings.Repeat("line\n", 39)))
+	yUnit := editor.font_info.yUnit
+
+	top, height, ok := editor.scrollThumb()
+	if !ok || top != 0 || height != 10*yUnit/4 {
+		t.Fatalf("Incorrect thumb, expected 0 and %v, got %v and %v", 10*yUnit/4, top, height)
+	}
+
+	// Dragging the thumb to the middle of the track shows the middle lines.
+	editor.scrollThumbTo(5 * yUnit)
+	if editor.firstVisible != 20 {
+		t.Fatalf("Expected line 20 to be first visible, got %v", editor.firstVisible)
+	}
+	if top, _, _ := editor.scrollThumb(); top != 5*yUnit {
+		t.Fatalf("Expected the thumb at %v, got %v", 5*yUnit, top)
+	}
+
+	// The thumb stops at the end of the track.
+	editor.scrollThumbTo(20 * yUnit)
+	if editor.firstVisible != 30 {
+		t.Fatalf("Expected line 30 to be first visible, got %v", editor.firstVisible)
+	}
+
+	editor.WriteText([]byte("short\n"))
+	if _, _, ok := editor.scrollThumb(); ok {
+		t.Fatalf("Expected no thumb when all of the lines are in view")
+	}
+}
@@ -0,0 +1,77 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"unicode"
+)
+
+// WithSearchCaseSensitive makes searches match case. The default is to
+// ignore case. OPTION-C toggles this while searching.
+func WithSearchCaseSensitive(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.search_case = enabled
+	}
+}
+
+// SearchCaseSensitive returns true if searches match case.
+func (e *Editor) SearchCaseSensitive() bool {
+	return e.search_case
+}
+
+// SetSearchCaseSensitive enables or disables case sensitive searches, and
+// repeats any current search.
+func (e *Editor) SetSearchCaseSensitive(enabled bool) {
+	e.search_case = enabled
+	if e.mode == SEARCH_MODE {
+		e.search()
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// searchRuneMatches returns true if the rune of the content matches the
+// rune of the search term.
+func (e *Editor) searchRuneMatches(term, r rune) bool {
+	if e.search_case {
+		return term == r
+	}
+	return unicode.ToLower(term) == unicode.ToLower(r)
+}
+
+// searchPrompt returns the prompt of the search bar, which shows the
+// search options in use.
+func (e *Editor) searchPrompt() string {
+	prompt := ""
+	if e.regex_search {
+		prompt += e.tr("regex") + " "
+	}
+	if e.search_case {
+		prompt += "Aa "
+	}
+	if e.search_word {
+		prompt += e.tr("word") + " "
+	}
+	return prompt + ">"
+}
@@ -0,0 +1,36 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestSearchCaseSensitive(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("Go go GO\n"))
+
+	table := [](struct {
+		regex, sensitive bool
+		term             string
+		matches          int
+	}){
+		{false, false, "go", 3},
+		{false, true, "go", 1},
+		{false, true, "GO", 1},
+		{true, false, "g.", 3},
+		{true, true, "G.", 2},
+	}
+
+	for _, entry := range table {
+		editor.SetRegexSearch(entry.regex)
+		editor.SetSearchCaseSensitive(entry.sensitive)
+		editor.Search(entry.term)
+		if editor.searchMatches != entry.matches {
+			t.Fatalf("Incorrect matches for %q (regex %v, case %v), expected %v, got %v",
+				entry.term, entry.regex, entry.sensitive, entry.matches, editor.searchMatches)
+		}
+	}
+
+	if prompt := editor.searchPrompt(); prompt != "regex Aa >" {
+		t.Fatalf("Incorrect search prompt, got: %q", prompt)
+	}
+}
@@ -0,0 +1,20 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestSelectingEndsOnEdit(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("abc\n"))
+
+	editor.SetSelecting(true)
+	if !editor.Selecting() {
+		t.Fatalf("Expected selection mode to be on")
+	}
+
+	editor.storeUndoAction(editor.fnHandleRuneSingle('x'))
+	if editor.Selecting() {
+		t.Fatalf("Expected editing to end selection mode")
+	}
+}
@@ -0,0 +1,187 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// wrapMarker is drawn at the end of a row which continues onto the next row.
+const wrapMarker = '↪'
+
+// WithSoftWrap enables soft wrapping, where lines which are too long to fit
+// are continued onto the following rows, rather than scrolling horizontally.
+// The content itself is not modified.
+func WithSoftWrap(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.soft_wrap = enabled
+	}
+}
+
+// SoftWrap returns true if soft wrapping is enabled.
+func (e *Editor) SoftWrap() bool {
+	return e.soft_wrap
+}
+
+// SetSoftWrap enables or disables soft wrapping.
+func (e *Editor) SetSoftWrap(enabled bool) {
+	e.soft_wrap = enabled
+	e.fixPosition()
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// wrapWidth returns the number of display runes per wrapped row.
+// The final column is kept clear for the wrap marker.
+func (e *Editor) wrapWidth() int {
+	width := e.textColumns() - 1
+	if width < 1 {
+		width = 1
+	}
+	return width
+}
+
+// wrapRows splits a line into rows, as display positions [start, end).
+// Rows are broken after the last space which fits, or mid-word if there is
+// none, where wide runes cover two columns. The final '\n' of the line may
+// use the column of the wrap marker.
+func (e *Editor) wrapRows(view *lineView) (rows [][2]int) {
+	width := e.wrapWidth()
+	content := len(view.runes) - 1
+	for start := 0; ; {
+		end := e.columnEnd(view.runes[:content], start, width)
+		if end >= content {
+			return append(rows, [2]int{start, len(view.runes)})
+		}
+		if end == start {
+			// A rune wider than the row has a row of its own.
+			end++
+		}
+		for i := end - 1; i > start; i-- {
+			if view.runes[i] == ' ' {
+				end = i + 1
+				break
+			}
+		}
+		rows = append(rows, [2]int{start, end})
+		start = end
+	}
+}
+
+// wrapRow returns the row holding the display position.
+func wrapRow(rows [][2]int, display int) int {
+	for i, row := range rows {
+		if display < row[1] {
+			return i
+		}
+	}
+	return len(rows) - 1
+}
+
+// drawWrapMarker renders the wrap marker after the row from start to end.
+func (e *Editor) drawWrapMarker(y int, view *lineView, start, end int, markerColor color.Color) {
+	x := e.textLeft() + font.MeasureString(e.font_info.face, string(view.runes[start:end])).Ceil()
+	text.Draw(e.screen, string(wrapMarker), e.font_info.face,
+		x, e.top_padding+y*e.font_info.yUnit+e.font_info.ascent,
+		markerColor)
+}
+
+// fixWrappedPosition scrolls down until the row of the cursor, on the line
+// number lineno, is within the view.
+func (e *Editor) fixWrappedPosition(lineno int) {
+	for e.firstVisible < lineno {
+		line := e.start
+		for i := 0; i < e.firstVisible && line.next != nil; i++ {
+			line = line.next
+		}
+
+		// Count the rows from the first visible line to the cursor.
+		rows := 0
+		for ; line != e.cursor.line && line != nil; line = line.next {
+			rows += len(e.wrapRows(e.lineView(line)))
+		}
+		view := e.lineView(e.cursor.line)
+		rows += wrapRow(e.wrapRows(view), view.index[e.cursor.x]) + 1
+
+		if rows <= e.rows {
+			return
+		}
+		e.firstVisible++
+	}
+}
+
+// moveVisualRow moves the cursor up (dir < 0) or down (dir > 0) by one row,
+// keeping to the same column where possible. The runes passed over are
+// highlighted if shift is held.
+func (e *Editor) moveVisualRow(dir int, shift bool) {
+	line := e.cursor.line
+	view := e.lineView(line)
+	rows := e.wrapRows(view)
+	row := wrapRow(rows, view.index[e.cursor.x])
+	col := view.index[e.cursor.x] - rows[row][0]
+
+	switch {
+	case dir < 0 && row > 0:
+		row--
+	case dir > 0 && row < len(rows)-1:
+		row++
+	case dir < 0 && line.prev != nil:
+		line = line.prev
+		view = e.lineView(line)
+		rows = e.wrapRows(view)
+		row = len(rows) - 1
+	case dir > 0 && line.next != nil:
+		line = line.next
+		view = e.lineView(line)
+		rows = e.wrapRows(view)
+		row = 0
+	case dir < 0:
+		// Already on the first row, so move to its start.
+		col = 0
+	default:
+		// Already on the last row.
+		return
+	}
+
+	display := rows[row][0] + col
+	if display > rows[row][1]-1 {
+		display = rows[row][1] - 1
+	}
+	x := view.position(display)
+
+	if shift {
+		if dir < 0 {
+			e.highlightBetween(line, x, e.cursor.line, e.cursor.x)
+		} else {
+			e.highlightBetween(e.cursor.line, e.cursor.x, line, x)
+		}
+	}
+
+	e.cursor.line = line
+	e.cursor.x = x
+	e.fixPosition()
+}
@@ -0,0 +1,65 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapRows(t *testing.T) {
+	editor := NewEditor(WithColumns(10), WithSoftWrap(true))
+
+	table := [](struct {
+		text string
+		rows [][2]int
+	}){
+		{"\n", [][2]int{{0, 1}}},
+		{"abcdefghi\n", [][2]int{{0, 10}}},
+		{"abcdefghij\n", [][2]int{{0, 9}, {9, 11}}},
+		{"abcdefghijklmnopqrst\n", [][2]int{{0, 9}, {9, 18}, {18, 21}}},
+		{"abc defghijkl\n", [][2]int{{0, 4}, {4, 14}}},
+		{"ab cd efgh ijklmn\n", [][2]int{{0, 6}, {6, 11}, {11, 18}}},
+	}
+
+	for _, entry := range table {
+		rows := editor.wrapRows(newLineView([]rune(entry.text)))
+		if !reflect.DeepEqual(rows, entry.rows) {
+			t.Fatalf("Incorrect rows for %q, expected %v, got %v", entry.text, entry.rows, rows)
+		}
+	}
+}
+
+func TestMoveVisualRow(t *testing.T) {
+	editor := NewEditor(WithColumns(10), WithSoftWrap(true))
+	editor.WriteText([]byte("abcdefghijklmnopqrst\nxy\n"))
+	editor.MoveCursor(0, 3)
+
+	table := [](struct{ dir, row, col int }){
+		{1, 0, 12},
+		{1, 0, 20},
+		{1, 1, 2},
+		{-1, 0, 20},
+		{-1, 0, 11},
+		{-1, 0, 2},
+		{-1, 0, 0},
+	}
+
+	for _, entry := range table {
+		editor.moveVisualRow(entry.dir, false)
+		row, col := editor.Cursor()
+		if row != entry.row || col != entry.col {
+			t.Fatalf("Incorrect move by %v, expected (%v,%v), got (%v,%v)", entry.dir, entry.row, entry.col, row, col)
+		}
+	}
+}
+
+func TestDrawCursorPastShorterLine(t *testing.T) {
+	editor := NewEditor(WithColumns(10), WithSoftWrap(true))
+	editor.WriteText([]byte("abcdefghijklmnopqrst\nxy\n"))
+	editor.MoveCursor(0, 20)
+
+	// Rendering the shorter line must not index its view by the cursor.
+	editor.updateImage()
+	if row, col := editor.Cursor(); row != 0 || col != 20 {
+		t.Fatalf("Incorrect cursor, expected (0,20), got (%v,%v)", row, col)
+	}
+}
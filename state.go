@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// editorState is the cursor, selection and scroll position of the editor,
+// by row, so that it can be restored after the lines have changed.
+type editorState struct {
+	row, x       int
+	selection    map[int]map[int]bool
+	firstVisible int
+}
+
+// captureState returns the current editorState.
+func (e *Editor) captureState() editorState {
+	state := editorState{
+		selection:    make(map[int]map[int]bool, len(e.highlighted)),
+		firstVisible: e.firstVisible,
+	}
+	row := 0
+	for line := e.start; line != nil; line = line.next {
+		if line == e.cursor.line {
+			state.row, state.x = row, e.cursor.x
+		}
+		if highlighted, ok := e.highlighted[line]; ok {
+			state.selection[row] = make(map[int]bool, len(highlighted))
+			for x := range highlighted {
+				state.selection[row][x] = true
+			}
+		}
+		row++
+	}
+	return state
+}
+
+// restoreState restores the cursor, selection and scroll position.
+func (e *Editor) restoreState(state editorState) {
+	e.MoveCursor(state.row, state.x)
+
+	e.resetHighlight()
+	row := 0
+	for line := e.start; line != nil; line = line.next {
+		for x := range state.selection[row] {
+			if x < len(line.values) {
+				e.highlight(line, x)
+			}
+		}
+		row++
+	}
+
+	e.firstVisible = state.firstVisible
+	e.fixPosition()
+}
+
+// lineAt returns the line at the row, or the last line.
+func (e *Editor) lineAt(row int) *editorLine {
+	if row < 0 {
+		row = 0
+	}
+	if count := e.lineCount(); row >= count {
+		row = count - 1
+	}
+	line := e.lines.root
+	for {
+		left := treeSize(line.tree.left)
+		switch {
+		case row < left:
+			line = line.tree.left
+		case row == left:
+			return line
+		default:
+			row -= left + 1
+			line = line.tree.right
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestSwapLineRestoresState(t *testing.T) {
+	editor := NewEditor(WithRows(2))
+	editor.WriteText([]byte("one\ntwo\nthree\nfour\n"))
+	editor.MoveCursor(2, 2)
+	editor.highlightBetween(editor.lineAt(2), 0, editor.lineAt(2), 2)
+	firstVisible := editor.firstVisible
+
+	editor.storeUndoAction(editor.fnSwapUp())
+	if got := string(editor.ReadText()); got != "one\nthree\ntwo\nfour\n" {
+		t.Fatalf("Incorrect swap, got: %q", got)
+	}
+	if got := string(editor.getHighlightedRunes()); got != "th" {
+		t.Fatalf("Expected the selection to move with the line, got: %q", got)
+	}
+
+	editor.MoveCursor(3, 0)
+	editor.resetHighlight()
+	editor.undoStack[0]()
+	if got := string(editor.ReadText()); got != "one\ntwo\nthree\nfour\n" {
+		t.Fatalf("Incorrect undo of swap, got: %q", got)
+	}
+	if row, col := editor.Cursor(); row != 2 || col != 2 || editor.firstVisible != firstVisible {
+		t.Fatalf("Expected the cursor and scroll to be restored, got: %v:%v, %v", row, col, editor.firstVisible)
+	}
+	if got := string(editor.getHighlightedRunes()); got != "th" {
+		t.Fatalf("Expected the selection to be restored, got: %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// surroundPairs maps the runes which surround a selection to their closing rune.
+var surroundPairs = map[rune]rune{
+	'(':  ')',
+	'[':  ']',
+	'{':  '}',
+	'"':  '"',
+	'\'': '\'',
+	'`':  '`',
+}
+
+// WithAutoSurround sets whether typing an opening quote or bracket while text
+// is selected surrounds the selection with the pair, rather than replacing
+// it. The default is enabled.
+func WithAutoSurround(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.auto_surround = enabled
+	}
+}
+
+// fnSurroundSelection surrounds the selection with the pair of runes.
+// The surrounded text stays selected. A selection which ends with a line's
+// '\n' is closed before it, on the same line.
+func (e *Editor) fnSurroundSelection(open, close rune) func() bool {
+	first, firstX, last, lastX, ok := e.selectionBounds()
+	if !ok {
+		return noop
+	}
+
+	firstRow := e.getLineNumberFromLine(first) - 1
+	lastRow := e.getLineNumberFromLine(last) - 1
+
+	closeX := lastX + 1
+	if last.values[lastX] == '\n' {
+		closeX = lastX
+	}
+
+	// Insert the closing rune first, so the opening position is unchanged.
+	e.cursor.line, e.cursor.x = last, closeX
+	e.handleRune(close)
+	e.cursor.line, e.cursor.x = first, firstX
+	e.handleRune(open)
+
+	// Keep the original text selected.
+	if first == last {
+		closeX++
+	}
+	e.highlightBetween(first, firstX+1, last, closeX)
+	e.cursor.line, e.cursor.x = last, closeX
+	e.fixPosition()
+
+	return func() bool {
+		e.MoveCursor(lastRow, closeX+1)
+		e.deletePrevious()
+		e.MoveCursor(firstRow, firstX+1)
+		e.deletePrevious()
+		return true
+	}
+}
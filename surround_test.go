@@ -0,0 +1,87 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestSurroundSelection(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("say hello world\nnext\n"))
+
+	editor.highlightBetween(editor.start, 4, editor.start, 9)
+	editor.storeUndoAction(editor.fnTypeRune('"'))
+
+	want := "say \"hello\" world\nnext\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect surround, expected %q, got: %q", want, got)
+	}
+	if got := string(editor.getHighlightedRunes()); got != "hello" {
+		t.Fatalf("Expected the surrounded text to stay selected, got: %q", got)
+	}
+
+	// Surround again, across lines.
+	editor.resetHighlight()
+	editor.highlightBetween(editor.start, 12, editor.start.next, 2)
+	editor.storeUndoAction(editor.fnTypeRune('('))
+
+	want = "say \"hello\" (world\nne)xt\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect surround, expected %q, got: %q", want, got)
+	}
+
+	// Each surround is a single undo step.
+	editor.undoStack[len(editor.undoStack)-1]()
+	editor.undoStack[len(editor.undoStack)-2]()
+	want = "say hello world\nnext\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Incorrect undo of surround, expected %q, got: %q", want, got)
+	}
+}
+
+func TestSurroundDisabled(t *testing.T) {
+	editor := NewEditor(WithAutoSurround(false))
+	editor.WriteText([]byte("hello\n"))
+
+	editor.highlightBetween(editor.start, 0, editor.start, 5)
+	editor.storeUndoAction(editor.fnTypeRune('('))
+
+	want := "(\n"
+	if got := string(editor.ReadText()); got != want {
+		t.Fatalf("Expected the selection to be replaced, expected %q, got: %q", want, got)
+	}
+}
+
+func TestSurroundSelectionLineEnd(t *testing.T) {
+	table := [](struct {
+		firstX, lastRow, lastX int
+		want, selected         string
+	}){
+		{0, 0, 3, "(ab)\ncd\n", "ab"},
+		{1, 1, 3, "a(b\ncd)\n", "b\ncd"},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte("ab\ncd\n"))
+
+		// Select through the '\n' of the last line.
+		last := editor.start
+		for row := 0; row < entry.lastRow; row++ {
+			last = last.next
+		}
+		editor.highlightBetween(editor.start, entry.firstX, last, entry.lastX)
+		editor.storeUndoAction(editor.fnTypeRune('('))
+
+		if got := string(editor.ReadText()); got != entry.want {
+			t.Fatalf("Incorrect surround, expected %q, got: %q", entry.want, got)
+		}
+		if got := string(editor.getHighlightedRunes()); got != entry.selected {
+			t.Fatalf("Incorrect selection, expected %q, got: %q", entry.selected, got)
+		}
+
+		editor.undoStack[len(editor.undoStack)-1]()
+		if got := string(editor.ReadText()); got != "ab\ncd\n" {
+			t.Fatalf("Incorrect undo of surround, got: %q", got)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+// colorPattern matches color literals, such as "#ff8000", "#f80",
+// "#ff800080", "rgb(255, 128, 0)" or "rgba(255, 128, 0, 0.5)".
+var colorPattern = regexp.MustCompile(`#(?:[0-9a-fA-F]{8}|[0-9a-fA-F]{6}|[0-9a-fA-F]{3})\b|rgba?\(\s*\d{1,3}\s*,\s*\d{1,3}\s*,\s*\d{1,3}\s*(?:,\s*[0-9.]+\s*)?\)`)
+
+// colorSpan is a color literal, from the rune start up to end.
+type colorSpan struct {
+	start, end int
+	text       string
+	color      color.NRGBA
+}
+
+// WithColorSwatches draws a swatch of the color after each color literal,
+// such as "#ff8000" or "rgba(255, 128, 0, 0.5)". The default is disabled.
+func WithColorSwatches(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.color_swatches = enabled
+	}
+}
+
+// WithColorPicker sets the function called when a swatch is clicked, or
+// the PickColor command is run on a color literal, with the color of the
+// literal. The picker calls set with the chosen color, to write it back in
+// the same form, such as "#rrggbb". set may be called later, and from any
+// goroutine, as it's applied with PostEdit. The default is no picker.
+func WithColorPicker(pick func(c color.NRGBA, set func(color.NRGBA))) EditorOption {
+	return func(e *Editor) {
+		e.color_picker = pick
+	}
+}
+
+// parseColorLiteral returns the color of a literal matching colorPattern.
+func parseColorLiteral(literal string) (color.NRGBA, bool) {
+	if hex := strings.TrimPrefix(literal, "#"); hex != literal {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 8 {
+			return color.NRGBA{}, false
+		}
+		return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+	}
+
+	_, args, _ := strings.Cut(strings.TrimSuffix(literal, ")"), "(")
+	parts := strings.Split(args, ",")
+	c := color.NRGBA{A: 0xff}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if i == 3 {
+			a, err := strconv.ParseFloat(part, 64)
+			if err != nil || a > 1 {
+				return color.NRGBA{}, false
+			}
+			c.A = uint8(math.Round(a * 0xff))
+			break
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil || v > 0xff {
+			return color.NRGBA{}, false
+		}
+		switch i {
+		case 0:
+			c.R = uint8(v)
+		case 1:
+			c.G = uint8(v)
+		case 2:
+			c.B = uint8(v)
+		}
+	}
+	return c, true
+}
+
+// formatColorLiteral returns the color written in the form of the literal.
+// Hexadecimal colors keep their case, and gain an alpha if needed.
+func formatColorLiteral(literal string, c color.NRGBA) string {
+	if strings.HasPrefix(literal, "#") {
+		out := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+		if c.A != 0xff || len(literal) == 9 {
+			out += fmt.Sprintf("%02x", c.A)
+		}
+		if strings.ToUpper(literal) == literal {
+			out = strings.ToUpper(out)
+		}
+		return out
+	}
+	if c.A != 0xff || strings.HasPrefix(literal, "rgba") {
+		a := strconv.FormatFloat(math.Round(float64(c.A)/0xff*100)/100, 'f', -1, 64)
+		return fmt.Sprintf("rgba(%d, %d, %d, %s)", c.R, c.G, c.B, a)
+	}
+	return fmt.Sprintf("rgb(%d, %d, %d)", c.R, c.G, c.B)
+}
+
+// colorSpans returns the color literals of the line, in order.
+func colorSpans(values []rune) (spans []colorSpan) {
+	s := string(values)
+	for _, m := range colorPattern.FindAllStringIndex(s, -1) {
+		c, ok := parseColorLiteral(s[m[0]:m[1]])
+		if !ok {
+			continue
+		}
+		start := utf8.RuneCountInString(s[:m[0]])
+		spans = append(spans, colorSpan{start, start + utf8.RuneCountInString(s[m[0]:m[1]]), s[m[0]:m[1]], c})
+	}
+	return spans
+}
+
+// withSwatches returns the view with a column after each color literal, for
+// its swatch to be drawn in. The column is selected with the literal's last
+// rune.
+func (e *Editor) withSwatches(v *lineView, spans []colorSpan) *lineView {
+	if len(spans) == 0 {
+		return v
+	}
+	if e.swatchToken == nil {
+		e.swatchToken = &inlineToken{columns: 1, render: e.swatchImage}
+	}
+
+	swatched := &lineView{
+		runes: make([]rune, 0, len(v.runes)+len(spans)),
+		index: make([]int, len(v.index)),
+	}
+	if v.colors != nil {
+		swatched.colors = make([]color.Color, 0, cap(swatched.runes))
+	}
+
+	// moved[d] is the display position which d moves to.
+	moved := make([]int, len(v.runes)+1)
+	next := 0
+	for d := 0; d <= len(v.runes); d++ {
+		for next < len(spans) && v.index[spans[next].end] == d {
+			swatched.tokens = append(swatched.tokens, viewToken{len(swatched.runes), spans[next].text, e.swatchToken})
+			swatched.runes = append(swatched.runes, ' ')
+			if v.colors != nil {
+				swatched.colors = append(swatched.colors, nil)
+			}
+			next++
+		}
+		moved[d] = len(swatched.runes)
+		if d == len(v.runes) {
+			break
+		}
+		swatched.runes = append(swatched.runes, v.runes[d])
+		if v.colors != nil {
+			swatched.colors = append(swatched.colors, v.colors[d])
+		}
+	}
+
+	for x, d := range v.index {
+		swatched.index[x] = moved[d]
+	}
+	for _, t := range v.tokens {
+		t.display = moved[t.display]
+		swatched.tokens = append(swatched.tokens, t)
+	}
+	return swatched
+}
+
+// swatchImage returns the image of the swatch of a color literal.
+func (e *Editor) swatchImage(literal string) *ebiten.Image {
+	if image, ok := e.swatchImages[literal]; ok {
+		return image
+	}
+	c, ok := parseColorLiteral(literal)
+	if !ok {
+		return nil
+	}
+
+	// Keep only the swatches of recent literals.
+	if e.swatchImages == nil || len(e.swatchImages) > 256 {
+		e.swatchImages = make(map[string]*ebiten.Image)
+	}
+	image := ebiten.NewImage(1, 1)
+	image.Fill(c)
+	e.swatchImages[literal] = image
+	return image
+}
+
+// clickSwatch opens the color picker if the point is on a swatch,
+// returning true if it did.
+func (e *Editor) clickSwatch(px, py int) bool {
+	if e.color_picker == nil || len(e.screenRows) == 0 {
+		return false
+	}
+	sr := e.screenRowAt(py)
+	for _, t := range sr.view.tokens {
+		if t.token != e.swatchToken || t.display < sr.start || t.display >= sr.end {
+			continue
+		}
+		left := e.textLeft() + font.MeasureString(e.font_info.face, string(sr.view.runes[sr.start:t.display])).Floor()
+		if px >= left && px < left+e.font_info.xUnit {
+			return e.pickColor(sr.line, sr.view.position(t.display))
+		}
+	}
+	return false
+}
+
+// pickColor opens the color picker for the color literal touching x on
+// the line, returning false if there is none.
+func (e *Editor) pickColor(line *editorLine, x int) bool {
+	if e.color_picker == nil || !e.canEdit() {
+		return false
+	}
+	for _, span := range colorSpans(line.values) {
+		if x < span.start || x > span.end {
+			continue
+		}
+		e.color_picker(span.color, func(c color.NRGBA) {
+			e.PostEdit(func(e *Editor) {
+				e.replaceColor(line, span, c)
+			})
+		})
+		return true
+	}
+	return false
+}
+
+// replaceColor writes the color over the literal of the span, if the
+// literal is still there.
+func (e *Editor) replaceColor(line *editorLine, span colorSpan, c color.NRGBA) {
+	row := e.getLineNumberFromLine(line) - 1
+	if row >= e.lineCount() || span.end > len(line.values) || string(line.values[span.start:span.end]) != span.text {
+		return
+	}
+
+	values := make([]rune, 0, len(line.values))
+	values = append(values, line.values[:span.start]...)
+	values = append(values, []rune(formatColorLiteral(span.text, c))...)
+	values = append(values, line.values[span.end:]...)
+
+	curRow, curX := e.getLineNumber(), e.cursor.x
+	e.storeUndoAction(e.fnReplaceLines(row, 1, [][]rune{values}))
+	e.TryMoveCursor(curRow, curX)
+}
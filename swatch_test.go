@@ -0,0 +1,70 @@
+package noter
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColorLiterals(t *testing.T) {
+	table := [](struct {
+		literal string
+		color   color.NRGBA
+		set     color.NRGBA
+		want    string
+	}){
+		{"#ff8000", color.NRGBA{0xff, 0x80, 0x00, 0xff}, color.NRGBA{0x10, 0x20, 0x30, 0xff}, "#102030"},
+		{"#F80", color.NRGBA{0xff, 0x88, 0x00, 0xff}, color.NRGBA{0xab, 0xcd, 0xef, 0x80}, "#ABCDEF80"},
+		{"#ff800080", color.NRGBA{0xff, 0x80, 0x00, 0x80}, color.NRGBA{0, 0, 0, 0xff}, "#000000ff"},
+		{"rgb(255, 128, 0)", color.NRGBA{0xff, 0x80, 0x00, 0xff}, color.NRGBA{1, 2, 3, 0xff}, "rgb(1, 2, 3)"},
+		{"rgba(255,128,0,0.5)", color.NRGBA{0xff, 0x80, 0x00, 0x80}, color.NRGBA{1, 2, 3, 0x40}, "rgba(1, 2, 3, 0.25)"},
+	}
+
+	for _, entry := range table {
+		c, ok := parseColorLiteral(entry.literal)
+		if !ok || c != entry.color {
+			t.Fatalf("Incorrect color of %q, expected %v, got %v", entry.literal, entry.color, c)
+		}
+		if got := formatColorLiteral(entry.literal, entry.set); got != entry.want {
+			t.Fatalf("Incorrect literal for %q, expected %q, got %q", entry.literal, entry.want, got)
+		}
+	}
+}
+
+func TestColorSwatches(t *testing.T) {
+	editor := NewEditor(WithColorSwatches(true))
+	editor.WriteText([]byte("a #fff b\n"))
+
+	view := editor.lineView(editor.start)
+	if got := string(view.runes); got != "a #fff  b\n" {
+		t.Fatalf("Expected a column for the swatch, got: %q", got)
+	}
+	if len(view.tokens) != 1 || view.tokens[0].display != 6 {
+		t.Fatalf("Expected a swatch after the literal, got: %v", view.tokens)
+	}
+	if view.index[5] != 5 || view.index[6] != 7 {
+		t.Fatalf("Expected the swatch to follow the literal, got: %v", view.index)
+	}
+}
+
+func TestPickColor(t *testing.T) {
+	var picked color.NRGBA
+	editor := NewEditor(WithColorPicker(func(c color.NRGBA, set func(color.NRGBA)) {
+		picked = c
+		set(color.NRGBA{0, 0, 0xff, 0xff})
+	}))
+	editor.WriteText([]byte("font = \"#ff0000\"\n"))
+	editor.MoveCursor(0, 10)
+
+	editor.RunCommand("PickColor")
+	if picked != (color.NRGBA{0xff, 0, 0, 0xff}) {
+		t.Fatalf("Expected the picker to be given the color, got: %v", picked)
+	}
+
+	editor.runPosted()
+	if got := string(editor.ReadText()); got != "font = \"#0000ff\"\n" {
+		t.Fatalf("Expected the chosen color to be written, got: %q", got)
+	}
+	if row, col := editor.Cursor(); row != 0 || col != 10 {
+		t.Fatalf("Expected the cursor to stay, got (%v,%v)", row, col)
+	}
+}
@@ -0,0 +1,174 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// EDITOR_RECENT_NAMES is the number of recently opened names the switcher
+// remembers.
+const EDITOR_RECENT_NAMES = 10
+
+// WithSwitcher enables the switcher, COMMAND-P, which fuzzy-matches what is
+// typed against the content names returned by names, and opens the chosen
+// name with the Content returned by open, or does nothing if it's nil.
+// Recently opened names are ranked first.
+func WithSwitcher(names func() []string, open func(name string) Content) EditorOption {
+	return func(e *Editor) {
+		e.switch_names = names
+		e.switch_open = open
+	}
+}
+
+// fuzzyScore returns how well the pattern matches the name, or false if
+// the runes of the pattern don't all appear in order within the name.
+// Consecutive runes, and runes at the start of a word, score higher.
+func fuzzyScore(pattern, name string) (int, bool) {
+	score := 0
+	consecutive := false
+	rs := []rune(name)
+	i := 0
+	for _, p := range strings.ToLower(pattern) {
+		found := false
+		for ; i < len(rs); i++ {
+			if unicode.ToLower(rs[i]) != p {
+				consecutive = false
+				continue
+			}
+			score++
+			if consecutive {
+				score += 2
+			}
+			if i == 0 || !unicode.IsLetter(rs[i-1]) || (unicode.IsUpper(rs[i]) && unicode.IsLower(rs[i-1])) {
+				score += 3
+			}
+			consecutive, found = true, true
+			i++
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// switcherMatches returns the names which match the pattern, the recently
+// opened first, then the best matches. With no pattern, the names are
+// otherwise in the order given.
+func (e *Editor) switcherMatches(pattern string, names []string) []string {
+	recent := make(map[string]int, len(e.recentNames))
+	for i, name := range e.recentNames {
+		recent[name] = len(e.recentNames) - i
+	}
+
+	type match struct {
+		name          string
+		recent, score int
+	}
+	var matches []match
+	for _, name := range names {
+		if score, ok := fuzzyScore(pattern, name); ok {
+			matches = append(matches, match{name, recent[name], score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].recent != matches[j].recent {
+			return matches[i].recent > matches[j].recent
+		}
+		if pattern == "" || matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		// Of equal matches, the shortest is closest.
+		return len(matches[i].name) < len(matches[j].name)
+	})
+
+	items := make([]string, len(matches))
+	for i, m := range matches {
+		items[i] = m.name
+	}
+	return items
+}
+
+// showSwitcher prompts for a name to open, listing the matches below the
+// top bar as it's typed.
+func (e *Editor) showSwitcher() {
+	if e.switch_names == nil {
+		return
+	}
+	names := e.switch_names()
+
+	popup := &Popup{
+		Items:  e.switcherMatches("", names),
+		Anchor: image.Rect(e.width_padding, 0, e.width_padding+1, e.top_padding),
+	}
+	popup.OnChoose = func(e *Editor, index int) {
+		name := popup.Items[index]
+		e.editMode()
+		e.switchTo(name, 0, 0)
+	}
+	popup.OnDismiss = func(e *Editor) {
+		e.editMode()
+	}
+
+	e.promptMode(e.tr("Open: "), nil)
+	e.promptInput = func(input string) {
+		popup.Items = e.switcherMatches(input, names)
+		popup.Selected, popup.first = 0, 0
+	}
+	e.ShowPopup(popup)
+}
+
+// addRecent ranks the name first of the recently opened names.
+func (e *Editor) addRecent(name string) {
+	recent := []string{name}
+	for _, other := range e.recentNames {
+		if other != name && len(recent) < EDITOR_RECENT_NAMES {
+			recent = append(recent, other)
+		}
+	}
+	e.recentNames = recent
+}
+
+// switchTo opens the named content at the row and column, unless the
+// current content has unsaved changes.
+func (e *Editor) switchTo(name string, row, col int) {
+	if e.modified {
+		e.Notify(e.tr("save the changes before opening %s", name))
+		return
+	}
+	content := e.switch_open(name)
+	if content == nil {
+		return
+	}
+
+	if e.content_name != "" {
+		e.addRecent(e.content_name)
+	}
+	e.addRecent(name)
+	e.OpenAt(content, name, row, col)
+}
@@ -0,0 +1,59 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSwitcherMatches(t *testing.T) {
+	editor := NewEditor()
+	names := []string{"notes.txt", "todo.md", "main_test.go", "main.go", "README.md"}
+
+	table := [](struct {
+		pattern string
+		matches []string
+	}){
+		{"", names},
+		{"mgo", []string{"main.go", "main_test.go"}},
+		{"md", []string{"todo.md", "README.md"}},
+		{"rdme", []string{"README.md"}},
+		{"xyz", []string{}},
+	}
+	for _, entry := range table {
+		if matches := editor.switcherMatches(entry.pattern, names); !reflect.DeepEqual(matches, entry.matches) {
+			t.Fatalf("Incorrect matches of %q, expected %v, got %v", entry.pattern, entry.matches, matches)
+		}
+	}
+
+	// Recently opened names are ranked first.
+	editor.addRecent("main_test.go")
+	if matches := editor.switcherMatches("mgo", names); matches[0] != "main_test.go" {
+		t.Fatalf("Expected the recent name first, got %v", matches)
+	}
+}
+
+func TestSwitcher(t *testing.T) {
+	contents := map[string]string{"a.txt": "apple\n", "b.txt": "banana\n"}
+	editor := NewEditor(
+		WithContentName("a.txt"),
+		WithSwitcher(
+			func() []string { return []string{"a.txt", "b.txt"} },
+			func(name string) Content { return &dummyContent{contents[name]} },
+		),
+	)
+
+	editor.RunCommand("Switch")
+	editor.handleRune('b')
+	popup, ok := editor.TopOverlay().(*Popup)
+	if !ok || !reflect.DeepEqual(popup.Items, []string{"b.txt"}) {
+		t.Fatalf("Expected the switcher to list the matches")
+	}
+	popup.choose(editor)
+
+	if editor.Mode() != EDIT_MODE || editor.ContentName() != "b.txt" || string(editor.ReadText()) != "banana\n" {
+		t.Fatalf("Expected b.txt to be opened, got %q", editor.ContentName())
+	}
+	if !reflect.DeepEqual(editor.recentNames, []string{"b.txt", "a.txt"}) {
+		t.Fatalf("Incorrect recent names, got %v", editor.recentNames)
+	}
+}
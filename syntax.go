@@ -0,0 +1,112 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+)
+
+// Span is a run of runes in a line, from Start up to End, drawn in Color.
+type Span struct {
+	Start, End int
+	Color      color.Color
+}
+
+// Highlighter colors lines of text, such as for language-aware syntax
+// highlighting. Tokenize is called for each line in order, without its
+// final '\n', and with the state returned for the previous line (nil for
+// the first line), so that constructs such as block comments may span lines.
+// The state returned for each line is kept until the line or one before it
+// is edited, so Tokenize must not change a state it is passed.
+type Highlighter interface {
+	Tokenize(line []rune, state any) ([]Span, any)
+}
+
+// WithHighlighter sets the highlighter used to color the visible lines.
+// If set to nil, text is drawn in the text color.
+func WithHighlighter(opt Highlighter) EditorOption {
+	return func(e *Editor) {
+		e.highlighter = opt
+		e.highlightStates = nil
+	}
+}
+
+// highlightState returns the highlighter state at the start of the line.
+// The state at the end of each line is kept, so only the lines after the
+// last kept state are tokenized, rather than all of the lines before it.
+func (e *Editor) highlightState(line *editorLine) (state any) {
+	row := e.getLineNumberFromLine(line) - 1
+	if row > len(e.highlightStates) {
+		cur := e.lineAt(len(e.highlightStates))
+		if len(e.highlightStates) > 0 {
+			state = e.highlightStates[len(e.highlightStates)-1]
+		}
+		for ; cur != line && cur != nil; cur = cur.next {
+			_, state = e.highlighter.Tokenize(cur.values[:len(cur.values)-1], state)
+			e.highlightStates = append(e.highlightStates, state)
+		}
+	}
+	if row > 0 {
+		return e.highlightStates[row-1]
+	}
+	return nil
+}
+
+// invalidateHighlight forgets the highlighter states from the line down,
+// and the widths of the table columns, after it is edited.
+func (e *Editor) invalidateHighlight(line *editorLine) {
+	e.tableWidths = nil
+	if len(e.highlightStates) == 0 {
+		return
+	}
+	if row := e.getLineNumberFromLine(line) - 1; row < len(e.highlightStates) {
+		e.highlightStates = e.highlightStates[:row]
+	}
+}
+
+// highlightView colors the view of the line with the spans of the
+// highlighter, and returns the state for the next line. Colors already
+// set by the view, such as ANSI colors, are kept.
+func (e *Editor) highlightView(view *lineView, line *editorLine, state any) any {
+	spans, state := e.highlighter.Tokenize(line.values[:len(line.values)-1], state)
+	if len(spans) == 0 {
+		return state
+	}
+
+	if view.colors == nil {
+		view.colors = make([]color.Color, len(view.runes))
+	}
+	for _, span := range spans {
+		for x := span.Start; x < span.End && x < len(line.values)-1; x++ {
+			if x < 0 {
+				continue
+			}
+			for d := view.index[x]; d < view.index[x+1]; d++ {
+				if view.colors[d] == nil {
+					view.colors[d] = span.Color
+				}
+			}
+		}
+	}
+	return state
+}
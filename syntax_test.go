@@ -0,0 +1,115 @@
+package noter
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+var commentColor = color.RGBA{0x7f, 0x7f, 0x7f, 0xff}
+
+// commentHighlighter colors "#" comments, which continue onto the following
+// line when they end with '\'.
+type commentHighlighter struct{}
+
+func (commentHighlighter) Tokenize(line []rune, state any) ([]Span, any) {
+	start := -1
+	if state == true {
+		start = 0
+	} else {
+		for x, r := range line {
+			if r == '#' {
+				start = x
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+	continued := len(line) > 0 && line[len(line)-1] == '\\'
+	return []Span{{start, len(line), commentColor}}, continued
+}
+
+func TestHighlightView(t *testing.T) {
+	editor := NewEditor(WithHighlighter(commentHighlighter{}))
+	editor.WriteText([]byte("a # b \\\nc\nd\n"))
+
+	table := [](struct {
+		row    int
+		colors []color.Color
+	}){
+		{0, []color.Color{nil, nil, commentColor, commentColor, commentColor, commentColor, commentColor, nil}},
+		{1, []color.Color{commentColor, nil}},
+		{2, nil},
+	}
+
+	for _, entry := range table {
+		line := editor.lineAt(entry.row)
+		view := editor.lineView(line)
+		editor.highlightView(view, line, editor.highlightState(line))
+		if len(view.colors) != len(entry.colors) {
+			t.Fatalf("Incorrect colors for row %v, expected %v, got %v", entry.row, entry.colors, view.colors)
+		}
+		for d := range entry.colors {
+			if view.colors[d] != entry.colors[d] {
+				t.Fatalf("Incorrect color for row %v at %v, expected %v, got %v", entry.row, d, entry.colors[d], view.colors[d])
+			}
+		}
+	}
+}
+
+// countingHighlighter counts the lines tokenized by commentHighlighter.
+type countingHighlighter struct {
+	commentHighlighter
+	lines int
+}
+
+func (h *countingHighlighter) Tokenize(line []rune, state any) ([]Span, any) {
+	h.lines++
+	return h.commentHighlighter.Tokenize(line, state)
+}
+
+func TestHighlightStateCache(t *testing.T) {
+	highlighter := &countingHighlighter{}
+	editor := NewEditor(WithHighlighter(highlighter), WithRows(5))
+	editor.WriteText([]byte(strings.Repeat("a\n", 100) + "# b \\\nc\nd\n"))
+
+	render := func() int {
+		editor.MoveCursor(102, 0)
+		highlighter.lines = 0
+		editor.updateImage()
+		return highlighter.lines
+	}
+
+	// The lines above the view are tokenized once, then only the visible
+	// lines and those from an edited line down.
+	render()
+	info := editor.ViewInfo()
+	visible := info.LastLine - info.FirstLine + 1
+
+	table := [](struct {
+		edit func()
+		row  int
+	}){
+		{func() {}, info.FirstLine},
+		{func() { editor.MoveCursor(90, 0); editor.handleRune('x') }, 90},
+		{func() { editor.MoveCursor(95, 0); editor.deletePrevious() }, 94},
+		{func() { editor.storeUndoAction(editor.fnReplaceLines(80, 1, [][]rune{[]rune("y\n")})) }, 80},
+	}
+
+	for i, entry := range table {
+		entry.edit()
+		want := info.FirstLine - entry.row + visible
+		if got := render(); got != want || editor.ViewInfo() != info {
+			t.Fatalf("Incorrect lines tokenized after edit %v, expected %v, got %v", i, want, got)
+		}
+	}
+
+	// The states kept are those of the lines as edited, where "c" is now
+	// at row 100 after the join.
+	line := editor.lineAt(100)
+	if state := editor.highlightState(line); state != true {
+		t.Fatalf("Expected the comment to continue, got %v", state)
+	}
+}
@@ -0,0 +1,196 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"path"
+	"strings"
+)
+
+// WithTableMode enables the table alignment display mode for CSV/TSV
+// content. Cells are padded to the width of their column when drawn,
+// but the underlying text is never altered.
+func WithTableMode(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.table_mode = enabled
+	}
+}
+
+// WithTableDelimiter sets the cell delimiter used by the table mode.
+// If not set, a tab is used for content named "*.tsv", and a comma otherwise.
+func WithTableDelimiter(delim rune) EditorOption {
+	return func(e *Editor) {
+		e.table_delim = delim
+	}
+}
+
+// TableMode returns true if the table alignment display mode is enabled.
+func (e *Editor) TableMode() bool {
+	return e.table_mode
+}
+
+// SetTableMode enables or disables the table alignment display mode.
+func (e *Editor) SetTableMode(enabled bool) {
+	e.table_mode = enabled
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// tableDelimiter returns the delimiter to use for table cells.
+func (e *Editor) tableDelimiter() rune {
+	if e.table_delim != 0 {
+		return e.table_delim
+	}
+	if strings.EqualFold(path.Ext(e.content_name), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+// tableDelimiters returns the index of each delimiter separating the
+// cells of a row. Delimiters within double quotes are not counted.
+func tableDelimiters(values []rune, delim rune) (delims []int) {
+	quoted := false
+	for x, r := range values {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == delim && !quoted:
+			delims = append(delims, x)
+		}
+	}
+	return delims
+}
+
+// tableCellWidths returns the width, in runes, of each cell of a row.
+func tableCellWidths(values []rune, delim rune) (widths []int) {
+	start := 0
+	for _, d := range tableDelimiters(values, delim) {
+		widths = append(widths, d-start)
+		start = d + 1
+	}
+
+	end := len(values)
+	if end > 0 && values[end-1] == '\n' {
+		end--
+	}
+	return append(widths, end-start)
+}
+
+// tableColumnWidths returns the widest cell of each column in the content.
+func (e *Editor) tableColumnWidths() (widths []int) {
+	delim := e.tableDelimiter()
+	for line := e.start; line != nil; line = line.next {
+		for col, width := range tableCellWidths(line.values, delim) {
+			if col == len(widths) {
+				widths = append(widths, 0)
+			}
+			if width > widths[col] {
+				widths[col] = width
+			}
+		}
+	}
+	return widths
+}
+
+// tableLineView pads each cell of the line out to its column width.
+func tableLineView(values []rune, delim rune, widths []int) *lineView {
+	view := &lineView{
+		runes: make([]rune, 0, len(values)),
+		index: make([]int, len(values)+1),
+	}
+
+	delims := tableDelimiters(values, delim)
+	cellWidths := tableCellWidths(values, delim)
+	col := 0
+	for x, r := range values {
+		if col < len(delims) && delims[col] == x {
+			// Pad the cell which this delimiter ends.
+			for pad := cellWidths[col]; col < len(widths) && pad < widths[col]; pad++ {
+				view.runes = append(view.runes, ' ')
+			}
+			col++
+
+			// Tabs have no glyph, so draw the delimiter as a space.
+			if r == '\t' {
+				r = ' '
+			}
+		}
+		view.index[x] = len(view.runes)
+		view.runes = append(view.runes, r)
+	}
+	view.index[len(values)] = len(view.runes)
+
+	return view
+}
+
+// NextCell moves the cursor to the start of the next table cell,
+// continuing onto the following row after the final cell.
+func (e *Editor) NextCell() {
+	e.resetHighlight()
+
+	for _, d := range tableDelimiters(e.cursor.line.values, e.tableDelimiter()) {
+		if d >= e.cursor.x {
+			e.cursor.x = d + 1
+			e.fixPosition()
+			return
+		}
+	}
+
+	if e.cursor.line.next != nil {
+		e.cursor.line = e.cursor.line.next
+		e.cursor.x = 0
+	}
+	e.fixPosition()
+}
+
+// PrevCell moves the cursor to the start of the previous table cell,
+// continuing onto the last cell of the preceding row before the first cell.
+func (e *Editor) PrevCell() {
+	e.resetHighlight()
+
+	// Find the start of the current cell, and of the cell before it.
+	delims := tableDelimiters(e.cursor.line.values, e.tableDelimiter())
+	current, previous := 0, -1
+	for _, d := range delims {
+		if d >= e.cursor.x {
+			break
+		}
+		current, previous = d+1, current
+	}
+
+	switch {
+	case previous >= 0:
+		e.cursor.x = previous
+	case e.cursor.line.prev != nil:
+		e.cursor.line = e.cursor.line.prev
+		e.cursor.x = 0
+		if delims := tableDelimiters(e.cursor.line.values, e.tableDelimiter()); len(delims) > 0 {
+			e.cursor.x = delims[len(delims)-1] + 1
+		}
+	default:
+		e.cursor.x = 0
+	}
+	e.fixPosition()
+}
@@ -0,0 +1,75 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableLineView(t *testing.T) {
+	editor := NewEditor(WithTableMode(true))
+	editor.WriteText([]byte("a,bb,c\naaa,\"b,b\",c\n"))
+
+	widths := editor.tableColumnWidths()
+	if !reflect.DeepEqual(widths, []int{3, 5, 1}) {
+		t.Fatalf("Incorrect column widths, got: %v", widths)
+	}
+
+	view := tableLineView(editor.start.values, ',', widths)
+	if string(view.runes) != "a  ,bb   ,c\n" {
+		t.Fatalf("Incorrect table view, got: %q", string(view.runes))
+	}
+	if view.index[1] != 3 || view.index[2] != 4 {
+		t.Fatalf("Incorrect table view index, got: %v", view.index)
+	}
+}
+
+func TestTableCellNavigation(t *testing.T) {
+	editor := NewEditor(WithTableMode(true))
+	editor.WriteText([]byte("a,bb,c\naaa,\"b,b\",c\n"))
+
+	table := [](struct {
+		next     bool
+		row, col int
+	}){
+		{true, 0, 2},
+		{true, 0, 5},
+		{true, 1, 0},
+		{true, 1, 4},
+		{true, 1, 10},
+		{false, 1, 4},
+		{false, 1, 0},
+		{false, 0, 5},
+		{false, 0, 2},
+		{false, 0, 0},
+	}
+
+	for _, entry := range table {
+		if entry.next {
+			editor.NextCell()
+		} else {
+			editor.PrevCell()
+		}
+		row, col := editor.Cursor()
+		if row != entry.row || col != entry.col {
+			t.Fatalf("Incorrect cell navigation, expected (%v,%v), got (%v,%v)", entry.row, entry.col, row, col)
+		}
+	}
+}
+
+func TestTableColumnWidthsCache(t *testing.T) {
+	editor := NewEditor(WithTableMode(true))
+	editor.WriteText([]byte("a,bb,c\naaa,\"b,b\",c\n"))
+
+	editor.updateImage()
+	if !reflect.DeepEqual(editor.tableWidths, []int{3, 5, 1}) {
+		t.Fatalf("Incorrect column widths, got: %v", editor.tableWidths)
+	}
+
+	for _, r := range "aaa" {
+		editor.handleRune(r)
+	}
+	editor.updateImage()
+	if !reflect.DeepEqual(editor.tableWidths, []int{4, 5, 1}) {
+		t.Fatalf("Incorrect column widths after edit, got: %v", editor.tableWidths)
+	}
+}
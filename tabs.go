@@ -0,0 +1,91 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "image/color"
+
+// WithTabWidth sets the number of columns between tab stops, which is also
+// the width of an indent. The default is EDITOR_DEFAULT_TAB_WIDTH.
+func WithTabWidth(width int) EditorOption {
+	return func(e *Editor) {
+		if width < 1 {
+			width = EDITOR_DEFAULT_TAB_WIDTH
+		}
+		e.tab_width = width
+	}
+}
+
+// TabWidth returns the number of columns between tab stops.
+func (e *Editor) TabWidth() int {
+	return e.tab_width
+}
+
+// expandTabs returns the view with each tab displayed as the spaces up to
+// the next tab stop, so that a tab is selected and highlighted as a whole.
+func (v *lineView) expandTabs(width int) *lineView {
+	tabs := false
+	for _, r := range v.runes {
+		if r == '\t' {
+			tabs = true
+			break
+		}
+	}
+	if !tabs {
+		return v
+	}
+
+	expanded := &lineView{
+		runes: make([]rune, 0, len(v.runes)+width),
+		index: make([]int, len(v.index)),
+	}
+	if v.colors != nil {
+		expanded.colors = make([]color.Color, 0, cap(expanded.runes))
+	}
+
+	// moved[d] is the display position which d moves to.
+	moved := make([]int, len(v.runes)+1)
+	for d, r := range v.runes {
+		moved[d] = len(expanded.runes)
+		count := 1
+		if r == '\t' {
+			r = ' '
+			count = width - len(expanded.runes)%width
+		}
+		for i := 0; i < count; i++ {
+			expanded.runes = append(expanded.runes, r)
+			if v.colors != nil {
+				expanded.colors = append(expanded.colors, v.colors[d])
+			}
+		}
+	}
+	moved[len(v.runes)] = len(expanded.runes)
+
+	for x, d := range v.index {
+		expanded.index[x] = moved[d]
+	}
+	for _, t := range v.tokens {
+		t.display = moved[t.display]
+		expanded.tokens = append(expanded.tokens, t)
+	}
+	return expanded
+}
@@ -0,0 +1,39 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTabs(t *testing.T) {
+	editor := NewEditor(WithTabWidth(4))
+
+	table := [](struct {
+		text  string
+		runes string
+		index []int
+	}){
+		{"ab\n", "ab\n", []int{0, 1, 2, 3}},
+		{"\tx\n", "    x\n", []int{0, 4, 5, 6}},
+		{"ab\tc\n", "ab  c\n", []int{0, 1, 2, 4, 5, 6}},
+		{"abcd\t\tc\n", "abcd        c\n", []int{0, 1, 2, 3, 4, 8, 12, 13, 14}},
+	}
+
+	for _, entry := range table {
+		editor.WriteText([]byte(entry.text))
+		view := editor.lineView(editor.start)
+		if string(view.runes) != entry.runes || !reflect.DeepEqual(view.index, entry.index) {
+			t.Fatalf("Incorrect view of %q, got %q %v", entry.text, string(view.runes), view.index)
+		}
+	}
+
+	// A tab is selected across all of its columns.
+	editor.WriteText([]byte("ab\tc\n"))
+	view := editor.lineView(editor.start)
+	if selected := view.selection(map[int]bool{2: true}); !reflect.DeepEqual(selected, map[int]bool{2: true, 3: true}) {
+		t.Fatalf("Expected the tab's columns to be selected, got %v", selected)
+	}
+	if x := view.position(3); x != 2 {
+		t.Fatalf("Expected a click within the tab to move to it, got %v", x)
+	}
+}
@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "image/color"
+
+// Theme is the set of colors the editor is drawn in.
+type Theme struct {
+	Font       color.Color // text, recommended to have an Alpha of 255.
+	Background color.Color
+	Selection  color.Color // select highlight over the text.
+	Search     color.Color // search highlight over the text.
+	Cursor     color.Color // cursor over the text.
+
+	// LineNumbers and Bars are the colors of the line numbers, and of the
+	// top and bottom bars. If nil, they follow the Font color.
+	LineNumbers color.Color
+	Bars        color.Color
+
+	// Brackets is the highlight over a bracket by the cursor and its
+	// match, see WithBracketMatching. If nil, it's the Cursor color.
+	Brackets color.Color
+}
+
+// ThemeLight is the default theme, of black text on white.
+var ThemeLight = Theme{
+	Font:       color.Black,
+	Background: color.White,
+	Selection:  color.RGBA{0, 0, 200, 70},
+	Search:     color.RGBA{0, 200, 0, 70},
+	Cursor:     color.RGBA{0, 0, 0, 90},
+	Brackets:   color.NRGBA{0xff, 0x90, 0x00, 110},
+}
+
+// ThemeDark is a theme of light gray text on a dark background. The text
+// keeps a contrast ratio of at least 4.5:1 under the selection, search and
+// cursor, which are blended over the background.
+var ThemeDark = Theme{
+	Font:        color.RGBA{0xd4, 0xd4, 0xd4, 0xff},
+	Background:  color.RGBA{0x1e, 0x1e, 0x1e, 0xff},
+	Selection:   color.NRGBA{0x40, 0x80, 0xff, 90},
+	Search:      color.NRGBA{0x40, 0xc0, 0x40, 80},
+	Cursor:      color.NRGBA{0xff, 0xff, 0xff, 64},
+	LineNumbers: color.RGBA{0x85, 0x85, 0x85, 0xff},
+	Brackets:    color.NRGBA{0xff, 0xb0, 0x40, 72},
+}
+
+// WithTheme sets the colors of the editor to those of the theme. A nil
+// Font, Background, Selection, Search or Cursor color keeps the current
+// color. Later With*Color options override the theme.
+func WithTheme(theme Theme) EditorOption {
+	return func(e *Editor) {
+		if theme.Font != nil {
+			WithFontColor(theme.Font)(e)
+		}
+		if theme.Background != nil {
+			WithBackgroundColor(theme.Background)(e)
+		}
+		if theme.Selection != nil {
+			WithHighlightColor(theme.Selection)(e)
+		}
+		if theme.Search != nil {
+			WithSearchColor(theme.Search)(e)
+		}
+		if theme.Cursor != nil {
+			WithCursorColor(theme.Cursor)(e)
+		}
+		e.number_color = theme.LineNumbers
+		e.bar_color = theme.Bars
+		e.bracket_color = theme.Brackets
+	}
+}
+
+// SetTheme changes the colors of the editor to those of the theme. While
+// high-contrast colors are used, the theme applies once they are disabled.
+func (e *Editor) SetTheme(theme Theme) {
+	contrast := e.high_contrast
+	if contrast {
+		e.high_contrast = false
+		e.applyContrast()
+	}
+	WithTheme(theme)(e)
+	if contrast {
+		e.high_contrast = true
+		e.applyContrast()
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// lineNumberColor returns the color of the line numbers.
+func (e *Editor) lineNumberColor() color.Color {
+	if e.number_color == nil || e.high_contrast {
+		return dimColor(e.font_color)
+	}
+	return e.number_color
+}
+
+// bracketColor returns the color of the highlight of matching brackets.
+func (e *Editor) bracketColor() color.Color {
+	if e.bracket_color == nil || e.high_contrast {
+		return e.cursorColor()
+	}
+	return e.bracket_color
+}
+
+// barColor returns the color of the top and bottom bars.
+func (e *Editor) barColor() color.Color {
+	if e.bar_color == nil || e.high_contrast {
+		return e.font_color
+	}
+	return e.bar_color
+}
@@ -0,0 +1,62 @@
+package noter
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestWithTheme(t *testing.T) {
+	red := color.RGBA{200, 0, 0, 255}
+	editor := NewEditor(WithTheme(Theme{Font: red, Bars: color.Black}))
+	if editor.font_color != red || editor.search_color != ThemeLight.Search {
+		t.Fatalf("Expected the theme to replace only the colors it sets")
+	}
+	if editor.barColor() != color.Black || editor.lineNumberColor() != dimColor(red) {
+		t.Fatalf("Incorrect bar and line number colors")
+	}
+
+	// A theme set under high contrast applies once it is disabled.
+	editor = NewEditor(WithHighContrast(true))
+	editor.SetTheme(ThemeDark)
+	if editor.font_color != highContrastColors.font || editor.barColor() != highContrastColors.font {
+		t.Fatalf("Expected high-contrast colors to take precedence")
+	}
+	editor.SetHighContrast(false)
+	if editor.font_color != ThemeDark.Font || editor.lineNumberColor() != ThemeDark.LineNumbers {
+		t.Fatalf("Expected the dark theme once high contrast is disabled")
+	}
+}
+
+// blend returns the color over the opaque background.
+func blend(c, background color.Color) color.Color {
+	r, g, b, a := c.RGBA()
+	br, bg, bb, _ := background.RGBA()
+	over := func(v, bv uint32) uint16 {
+		return uint16(v + bv*(0xffff-a)/0xffff)
+	}
+	return color.RGBA64{over(r, br), over(g, bg), over(b, bb), 0xffff}
+}
+
+func TestThemeDark(t *testing.T) {
+	dark := ThemeDark
+	if ratio := contrastRatio(dark.Font, dark.Background); ratio < 7 {
+		t.Fatalf("Insufficient text contrast: %.2f", ratio)
+	}
+	if ratio := contrastRatio(dark.LineNumbers, dark.Background); ratio < 4.5 {
+		t.Fatalf("Insufficient line number contrast: %.2f", ratio)
+	}
+	for _, overlay := range []color.Color{dark.Selection, dark.Search, dark.Cursor} {
+		under := blend(overlay, dark.Background)
+		if ratio := contrastRatio(dark.Font, under); ratio < 4.5 {
+			t.Fatalf("Insufficient text contrast under %v: %.2f", overlay, ratio)
+		}
+		if ratio := contrastRatio(under, dark.Background); ratio < 1.5 {
+			t.Fatalf("Overlay %v is hard to see over the background: %.2f", overlay, ratio)
+		}
+	}
+
+	editor := NewEditor(WithTheme(dark))
+	if editor.popupBackground() != dark.Background {
+		t.Fatalf("Expected popups in the background color")
+	}
+}
@@ -0,0 +1,188 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+// inlineToken is a pattern of text which is rendered as an inline image.
+type inlineToken struct {
+	pattern *regexp.Regexp
+	columns int
+	render  func(token string) *ebiten.Image
+}
+
+// tokenSpan is a match of an inline token, from the rune start up to end.
+type tokenSpan struct {
+	start, end int
+	token      *inlineToken
+}
+
+// viewToken is an inline token in a lineView, at the display position.
+type viewToken struct {
+	display int
+	text    string
+	token   *inlineToken
+}
+
+// RegisterToken renders text matching the pattern, such as ":gold_coin:",
+// as an inline image occupying the given number of columns. The cursor and
+// selections treat each match as a single unit. render is called with the
+// matching text when it is drawn, and may return nil to leave it blank.
+// Tokens are only rendered in the default display mode, not in table mode.
+func (e *Editor) RegisterToken(pattern string, columns int, render func(token string) *ebiten.Image) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	e.tokens = append(e.tokens, &inlineToken{re, columns, render})
+	e.Invalidate()
+	return nil
+}
+
+// tokenSpans returns the matches of the inline tokens in the line, in order.
+// Where matches overlap, the earliest is used.
+func (e *Editor) tokenSpans(values []rune) (spans []tokenSpan) {
+	if len(e.tokens) == 0 {
+		return nil
+	}
+
+	// Map byte offsets of the matches to rune offsets.
+	s := string(values[:len(values)-1])
+	runeAt := runeOffsets(s)
+
+	for _, token := range e.tokens {
+		for _, m := range token.pattern.FindAllStringIndex(s, -1) {
+			if m[0] < m[1] {
+				spans = append(spans, tokenSpan{runeAt[m[0]], runeAt[m[1]], token})
+			}
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	// Drop overlapping matches.
+	kept := spans[:0]
+	for _, span := range spans {
+		if len(kept) == 0 || span.start >= kept[len(kept)-1].end {
+			kept = append(kept, span)
+		}
+	}
+	return kept
+}
+
+// tokenLineView returns a view which displays each token span as blank
+// columns, for its image to be drawn over.
+func tokenLineView(values []rune, spans []tokenSpan) *lineView {
+	view := &lineView{
+		runes: make([]rune, 0, len(values)),
+		index: make([]int, len(values)+1),
+	}
+	x := 0
+	for _, span := range spans {
+		for ; x < span.start; x++ {
+			view.index[x] = len(view.runes)
+			view.runes = append(view.runes, values[x])
+		}
+
+		// The runes within the token are displayed after it, so that
+		// selecting its first rune selects all of its columns.
+		display := len(view.runes)
+		view.index[x] = display
+		view.runes = append(view.runes, []rune(strings.Repeat(" ", span.token.columns))...)
+		view.tokens = append(view.tokens, viewToken{display, string(values[span.start:span.end]), span.token})
+		for x++; x < span.end; x++ {
+			view.index[x] = len(view.runes)
+		}
+	}
+	for ; x < len(values); x++ {
+		view.index[x] = len(view.runes)
+		view.runes = append(view.runes, values[x])
+	}
+	view.index[len(values)] = len(view.runes)
+	return view
+}
+
+// drawTokens renders the images of the inline tokens in the row from the
+// display position start up to end.
+func (e *Editor) drawTokens(y int, view *lineView, start, end int) {
+	for _, t := range view.tokens {
+		if t.display < start || t.display+t.token.columns > end || t.token.render == nil {
+			continue
+		}
+		image := t.token.render(t.text)
+		if image == nil {
+			continue
+		}
+
+		// Scale the image to fit its columns, keeping its aspect ratio.
+		width := float64(font.MeasureString(e.font_info.face, string(view.runes[t.display:t.display+t.token.columns])).Ceil())
+		height := float64(e.font_info.yUnit)
+		bounds := image.Bounds()
+		scale := width / float64(bounds.Dx())
+		if s := height / float64(bounds.Dy()); s < scale {
+			scale = s
+		}
+
+		opts := ebiten.DrawImageOptions{}
+		opts.GeoM.Scale(scale, scale)
+		opts.GeoM.Translate(
+			float64(e.textLeft()+font.MeasureString(e.font_info.face, string(view.runes[start:t.display])).Ceil()),
+			float64(e.top_padding+y*e.font_info.yUnit))
+		e.screen.DrawImage(image, &opts)
+	}
+}
+
+// skipToken moves the cursor out of an inline token, to its end when
+// moving right (dir > 0), or to its start otherwise. The runes passed over
+// are highlighted if shift is held.
+func (e *Editor) skipToken(dir int, shift bool) {
+	for _, span := range e.tokenSpans(e.cursor.line.values) {
+		if e.cursor.x <= span.start || e.cursor.x >= span.end {
+			continue
+		}
+		if dir > 0 {
+			for ; e.cursor.x < span.end; e.cursor.x++ {
+				if shift {
+					e.highlight(e.cursor.line, e.cursor.x)
+				}
+			}
+		} else {
+			for e.cursor.x > span.start {
+				e.cursor.x--
+				if shift {
+					e.highlight(e.cursor.line, e.cursor.x)
+				}
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,54 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenLineView(t *testing.T) {
+	editor := NewEditor()
+	if err := editor.RegisterToken(`:[a-z_]+:`, 2, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := editor.RegisterToken(`(`, 2, nil); err == nil {
+		t.Fatalf("Expected an error for an invalid pattern")
+	}
+
+	values := []rune("é :coin: x\n")
+	spans := editor.tokenSpans(values)
+	if len(spans) != 1 || spans[0].start != 2 || spans[0].end != 8 {
+		t.Fatalf("Incorrect token spans: %v", spans)
+	}
+
+	view := tokenLineView(values, spans)
+	if got := string(view.runes); got != "é    x\n" {
+		t.Fatalf("Incorrect token display, got: %q", got)
+	}
+	if want := []int{0, 1, 2, 4, 4, 4, 4, 4, 4, 5, 6, 7}; !reflect.DeepEqual(view.index, want) {
+		t.Fatalf("Incorrect display positions, expected %v, got: %v", want, view.index)
+	}
+	if len(view.tokens) != 1 || view.tokens[0].display != 2 || view.tokens[0].text != ":coin:" {
+		t.Fatalf("Incorrect view tokens: %v", view.tokens)
+	}
+}
+
+func TestSkipToken(t *testing.T) {
+	editor := NewEditor()
+	editor.RegisterToken(`:[a-z_]+:`, 2, nil)
+	editor.WriteText([]byte("a :coin: b\n"))
+
+	table := [](struct{ x, dir, want int }){
+		{1, 1, 1},
+		{3, 1, 8},
+		{7, -1, 2},
+		{8, -1, 8},
+	}
+
+	for _, entry := range table {
+		editor.cursor.x = entry.x
+		editor.skipToken(entry.dir, false)
+		if editor.cursor.x != entry.want {
+			t.Fatalf("Incorrect skip from %v by %v, expected %v, got %v", entry.x, entry.dir, entry.want, editor.cursor.x)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// EDITOR_TUTORIAL_STEP is how long each step of a tutorial is shown.
+const EDITOR_TUTORIAL_STEP = 2500 * time.Millisecond
+
+// TutorialStep is a step of a tutorial, which types the text, replays the
+// keystrokes, runs the command, and shows the caption while it does. The
+// keystrokes are replayed as if pressed, running the commands bound to
+// them. The caption of a step begins with its keystrokes, or else with the
+// keystroke bound to its command, if any.
+type TutorialStep struct {
+	Caption string // such as "selects all".
+	Text    string // text to type.
+	Keys    string // keystrokes to replay, separated by spaces, such as "cmd+a cmd+x".
+	Command string // name of the command to run, as RunCommand.
+}
+
+// Tutorial is the built-in walkthrough of the shortcuts.
+var Tutorial = []TutorialStep{
+	{Caption: "Welcome to noter! Press Escape to leave the tutorial."},
+	{Caption: "Type to insert text.", Text: "- [ ] learn the shortcuts"},
+	{Caption: "checks or unchecks a task.", Command: "ToggleCheckbox"},
+	{Caption: "selects all.", Command: "SelectAll"},
+	{Caption: "cuts the selection.", Command: "Cut"},
+	{Caption: "pastes.", Command: "Paste"},
+	{Caption: "undoes.", Command: "Undo"},
+	{Caption: "deletes to the start of the line.", Command: "DeleteToLineStart"},
+	{Caption: "That's all! Your text is back as it was."},
+}
+
+// tutorialState is the tutorial being played, and the content it replaced.
+type tutorialState struct {
+	steps []TutorialStep
+	step  int       // index of the step shown.
+	next  time.Time // when the next step begins.
+
+	start     *editorLine
+	cursor    *editorCursor
+	carets    []int
+	undoStack []func() bool
+	modified  bool
+	readOnly  bool
+	first     int
+	clipboard Content
+
+	// appended is the text of AppendText while the tutorial plays, which
+	// is appended to the content when it's restored.
+	appended []byte
+
+	// views are the other views of the content, which the editor rejoins
+	// afterwards, taking the content as they've edited it.
+	views *viewGroup
+}
+
+// PlayTutorial plays the steps, or the built-in Tutorial if nil, over an
+// empty document, with a clipboard of its own. The steps replay their input
+// as if typed and pressed, and other input is ignored while they play,
+// other than Escape which stops the tutorial. Afterwards the content is
+// restored as it was, with any AppendText meanwhile, and the edits of
+// PostEdit wait until then. Any other views (see NewView) keep the content
+// meanwhile, and the editor shows it as they've left it. It returns false
+// if the content is still loading.
+func (e *Editor) PlayTutorial(steps []TutorialStep) bool {
+	if e.Loading() {
+		return false
+	}
+	if steps == nil {
+		steps = Tutorial
+	}
+	e.StopTutorial()
+
+	e.editMode()
+	e.tutorial = &tutorialState{
+		steps:     steps,
+		step:      -1,
+		start:     e.start,
+		cursor:    e.cursor,
+		carets:    e.carets,
+		modified:  e.modified,
+		readOnly:  e.read_only,
+		first:     e.firstVisible,
+		clipboard: e.clipboard,
+	}
+	e.ClearCarets()
+	e.clipboard = &dummyContent{}
+	e.tutorial.views = e.detachView()
+	e.tutorial.undoStack = e.undoStack
+	e.start = &editorLine{values: []rune{'\n'}}
+	e.cursor = &editorCursor{line: e.start}
+	e.undoStack = make([]func() bool, 0)
+	e.highlighted = make(map[*editorLine]map[int]bool)
+	e.read_only = false
+	e.firstVisible = 0
+	e.highlightStates = nil
+	e.tableWidths = nil
+	e.invalidateLines()
+
+	e.advanceTutorial(time.Now())
+	return true
+}
+
+// TutorialPlaying returns true while a tutorial is playing.
+func (e *Editor) TutorialPlaying() bool {
+	return e.tutorial != nil
+}
+
+// StopTutorial stops any tutorial, and restores the content.
+func (e *Editor) StopTutorial() {
+	t := e.tutorial
+	if t == nil {
+		return
+	}
+	e.tutorial = nil
+
+	e.editMode()
+	e.start = t.start
+	e.cursor = t.cursor
+	e.carets = t.carets
+	e.clipboard = t.clipboard
+	e.undoStack = t.undoStack
+	e.modified = t.modified
+	e.read_only = t.readOnly
+	e.highlighted = make(map[*editorLine]map[int]bool)
+	e.firstVisible = t.first
+	e.highlightStates = nil
+	e.tableWidths = nil
+	if t.views != nil && len(t.views.views) > 0 {
+		// The other views may have edited the content meanwhile.
+		e.attachView(t.views)
+		e.notifyEdit()
+	} else {
+		e.invalidateLines()
+	}
+	e.fixPosition()
+	if len(t.appended) > 0 {
+		e.AppendText(t.appended)
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// advanceTutorial begins the next step of the tutorial, if it's due, and
+// stops the tutorial after the last.
+func (e *Editor) advanceTutorial(now time.Time) {
+	t := e.tutorial
+	if t == nil || now.Before(t.next) {
+		return
+	}
+	t.step++
+	if t.step >= len(t.steps) {
+		e.StopTutorial()
+		return
+	}
+	t.next = now.Add(EDITOR_TUTORIAL_STEP)
+
+	step := t.steps[t.step]
+	if step.Text != "" {
+		e.storeUndoAction(e.fnHandleRuneMulti([]rune(step.Text)))
+		e.setModified()
+	}
+	for _, keystroke := range strings.Fields(step.Keys) {
+		e.runKeystroke(keystroke)
+	}
+	if step.Command != "" {
+		e.RunCommand(step.Command)
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// updateTutorial plays the tutorial, ignoring all input other than Escape.
+// It returns true while the tutorial is playing.
+func (e *Editor) updateTutorial() bool {
+	if e.tutorial == nil {
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		e.StopTutorial()
+		return true
+	}
+	e.advanceTutorial(time.Now())
+	return true
+}
+
+// keystrokeLabel returns the first keystroke bound to the command, such as
+// "COMMAND-A" for "cmd+a", or "" if it isn't bound.
+func (e *Editor) keystrokeLabel(command string) string {
+	var keystrokes []string
+	for keystroke, bound := range e.bindings {
+		if bound == command {
+			keystrokes = append(keystrokes, keystroke)
+		}
+	}
+	if len(keystrokes) == 0 {
+		return ""
+	}
+	sort.Strings(keystrokes)
+	return keystrokeName(keystrokes[0])
+}
+
+// keystrokeName returns the keystroke as shown in a caption, such as
+// "COMMAND-A" for "cmd+a".
+func keystrokeName(keystroke string) string {
+	name := strings.ToUpper(strings.ReplaceAll(keystroke, "+", "-"))
+	return strings.Replace(name, "CMD-", "COMMAND-", 1)
+}
+
+// tutorialCaption returns the caption of the current step of the tutorial.
+func (e *Editor) tutorialCaption() string {
+	t := e.tutorial
+	if t == nil || t.step < 0 || t.step >= len(t.steps) {
+		return ""
+	}
+	step := t.steps[t.step]
+	caption := e.tr(step.Caption)
+	label := e.keystrokeLabel(step.Command)
+	if keystrokes := strings.Fields(step.Keys); len(keystrokes) > 0 {
+		for i, keystroke := range keystrokes {
+			keystrokes[i] = keystrokeName(keystroke)
+		}
+		label = strings.Join(keystrokes, " ")
+	}
+	if label != "" {
+		caption = label + " " + caption
+	}
+	return caption
+}
+
+// drawTutorial draws the caption of the tutorial in a box, centered at the
+// bottom of the text.
+func (e *Editor) drawTutorial() {
+	caption := e.tutorialCaption()
+	if caption == "" {
+		return
+	}
+
+	pad := e.width_padding
+	width := font.MeasureString(e.font_info.face, caption).Ceil() + pad*2
+	height := e.font_info.yUnit + pad*2
+	x := (e.width - width) / 2
+	if x < 0 {
+		x = 0
+	}
+	y := e.top_padding + e.rows*e.font_info.yUnit - height - pad
+
+	ebitenutil.DrawRect(e.screen, float64(x), float64(y), float64(width), float64(height), e.popupBackground())
+	x0, y0 := float64(x), float64(y)
+	x1, y1 := float64(x+width-1), float64(y+height-1)
+	ebitenutil.DrawLine(e.screen, x0, y0, x1, y0, e.font_color)
+	ebitenutil.DrawLine(e.screen, x1, y0, x1, y1, e.font_color)
+	ebitenutil.DrawLine(e.screen, x1, y1, x0, y1, e.font_color)
+	ebitenutil.DrawLine(e.screen, x0, y1, x0, y0, e.font_color)
+	text.Draw(e.screen, caption, e.font_info.face, x+pad, y+pad+e.font_info.ascent, e.font_color)
+}
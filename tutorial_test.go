@@ -0,0 +1,128 @@
+package noter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayTutorial(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("my notes\n"))
+	editor.MoveCursor(0, 3)
+
+	editor.PlayTutorial([]TutorialStep{
+		{Caption: "Type to insert text.", Text: "hello"},
+		{Caption: "selects all.", Command: "SelectAll"},
+		{Caption: "cuts the selection.", Command: "Cut"},
+	})
+	if got := string(editor.ReadText()); got != "hello\n" || editor.tutorialCaption() != "Type to insert text." {
+		t.Fatalf("Expected the first step over an empty document, got %q", got)
+	}
+
+	now := time.Now()
+	for step := 1; step < 3; step++ {
+		now = now.Add(EDITOR_TUTORIAL_STEP)
+		editor.advanceTutorial(now)
+	}
+	if got := string(editor.ReadText()); got != "" || editor.tutorialCaption() != "COMMAND-X cuts the selection." {
+		t.Fatalf("Expected the text to be cut, got %q, %q", got, editor.tutorialCaption())
+	}
+
+	editor.advanceTutorial(now.Add(EDITOR_TUTORIAL_STEP))
+	if editor.TutorialPlaying() || string(editor.ReadText()) != "my notes\n" || editor.IsModified() {
+		t.Fatalf("Expected the content to be restored after the tutorial, got %q", editor.ReadText())
+	}
+	if row, col := editor.Cursor(); row != 0 || col != 3 {
+		t.Fatalf("Expected the cursor to be restored, got (%v,%v)", row, col)
+	}
+}
+
+func TestTutorialKeepsContent(t *testing.T) {
+	clipboard := &dummyContent{content: "mine"}
+	editor := NewEditor(WithClipboard(clipboard))
+	editor.WriteText([]byte("ab\ncd\n"))
+	editor.AddCaret(1, 1)
+
+	editor.PlayTutorial([]TutorialStep{
+		{Caption: "Type to insert text.", Text: "hello"},
+		{Caption: "selects all.", Command: "SelectAll"},
+		{Caption: "cuts the selection.", Command: "Cut"},
+	})
+	editor.AppendText([]byte("ef\n"))
+	posted := false
+	editor.PostEdit(func(e *Editor) { posted = true })
+	editor.runPosted()
+	if posted || string(editor.ReadText()) != "hello\n" {
+		t.Fatalf("Expected appends and posted edits to wait, got %q", editor.ReadText())
+	}
+
+	now := time.Now()
+	for step := 0; step < 3; step++ {
+		now = now.Add(EDITOR_TUTORIAL_STEP)
+		editor.advanceTutorial(now)
+	}
+	if editor.TutorialPlaying() || clipboard.content != "mine" {
+		t.Fatalf("Expected the clipboard to be kept, got %q", clipboard.content)
+	}
+	if got := string(editor.ReadText()); got != "ab\ncd\nef\n" {
+		t.Fatalf("Incorrect content after the tutorial, expected %q, got %q", "ab\ncd\nef\n", got)
+	}
+	if carets := editor.Carets(); len(carets) != 1 || carets[0] != (Position{1, 1}) {
+		t.Fatalf("Expected the caret to be restored, got %v", carets)
+	}
+
+	editor.runPosted()
+	if !posted {
+		t.Fatalf("Expected the posted edit to run after the tutorial")
+	}
+}
+
+func TestTutorialEdits(t *testing.T) {
+	removed := map[string]any{}
+	ext := &testExtension{}
+	editor := NewEditor(WithExtension(ext), WithOnLineDataRemoved(func(key string, value any) {
+		removed[key] = value
+	}))
+	editor.WriteText([]byte("ab\ncd\n"))
+	editor.SetLineData(1, "todo", true)
+	editor.ShowFindAll("cd")
+
+	editor.PlayTutorial([]TutorialStep{
+		{Caption: "Type to insert text.", Text: "cd\ncd"},
+		{Caption: "selects all.", Command: "SelectAll"},
+		{Caption: "cuts the selection.", Command: "Cut"},
+	})
+	now := time.Now()
+	for step := 0; step < 3; step++ {
+		now = now.Add(EDITOR_TUTORIAL_STEP)
+		editor.advanceTutorial(now)
+	}
+	if len(removed) != 0 || ext.edits != 0 {
+		t.Fatalf("Expected the tutorial's edits to go untold, got %v and %v edits", removed, ext.edits)
+	}
+	if got := len(editor.findPanel.results); got != 1 {
+		t.Fatalf("Expected the find panel to keep its matches, got %v", got)
+	}
+
+	editor.StopTutorial()
+	if value, ok := editor.LineData(1, "todo"); !ok || value != true {
+		t.Fatalf("Expected the line data to be kept, got %v", value)
+	}
+}
+
+func TestTutorialKeys(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("my notes\n"))
+
+	editor.PlayTutorial([]TutorialStep{
+		{Caption: "Type to insert text.", Text: "hello"},
+		{Caption: "cut everything.", Keys: "cmd+a cmd+x"},
+	})
+	editor.advanceTutorial(time.Now().Add(EDITOR_TUTORIAL_STEP))
+	if got := string(editor.ReadText()); got != "" {
+		t.Fatalf("Expected the keystrokes to cut the text, got %q", got)
+	}
+	if got := editor.tutorialCaption(); got != "COMMAND-A COMMAND-X cut everything." {
+		t.Fatalf("Incorrect caption, got %q", got)
+	}
+}
@@ -0,0 +1,214 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+// viewGroup is the views of the same content, see NewView.
+type viewGroup struct {
+	views []*Editor
+}
+
+// NewView returns another view of the content of the editor, with its own
+// cursor, selection and scroll position, so that one part of the content
+// can be read while another is edited. Edits in either view are seen in
+// both, and Undo in either undoes the latest edit of both, moving the
+// cursor of the view it's run in. The options are those of NewEditor,
+// such as the size of the view, and the host draws each view, such as one
+// above the other. Replacing the content of a view, such as with WriteText
+// or OpenAt, detaches it from the others, which keep the content as it
+// was, and clears the undo history of each.
+func (e *Editor) NewView(options ...EditorOption) *Editor {
+	v := NewEditor(options...)
+	if e.views == nil {
+		e.views = &viewGroup{views: []*Editor{e}}
+		for i, undo := range e.undoStack {
+			e.undoStack[i] = e.viewUndo(undo)
+		}
+	}
+	e.views.views = append(e.views.views, v)
+	v.views = e.views
+	v.content, v.content_name = e.content, e.content_name
+	v.lines = e.lines
+
+	// Show the content of the editor, from the top.
+	v.cursor = &editorCursor{line: e.start}
+	v.syncFrom(e)
+	v.firstVisible = 0
+	v.updateImage()
+	return v
+}
+
+// Views returns the number of views of the content, see NewView.
+func (e *Editor) Views() int {
+	if e.views == nil {
+		return 1
+	}
+	return len(e.views.views)
+}
+
+// syncViews shares the content of the editor, its undo history and its
+// modified state with its other views, after an edit (see notifyEdit).
+func (e *Editor) syncViews() {
+	if e.views == nil {
+		return
+	}
+	for _, v := range e.views.views {
+		if v != e {
+			v.syncFrom(e)
+			v.updateImage()
+		}
+	}
+}
+
+// syncFrom takes the content of the view from, its undo history and its
+// modified state. The cursor is kept on the content, moving from any line
+// which was removed to the nearest line before it which remains, and the
+// selection is cleared.
+func (e *Editor) syncFrom(from *Editor) {
+	e.start = from.start
+	if len(e.highlightStates) > len(from.highlightStates) {
+		e.highlightStates = e.highlightStates[:len(from.highlightStates)]
+	}
+	e.tableWidths = nil
+	e.undoStack = from.undoStack
+	e.modified = from.modified
+	e.lineEnding, e.encoding, e.bom = from.lineEnding, from.encoding, from.bom
+	if len(e.highlighted) > 0 {
+		e.resetHighlight()
+	}
+
+	if e.cursor == nil || e.start == nil {
+		return
+	}
+	line := e.cursor.line
+	for line != nil {
+		if _, ok := e.rowOf(line); ok {
+			break
+		}
+		line = line.prev
+	}
+	if line != e.cursor.line {
+		e.cursor.line, e.cursor.x = line, 0
+		if line == nil {
+			e.cursor.line = e.start
+		}
+	} else if e.cursor.x > len(e.cursor.line.values)-1 {
+		e.cursor.x = len(e.cursor.line.values) - 1
+	}
+}
+
+// viewUndo returns the undo action of an edit of the editor, for the undo
+// history it shares with its views. Run by Undo in another view, the action
+// moves the cursor, selection and scroll position of that view, rather than
+// those of the editor.
+func (e *Editor) viewUndo(undo func() bool) func() bool {
+	if e.views == nil {
+		return undo
+	}
+	return func() bool {
+		v := e.undoingView()
+		if v == nil {
+			return undo()
+		}
+
+		e.cursor, v.cursor = v.cursor, e.cursor
+		e.highlighted, v.highlighted = v.highlighted, e.highlighted
+		e.firstVisible, v.firstVisible = v.firstVisible, e.firstVisible
+		e.undoing = true
+		done := undo()
+		e.undoing = false
+		e.cursor, v.cursor = v.cursor, e.cursor
+		e.highlighted, v.highlighted = v.highlighted, e.highlighted
+		e.firstVisible, v.firstVisible = v.firstVisible, e.firstVisible
+
+		// The view takes the content as undone, with its own rows.
+		v.start = e.start
+		if len(v.highlightStates) > len(e.highlightStates) {
+			v.highlightStates = v.highlightStates[:len(e.highlightStates)]
+		}
+		v.tableWidths = nil
+		v.fixPosition()
+		return done
+	}
+}
+
+// undoingView returns the other view of the editor running Undo, if any.
+func (e *Editor) undoingView() *Editor {
+	if e.views == nil {
+		return nil
+	}
+	for _, v := range e.views.views {
+		if v != e && v.undoing {
+			return v
+		}
+	}
+	return nil
+}
+
+// shareModified gives the other views the modified state of the editor.
+func (e *Editor) shareModified() {
+	if e.views == nil {
+		return
+	}
+	for _, v := range e.views.views {
+		v.modified = e.modified
+	}
+}
+
+// detachView removes the editor from its views, with an index of its own
+// for the lines, so that replacing its content leaves theirs as it was.
+// It returns the views it left, if any. The lines of the content are in
+// the index of the views, so the editor must replace its content before
+// its lines are next numbered. The undo history of the editor and of the
+// views is cleared, as the actions of each may undo the edits of another.
+func (e *Editor) detachView() *viewGroup {
+	g := e.views
+	if g == nil {
+		return nil
+	}
+	e.views = nil
+	for i, v := range g.views {
+		if v == e {
+			g.views = append(g.views[:i:i], g.views[i+1:]...)
+			break
+		}
+	}
+	if len(g.views) == 0 {
+		return nil
+	}
+	e.lines = &lineIndex{}
+	e.undoStack = make([]func() bool, 0)
+	for _, v := range g.views {
+		v.undoStack = make([]func() bool, 0)
+	}
+	return g
+}
+
+// attachView adds the editor to the views it left with detachView, taking
+// their content.
+func (e *Editor) attachView(g *viewGroup) {
+	other := g.views[0]
+	g.views = append(g.views, e)
+	e.views = g
+	e.lines = other.lines
+	e.syncFrom(other)
+}
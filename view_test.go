@@ -0,0 +1,133 @@
+package noter
+
+import "testing"
+
+func TestNewView(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a\nb\nc\n"))
+	view := editor.NewView()
+	view.MoveCursor(2, 0)
+
+	editor.MoveCursor(0, 1)
+	editor.storeUndoAction(editor.fnHandleRuneMulti([]rune("x\n")))
+	if got := string(view.ReadText()); got != "ax\n\nb\nc\n" {
+		t.Fatalf("Expected the edit in the view, got: %q", got)
+	}
+	if row, col := view.Cursor(); row != 3 || col != 0 {
+		t.Fatalf("Expected the view's cursor to stay on its line at 3:0, got %v:%v", row, col)
+	}
+
+	view.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != "a\nb\nc\n" {
+		t.Fatalf("Expected the undo in the editor, got: %q", got)
+	}
+	if row, col := view.Cursor(); row != 0 || col != 1 {
+		t.Fatalf("Expected the view's cursor at the undone edit at 0:1, got %v:%v", row, col)
+	}
+	if row, col := editor.Cursor(); row != 0 || col != 0 {
+		t.Fatalf("Expected the editor's cursor to leave its removed line for 0:0, got %v:%v", row, col)
+	}
+
+	// A cursor on a removed line moves to the line at its number.
+	view.MoveCursor(1, 1)
+	editor.MoveCursor(1, 0)
+	editor.RunCommand("DeleteLine")
+	if row, col := view.Cursor(); row != 1 || col != 1 || string(view.cursor.line.values) != "c\n" {
+		t.Fatalf("Expected the view's cursor at 1:1 on the next line, got %v:%v", row, col)
+	}
+}
+
+func TestViewModified(t *testing.T) {
+	editor := NewEditor(WithContent(&dummyContent{}))
+	editor.WriteText([]byte("a\nb\n"))
+	view := editor.NewView()
+
+	editor.MoveCursor(0, 1)
+	editor.RunCommand("InsertLineBelow")
+	if !view.IsModified() {
+		t.Fatalf("Expected the view to be modified by the edit")
+	}
+	editor.Save()
+	if view.IsModified() {
+		t.Fatalf("Expected the view to be saved with the editor")
+	}
+
+	editor.AppendText([]byte("c"))
+	if got := string(view.ReadText()); got != "a\n\nb\nc\n" {
+		t.Fatalf("Expected the appended text in the view, got %q", got)
+	}
+}
+
+func TestViewDetach(t *testing.T) {
+	saved := &dummyContent{}
+	editor := NewEditor(WithContent(saved))
+	editor.WriteText([]byte("a\nb\n"))
+	view := editor.NewView()
+
+	// Replacing the content of the editor leaves the view as it was.
+	editor.WriteText([]byte("other\n"))
+	if editor.Views() != 1 || view.Views() != 1 {
+		t.Fatalf("Expected the views to be detached, got %v and %v", editor.Views(), view.Views())
+	}
+	if got := string(view.ReadText()); got != "a\nb\n" {
+		t.Fatalf("Expected the view to keep its content, got %q", got)
+	}
+	view.MoveCursor(1, 0)
+	view.RunCommand("DeleteLine")
+	view.Save()
+	if got := string(editor.ReadText()); got != "other\n" || saved.content != "a\n" {
+		t.Fatalf("Expected the view to save its own content, got %q and %q", got, saved.content)
+	}
+}
+
+func TestViewTutorial(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a\nb\n"))
+	view := editor.NewView()
+
+	// The view keeps the content while the tutorial plays, and the editor
+	// shows it as the view left it.
+	editor.PlayTutorial([]TutorialStep{{Caption: "Type to insert text.", Text: "hello"}})
+	if got := string(view.ReadText()); got != "a\nb\n" || view.Views() != 1 {
+		t.Fatalf("Expected the view to keep the content, got %q", got)
+	}
+	view.MoveCursor(0, 0)
+	view.RunCommand("DeleteLine")
+
+	editor.StopTutorial()
+	if got := string(editor.ReadText()); got != "b\n" || editor.Views() != 2 {
+		t.Fatalf("Expected the edited content after the tutorial, got %q", got)
+	}
+	editor.MoveCursor(0, 1)
+	editor.storeUndoAction(editor.fnHandleRuneMulti([]rune("c")))
+	if got := string(view.ReadText()); got != "bc\n" {
+		t.Fatalf("Expected edits to be shared again, got %q", got)
+	}
+}
+
+func TestViewUndoAfterDetach(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a\nb\n"))
+	view := editor.NewView()
+	editor.MoveCursor(0, 1)
+	editor.storeUndoAction(editor.fnHandleRuneMulti([]rune("x")))
+
+	// The edits of the editor can't be undone in the view once the editor
+	// has other content.
+	editor.WriteText([]byte("other\ntext\n"))
+	view.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != "other\ntext\n" {
+		t.Fatalf("Expected the editor's content to be untouched, got %q", got)
+	}
+	if got := string(view.ReadText()); got != "ax\nb\n" {
+		t.Fatalf("Expected the view to keep its content, got %q", got)
+	}
+
+	// The view's own edits are undone as before.
+	view.MoveCursor(1, 0)
+	view.storeUndoAction(view.fnHandleRuneMulti([]rune("y")))
+	view.RunCommand("Undo")
+	if got := string(view.ReadText()); got != "ax\nb\n" {
+		t.Fatalf("Incorrect undo in the view, got %q", got)
+	}
+}
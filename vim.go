@@ -0,0 +1,353 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// KeymapVim emulates the modes of Vim. The editor starts in the "normal"
+// mode, where keys are commands; "i", "a", "o" and friends enter the
+// insert mode (EDIT_MODE), which Escape leaves; and "v" enters the
+// "visual" mode, where moving the cursor selects text.
+//
+// The supported commands are the motions h j k l w b 0 $ gg G, and
+// x dd yy p P u i a A I o O v. In visual mode, y yanks and d or x deletes
+// the selection. Enter, Tab, Backspace and Delete are ignored outside the
+// insert mode. The COMMAND keys of the default bindings still work.
+var KeymapVim = &Keymap{name: "vim", attach: attachVim}
+
+// vimState is the state of the Vim emulation of an editor.
+type vimState struct {
+	normal, visual Mode
+	pending        string // operator waiting for its second key, such as "d".
+	linewise       bool   // true if the clipboard holds whole lines.
+}
+
+// vimIgnoredKeys are the keys which edit the content in EDIT_MODE, which
+// the normal and visual modes take without editing.
+var vimIgnoredKeys = []ebiten.Key{
+	ebiten.KeyEnter,
+	ebiten.KeyNumpadEnter,
+	ebiten.KeyTab,
+	ebiten.KeyBackspace,
+	ebiten.KeyDelete,
+}
+
+// vimIgnoresKey returns true if the key is one of vimIgnoredKeys.
+func vimIgnoresKey(key ebiten.Key) bool {
+	for _, ignored := range vimIgnoredKeys {
+		if key == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// vimIgnoredKeyPressed returns true if one of vimIgnoredKeys is pressed,
+// so that the mode takes the input rather than the EDIT_MODE handling.
+func vimIgnoredKeyPressed() bool {
+	for _, key := range inpututil.PressedKeys() {
+		if vimIgnoresKey(key) && isKeyJustPressedOrRepeating(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// attachVim registers the Vim modes, and starts in the normal mode.
+func attachVim(e *Editor) {
+	vim := &vimState{}
+	vim.normal = e.RegisterMode("normal", vim.updateNormal)
+	vim.visual = e.RegisterMode("visual", vim.updateVisual)
+	e.escapeMode = vim.normal
+	e.SetMode(vim.normal)
+}
+
+// updateNormal handles the keys of the normal mode.
+func (vim *vimState) updateNormal(e *Editor) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		vim.pending = ""
+		return true
+	}
+
+	runes := ebiten.AppendInputChars(nil)
+	for _, r := range runes {
+		vim.normalRune(e, r)
+	}
+	if len(runes) > 0 {
+		e.fixPosition()
+	}
+	return len(runes) > 0 || vimIgnoredKeyPressed()
+}
+
+// normalRune handles a key of the normal mode.
+func (vim *vimState) normalRune(e *Editor, r rune) {
+	command := vim.pending + string(r)
+	vim.pending = ""
+
+	if vimMotion(e, command) {
+		return
+	}
+
+	switch {
+	case command == "d" || command == "y" || command == "g":
+		vim.pending = command
+	case command == "dd" && e.canEdit():
+		vim.yankLine(e)
+		vim.deleteLine(e)
+	case command == "yy":
+		vim.yankLine(e)
+	case command == "x" && e.canEdit():
+		if e.cursor.x < len(e.cursor.line.values)-1 {
+			e.cursor.x++
+			e.storeUndoAction(e.fnDeleteSinglePrevious())
+			e.setModified()
+		}
+	case (command == "p" || command == "P") && e.canEdit():
+		vim.put(e, command == "p")
+	case command == "u":
+		e.RunCommand("Undo")
+	case command == "v":
+		e.SetMode(vim.visual)
+		e.dragAnchor = *e.cursor
+		vim.selectVisual(e)
+	case strings.Contains("iaAI", command) && !e.read_only,
+		strings.Contains("oO", command) && e.canEdit():
+		vim.insert(e, r)
+	}
+}
+
+// vimMotion moves the cursor for a motion command, returning true if the
+// command was a motion.
+func vimMotion(e *Editor, command string) bool {
+	line := e.cursor.line
+	switch command {
+	case "h":
+		if e.cursor.x > 0 {
+			e.cursor.x--
+		}
+	case "l":
+		if e.cursor.x < len(line.values)-1 {
+			e.cursor.x++
+		}
+	case "j":
+		e.moveLineDown(false)
+	case "k":
+		e.moveLineUp(false)
+	case "0":
+		e.cursor.x = 0
+	case "$":
+		e.cursor.x = len(line.values) - 1
+	case "w":
+		wordForward(e)
+	case "b":
+		wordBackward(e)
+	case "gg":
+		e.cursor.line, e.cursor.x = e.start, 0
+	case "G":
+		e.cursor.line, e.cursor.x = vimLastLine(e), 0
+	default:
+		return false
+	}
+	return true
+}
+
+// vimLastLine returns the last line, skipping the empty line after a
+// final new line.
+func vimLastLine(e *Editor) *editorLine {
+	line := e.lastLine()
+	if len(line.values) == 1 && line.prev != nil {
+		return line.prev
+	}
+	return line
+}
+
+// stepCursor moves the cursor a rune forward (dir > 0) or back, across lines,
+// returning false at the end of the content.
+func stepCursor(e *Editor, dir int) bool {
+	line := e.cursor.line
+	switch {
+	case dir > 0 && e.cursor.x < len(line.values)-1:
+		e.cursor.x++
+	case dir > 0 && line.next != nil:
+		e.cursor.line, e.cursor.x = line.next, 0
+	case dir < 0 && e.cursor.x > 0:
+		e.cursor.x--
+	case dir < 0 && line.prev != nil:
+		e.cursor.line, e.cursor.x = line.prev, len(line.prev.values)-1
+	default:
+		return false
+	}
+	return true
+}
+
+// cursorRune returns the rune under the cursor.
+func cursorRune(e *Editor) rune {
+	return e.cursor.line.values[e.cursor.x]
+}
+
+// wordForward moves to the start of the next word.
+func wordForward(e *Editor) {
+	for isWordRune(cursorRune(e)) && stepCursor(e, 1) {
+	}
+	for !isWordRune(cursorRune(e)) && stepCursor(e, 1) {
+	}
+}
+
+// wordBackward moves to the start of the word, or the previous word.
+func wordBackward(e *Editor) {
+	stepCursor(e, -1)
+	for !isWordRune(cursorRune(e)) && stepCursor(e, -1) {
+	}
+	for e.cursor.x > 0 && isWordRune(e.cursor.line.values[e.cursor.x-1]) {
+		e.cursor.x--
+	}
+}
+
+// insert enters the insert mode for the commands i, a, A, I, o and O.
+func (vim *vimState) insert(e *Editor, r rune) {
+	line := e.cursor.line
+	switch r {
+	case 'a':
+		if e.cursor.x < len(line.values)-1 {
+			e.cursor.x++
+		}
+	case 'A':
+		e.cursor.x = len(line.values) - 1
+	case 'I':
+		e.cursor.x = 0
+		for e.cursor.x < len(line.values)-1 && (line.values[e.cursor.x] == ' ' || line.values[e.cursor.x] == '\t') {
+			e.cursor.x++
+		}
+	case 'o', 'O':
+		e.storeUndoAction(e.fnInsertLine(r == 'O'))
+		e.setModified()
+	}
+	e.SetMode(EDIT_MODE)
+}
+
+// yankLine copies the cursor line to the clipboard.
+func (vim *vimState) yankLine(e *Editor) {
+	e.clipboard.WriteText([]byte(string(e.cursor.line.values)))
+	vim.linewise = true
+}
+
+// deleteLine deletes the cursor line, staying on the last line of the
+// content rather than the empty line after a final new line.
+func (vim *vimState) deleteLine(e *Editor) {
+	e.deleteLine()
+	if e.cursor.line.next == nil && e.cursor.line != vimLastLine(e) {
+		e.cursor.line = e.cursor.line.prev
+	}
+}
+
+// put pastes the clipboard after the cursor (p) or before it (P). Lines
+// are pasted below or above the cursor line.
+func (vim *vimState) put(e *Editor, after bool) {
+	rs := normalizeNewlines([]rune(string(e.clipboard.ReadText())))
+	if len(rs) == 0 {
+		return
+	}
+
+	line := e.cursor.line
+	switch {
+	case vim.linewise && after && line.next == nil:
+		// Below the final line, which has no new line to paste after.
+		e.cursor.x = len(line.values) - 1
+		rs = append([]rune{'\n'}, rs[:len(rs)-1]...)
+	case vim.linewise && after:
+		e.cursor.line, e.cursor.x = line.next, 0
+	case vim.linewise:
+		e.cursor.x = 0
+	case after && e.cursor.x < len(line.values)-1:
+		e.cursor.x++
+	}
+
+	row := e.getLineNumber()
+	e.storeUndoAction(e.fnHandleRuneMulti(rs))
+	e.setModified()
+	if vim.linewise {
+		e.MoveCursor(row, 0)
+	}
+}
+
+// updateVisual handles the keys of the visual mode.
+func (vim *vimState) updateVisual(e *Editor) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		vim.pending = ""
+		e.resetHighlight()
+		e.SetMode(vim.normal)
+		return true
+	}
+
+	runes := ebiten.AppendInputChars(nil)
+	for _, r := range runes {
+		vim.visualRune(e, r)
+	}
+	if len(runes) > 0 {
+		e.fixPosition()
+	}
+	return len(runes) > 0 || vimIgnoredKeyPressed()
+}
+
+// visualRune handles a key of the visual mode.
+func (vim *vimState) visualRune(e *Editor, r rune) {
+	command := vim.pending + string(r)
+	vim.pending = ""
+
+	if vimMotion(e, command) {
+		vim.selectVisual(e)
+		return
+	}
+
+	switch command {
+	case "g":
+		vim.pending = command
+	case "y":
+		e.clipboard.WriteText([]byte(string(e.getHighlightedRunes())))
+		vim.linewise = false
+		e.resetHighlight()
+		e.SetMode(vim.normal)
+	case "d", "x":
+		if !e.canEdit() {
+			return
+		}
+		e.clipboard.WriteText([]byte(string(e.getHighlightedRunes())))
+		vim.linewise = false
+		e.storeUndoAction(e.fnDeleteHighlighted())
+		e.resetHighlight()
+		e.setModified()
+		e.SetMode(vim.normal)
+	}
+}
+
+// selectVisual selects from the anchor to the cursor, including both.
+func (vim *vimState) selectVisual(e *Editor) {
+	e.selectFromAnchor()
+	e.highlight(e.dragAnchor.line, e.dragAnchor.x)
+	e.highlight(e.cursor.line, e.cursor.x)
+}
@@ -0,0 +1,84 @@
+package noter
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestVimNormal(t *testing.T) {
+	table := [](struct {
+		keys, expected string
+		row, col       int
+	}){
+		{"x", "bc\ndef\nghi\n", 0, 0},
+		{"dd", "def\nghi\n", 0, 0},
+		{"jdd", "abc\nghi\n", 1, 0},
+		{"Gdd", "abc\ndef\n", 1, 0},
+		{"yyjp", "abc\ndef\nabc\nghi\n", 2, 0},
+		{"yyP", "abc\nabc\ndef\nghi\n", 0, 0},
+		{"Gyyp", "abc\ndef\nghi\nghi\n", 3, 0},
+		{"jl$", "abc\ndef\nghi\n", 1, 3},
+		{"jwb", "abc\ndef\nghi\n", 1, 0},
+		{"ddu", "abc\ndef\nghi\n", 1, 0},
+	}
+
+	for _, entry := range table {
+		editor := NewEditor()
+		editor.WriteText([]byte("abc\ndef\nghi\n"))
+		editor.MoveCursor(0, 0)
+		vim := &vimState{}
+		for _, r := range entry.keys {
+			vim.normalRune(editor, r)
+		}
+		if got := string(editor.ReadText()); got != entry.expected {
+			t.Fatalf("Incorrect content after %q, expected %q, got %q", entry.keys, entry.expected, got)
+		}
+		if row, col := editor.Cursor(); row != entry.row || col != entry.col {
+			t.Fatalf("Incorrect cursor after %q, expected (%v,%v), got (%v,%v)", entry.keys, entry.row, entry.col, row, col)
+		}
+	}
+}
+
+func TestVimWord(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("one two, three\nfour\n"))
+	editor.MoveCursor(0, 0)
+
+	table := [](struct{ motion, row, col int }){
+		{'w', 0, 4},
+		{'w', 0, 9},
+		{'w', 1, 0},
+		{'b', 0, 9},
+		{'b', 0, 4},
+	}
+
+	vim := &vimState{}
+	for _, entry := range table {
+		vim.normalRune(editor, rune(entry.motion))
+		if row, col := editor.Cursor(); row != entry.row || col != entry.col {
+			t.Fatalf("Incorrect move by %c, expected (%v,%v), got (%v,%v)", entry.motion, entry.row, entry.col, row, col)
+		}
+	}
+}
+
+func TestVimIgnoresKey(t *testing.T) {
+	table := [](struct {
+		key     ebiten.Key
+		ignored bool
+	}){
+		{ebiten.KeyEnter, true},
+		{ebiten.KeyNumpadEnter, true},
+		{ebiten.KeyTab, true},
+		{ebiten.KeyBackspace, true},
+		{ebiten.KeyDelete, true},
+		{ebiten.KeyArrowLeft, false},
+		{ebiten.KeyEscape, false},
+	}
+
+	for _, entry := range table {
+		if got := vimIgnoresKey(entry.key); got != entry.ignored {
+			t.Fatalf("Incorrect handling of %v, expected ignored %v, got %v", entry.key, entry.ignored, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// whitespaceMarks are drawn over whitespace, when it's shown.
+var whitespaceMarks = map[rune]string{' ': "·", '\t': "→"}
+
+// WithShowWhitespace draws a middle dot over each space and an arrow over
+// each tab, so that trailing whitespace and mixed indentation stand out.
+// The default is disabled.
+func WithShowWhitespace(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.show_whitespace = enabled
+	}
+}
+
+// ShowWhitespace returns true if whitespace is shown.
+func (e *Editor) ShowWhitespace() bool {
+	return e.show_whitespace
+}
+
+// SetShowWhitespace shows or hides whitespace.
+func (e *Editor) SetShowWhitespace(enabled bool) {
+	e.show_whitespace = enabled
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// whitespaceColumns returns the display positions of the whitespace of the
+// line from start up to end, with their marks. Virtual runes of the view,
+// such as table padding, are not marked, while a tab is marked at the
+// first of its columns.
+func whitespaceColumns(line *editorLine, view *lineView, start, end int) map[int]string {
+	marks := make(map[int]string)
+	for x, r := range line.values {
+		d := view.index[x]
+		if d < start || d >= end || (view.runes[d] != r && r != '\t') {
+			continue
+		}
+		if mark, ok := whitespaceMarks[r]; ok {
+			marks[d] = mark
+		}
+	}
+	return marks
+}
+
+// drawWhitespace marks the whitespace in the row from the display position
+// start up to end.
+func (e *Editor) drawWhitespace(y int, line *editorLine, view *lineView, start, end int, markColor color.Color) {
+	for d, mark := range whitespaceColumns(line, view, start, end) {
+		x := e.textLeft() + font.MeasureString(e.font_info.face, string(view.runes[start:d])).Floor()
+		text.Draw(e.screen, mark, e.font_info.face,
+			x, e.top_padding+y*e.font_info.yUnit+e.font_info.ascent,
+			markColor)
+	}
+}
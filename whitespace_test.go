@@ -0,0 +1,23 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhitespaceColumns(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte("a b\t \n"))
+	line := editor.start
+
+	marks := whitespaceColumns(line, newLineView(line.values), 0, len(line.values))
+	if expected := map[int]string{1: "·", 3: "→", 4: "·"}; !reflect.DeepEqual(marks, expected) {
+		t.Fatalf("Incorrect marks, expected %v, got %v", expected, marks)
+	}
+
+	// Only the whitespace within the row is marked.
+	marks = whitespaceColumns(line, newLineView(line.values), 2, 4)
+	if expected := map[int]string{3: "→"}; !reflect.DeepEqual(marks, expected) {
+		t.Fatalf("Incorrect marks of the row, expected %v, got %v", expected, marks)
+	}
+}
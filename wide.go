@@ -0,0 +1,70 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "unicode/utf8"
+
+// runeColumns returns the number of columns, each the advance of '0', which
+// the rune covers when drawn. Wide runes, such as those of CJK, cover two.
+// The widths are kept by the fontInfo, as they depend on the font.
+func (e *Editor) runeColumns(r rune) int {
+	if r < utf8.RuneSelf {
+		return 1
+	}
+	fi := e.font_info
+	if cols, ok := fi.widths[r]; ok {
+		return cols
+	}
+
+	cols := 1
+	if advance, ok := fi.face.GlyphAdvance(r); ok && fi.xUnit > 0 {
+		cols = (advance.Round() + fi.xUnit/2) / fi.xUnit
+		if cols < 1 {
+			cols = 1
+		}
+	}
+	fi.widths[r] = cols
+	return cols
+}
+
+// columnsOf returns the number of columns which the runes cover.
+func (e *Editor) columnsOf(runes []rune) (cols int) {
+	for _, r := range runes {
+		cols += e.runeColumns(r)
+	}
+	return cols
+}
+
+// columnEnd returns the position after the runes from start which fit
+// within the columns.
+func (e *Editor) columnEnd(runes []rune, start, cols int) int {
+	end := start
+	for ; end < len(runes); end++ {
+		width := e.runeColumns(runes[end])
+		if width > cols {
+			break
+		}
+		cols -= width
+	}
+	return end
+}
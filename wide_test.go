@@ -0,0 +1,44 @@
+package noter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuneColumns(t *testing.T) {
+	editor := NewEditor()
+
+	table := [](struct {
+		text string
+		cols int
+	}){
+		{"abc", 3},
+		{"日本語", 6},
+		{"a日b", 4},
+	}
+
+	for _, entry := range table {
+		if cols := editor.columnsOf([]rune(entry.text)); cols != entry.cols {
+			t.Fatalf("Incorrect columns for %q, expected %v, got %v", entry.text, entry.cols, cols)
+		}
+	}
+}
+
+func TestWrapWideRows(t *testing.T) {
+	editor := NewEditor(WithColumns(10), WithSoftWrap(true))
+
+	table := [](struct {
+		text string
+		rows [][2]int
+	}){
+		{"日本語日本語日本語\n", [][2]int{{0, 4}, {4, 8}, {8, 10}}},
+		{"ab 日本語日\n", [][2]int{{0, 3}, {3, 8}}},
+	}
+
+	for _, entry := range table {
+		rows := editor.wrapRows(newLineView([]rune(entry.text)))
+		if !reflect.DeepEqual(rows, entry.rows) {
+			t.Fatalf("Incorrect rows for %q, expected %v, got %v", entry.text, entry.rows, rows)
+		}
+	}
+}
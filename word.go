@@ -0,0 +1,69 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"unicode"
+)
+
+// WithSearchWholeWord makes searches only match whole words, so that "cat"
+// doesn't match "concatenate". OPTION-W toggles this while searching.
+func WithSearchWholeWord(enabled bool) EditorOption {
+	return func(e *Editor) {
+		e.search_word = enabled
+	}
+}
+
+// SearchWholeWord returns true if searches only match whole words.
+func (e *Editor) SearchWholeWord() bool {
+	return e.search_word
+}
+
+// SetSearchWholeWord enables or disables whole word searches, and repeats
+// any current search.
+func (e *Editor) SetSearchWholeWord(enabled bool) {
+	e.search_word = enabled
+	if e.mode == SEARCH_MODE {
+		e.search()
+	}
+
+	// Update the backing image.
+	e.updateImage()
+}
+
+// isWordRune returns true if the rune is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWholeWord returns true if the runes from start up to end are not
+// joined to a word before or after them.
+func isWholeWord(values []rune, start, end int) bool {
+	if start > 0 && isWordRune(values[start-1]) && isWordRune(values[start]) {
+		return false
+	}
+	if end < len(values) && isWordRune(values[end]) && isWordRune(values[end-1]) {
+		return false
+	}
+	return true
+}
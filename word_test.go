@@ -0,0 +1,29 @@
+package noter
+
+import (
+	"testing"
+)
+
+func TestSearchWholeWord(t *testing.T) {
+	editor := NewEditor(WithSearchWholeWord(true))
+	editor.WriteText([]byte("concatenate cat, cat2 (cat) chat_cat\n"))
+
+	table := [](struct {
+		regex   bool
+		term    string
+		matches int
+	}){
+		{false, "cat", 3},
+		{false, "(cat", 1},
+		{true, "c.t", 3},
+		{true, "cat\\d", 1},
+	}
+
+	for _, entry := range table {
+		editor.SetRegexSearch(entry.regex)
+		editor.Search(entry.term)
+		if editor.searchMatches != entry.matches {
+			t.Fatalf("Incorrect matches for %q, expected %v, got %v", entry.term, entry.matches, editor.searchMatches)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import "time"
+
+const (
+	// EDITOR_WORK_BUDGET is the time spent on queued work in each Update,
+	// so that expensive operations don't stall the frame rate.
+	EDITOR_WORK_BUDGET = 4 * time.Millisecond
+
+	// EDITOR_EDIT_CHUNK is the number of lines changed at a time by an
+	// edit of all of the lines, such as ReplaceAll.
+	EDITOR_EDIT_CHUNK = 20000
+)
+
+// Work is a step of an expensive operation, such as a replace-all on a
+// huge document. Each call does a small part of the operation, returning
+// its progress from 0 to 1, and true once it is done. Steps are run between
+// the handling of input, so the content may change between them.
+type Work func() (progress float64, done bool)
+
+// queuedWork is an operation waiting in the work queue.
+type queuedWork struct {
+	name     string
+	work     Work
+	progress float64
+}
+
+// Queue adds an operation to the work queue. Operations are run in order,
+// a step at a time within each Update, and their progress is shown in the
+// bottom bar.
+func (e *Editor) Queue(name string, work Work) {
+	e.queue(name, work)
+}
+
+// queue adds an operation to the work queue, returning it so that it can
+// be cancelled with unqueue.
+func (e *Editor) queue(name string, work Work) *queuedWork {
+	w := &queuedWork{name: name, work: work}
+	e.workQueue = append(e.workQueue, w)
+	return w
+}
+
+// unqueue removes an operation from the work queue, if it's still queued,
+// without running the rest of it.
+func (e *Editor) unqueue(w *queuedWork) {
+	for i, queued := range e.workQueue {
+		if queued == w {
+			e.workQueue = append(e.workQueue[:i:i], e.workQueue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Working returns the name and progress of the operation at the front of
+// the work queue, and false if the queue is empty.
+func (e *Editor) Working() (name string, progress float64, ok bool) {
+	if len(e.workQueue) == 0 {
+		return "", 0, false
+	}
+	w := e.workQueue[0]
+	return w.name, w.progress, true
+}
+
+// runQueue runs queued work until the work budget is spent, or the
+// queue is empty.
+func (e *Editor) runQueue() {
+	if len(e.workQueue) == 0 {
+		return
+	}
+
+	deadline := time.Now().Add(EDITOR_WORK_BUDGET)
+	for len(e.workQueue) > 0 {
+		w := e.workQueue[0]
+		progress, done := w.work()
+		w.progress = progress
+		if done {
+			e.workQueue = e.workQueue[1:]
+			e.Notify(e.tr("%s: done", w.name))
+		}
+		if time.Now().After(deadline) {
+			return
+		}
+	}
+}
+
+// lineEdit is an edit of all of the lines, queued as work, see queueEdit.
+type lineEdit struct {
+	name string
+	work *queuedWork
+	rows []int    // the rows changed,
+	old  [][]rune // and their values before, for undo.
+}
+
+// queueEdit queues an edit of each line of the content, such as a
+// replace-all, which changes EDITOR_EDIT_CHUNK lines at a time. The edit
+// is given the runes of a line, without its '\n', and returns the new
+// runes, or nil to leave the line as it is. Read-only lines are left.
+// Other edits are rejected with a notice until it's done, so that it
+// is undone as a single step.
+func (e *Editor) queueEdit(name string, edit func(values []rune) []rune) {
+	e.ClearCarets()
+
+	le := &lineEdit{name: name}
+	row := 0
+	le.work = e.queue(name, func() (progress float64, done bool) {
+		now := time.Now()
+		line := e.lineAt(row)
+		for end := row + EDITOR_EDIT_CHUNK; row < end && row < e.lineCount(); row++ {
+			if values := edit(line.values[:len(line.values)-1]); values != nil && !line.protected {
+				le.rows = append(le.rows, row)
+				le.old = append(le.old, line.values)
+				line.values = append(values, '\n')
+				line.edited = now
+				e.invalidateHighlight(line)
+			}
+			line = line.next
+		}
+		e.fixPosition()
+		if row < e.lineCount() {
+			return float64(row) / float64(e.lineCount()), false
+		}
+
+		e.lineEdit = nil
+		if len(le.rows) > 0 {
+			e.undoStack = append(e.undoStack, e.viewUndo(le.undo(e)))
+			e.modified = true
+			e.notifyEdit()
+		}
+		return 1, true
+	})
+	e.lineEdit = le
+}
+
+// undo returns the undo action of the edit, restoring the changed lines.
+func (le *lineEdit) undo(e *Editor) func() bool {
+	return func() bool {
+		for i := len(le.rows) - 1; i >= 0; i-- {
+			line := e.lineAt(le.rows[i])
+			line.values = le.old[i]
+			e.invalidateHighlight(line)
+		}
+		e.fixPosition()
+		return true
+	}
+}
+
+// cancelEdit stops any edit queued by queueEdit, leaving the lines it has
+// changed so far.
+func (e *Editor) cancelEdit() {
+	if e.lineEdit != nil {
+		e.unqueue(e.lineEdit.work)
+		e.lineEdit = nil
+	}
+}
+
+// workText returns the progress of the current operation, for the bottom bar.
+func (e *Editor) workText() (string, bool) {
+	name, progress, ok := e.Working()
+	if !ok {
+		return "", false
+	}
+	return e.tr("%s: %d%%...", name, int(progress*100)), true
+}
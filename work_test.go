@@ -0,0 +1,89 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunQueue(t *testing.T) {
+	editor := NewEditor()
+
+	steps := 0
+	editor.Queue("count", func() (float64, bool) {
+		steps++
+		return float64(steps) / 3, steps == 3
+	})
+	ran := false
+	editor.Queue("next", func() (float64, bool) {
+		ran = true
+		return 1, true
+	})
+
+	if name, _, ok := editor.Working(); !ok || name != "count" {
+		t.Fatalf("Expected the first operation to be working, got %q", name)
+	}
+
+	editor.runQueue()
+	if steps != 3 || !ran {
+		t.Fatalf("Expected the queue to be run within the budget, got %v steps", steps)
+	}
+	if _, _, ok := editor.Working(); ok {
+		t.Fatalf("Expected the queue to be empty")
+	}
+	if notice, _ := editor.currentNotice(); notice != "next: done" {
+		t.Fatalf("Incorrect notice, got: %q", notice)
+	}
+}
+
+// runAll runs the work queue until it's empty.
+func runAll(editor *Editor) {
+	for {
+		if _, _, ok := editor.Working(); !ok {
+			return
+		}
+		editor.runQueue()
+	}
+}
+
+func TestQueuedSearch(t *testing.T) {
+	editor := NewEditor()
+	editor.WriteText([]byte(strings.Repeat("a\n", 2*EDITOR_SEARCH_CHUNK) + "b\n"))
+
+	editor.searchMode()
+	editor.searchTerm = []rune("b")
+	editor.search()
+	if name, _, ok := editor.Working(); !ok || name != "search" {
+		t.Fatalf("Expected the search to be queued, got %q", name)
+	}
+
+	runAll(editor)
+	if row := editor.getLineNumber(); row != 2*EDITOR_SEARCH_CHUNK || editor.searchMatches != 1 {
+		t.Fatalf("Incorrect match, expected row %v, got row %v with %v matches", 2*EDITOR_SEARCH_CHUNK, row, editor.searchMatches)
+	}
+}
+
+func TestQueueEdit(t *testing.T) {
+	editor := NewEditor()
+	text := strings.Repeat("a\n", 2*EDITOR_EDIT_CHUNK)
+	editor.WriteText([]byte(text))
+
+	editor.queueEdit("upper", func(values []rune) []rune {
+		return []rune(strings.ToUpper(string(values)))
+	})
+	editor.RunCommand("Duplicate")
+	if got := string(editor.ReadText()); got != text {
+		t.Fatalf("Expected the edit to be rejected while queued work runs")
+	}
+	if notice, _ := editor.currentNotice(); notice != "wait for upper to finish" {
+		t.Fatalf("Incorrect notice, got: %q", notice)
+	}
+
+	runAll(editor)
+	if got := string(editor.ReadText()); got != strings.ToUpper(text) {
+		t.Fatalf("Expected all of the lines to be edited")
+	}
+	editor.RunCommand("Undo")
+	if got := string(editor.ReadText()); got != text {
+		t.Fatalf("Expected the edit to be undone as a single step")
+	}
+}
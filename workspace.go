@@ -0,0 +1,206 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// EDITOR_SEARCH_WORKERS is the number of goroutines which read and
+	// search the contents of a workspace search.
+	EDITOR_SEARCH_WORKERS = 4
+
+	// EDITOR_SEARCH_LIMIT is the most matches a workspace search lists.
+	EDITOR_SEARCH_LIMIT = 10000
+)
+
+// workspaceMatch is a match of a workspace search.
+type workspaceMatch struct {
+	name   string
+	order  int    // the index of the name, for sorting.
+	row, x int    // the start of the match.
+	end    int    // the end of the match, on the row.
+	line   string // the line of the match, trimmed.
+}
+
+// workspaceSearch is a search of the contents named by the switcher, which
+// are read and searched on background goroutines. Matches are added with
+// PostEdit as each content is searched.
+type workspaceSearch struct {
+	matches []workspaceMatch
+	files   int  // the number of contents with matches.
+	done    bool // true once all of the contents have been searched.
+
+	stop    chan struct{}
+	stopped bool
+}
+
+// SearchWorkspace searches all of the contents named by WithSwitcher for
+// the term, with the options of the search, listing the matches in the
+// find all panel as they're found. Choosing a match opens its content at
+// the match. The contents are opened and read on background goroutines,
+// so the names and open functions of WithSwitcher must be safe to call
+// from any goroutine. The content being edited is searched in its lines
+// as they are, rather than as saved, and the others are decoded as
+// WriteText would, such as in the encoding of WithEncoding. It returns
+// false if there is no switcher, or the term has no matcher, such as an
+// invalid pattern.
+func (e *Editor) SearchWorkspace(term string) bool {
+	match := e.matcher([]rune(term))
+	if e.switch_names == nil || e.switch_open == nil || match == nil {
+		return false
+	}
+
+	s := &workspaceSearch{stop: make(chan struct{})}
+	e.showFindPanel(&findPanel{term: []rune(term), workspace: s})
+
+	names := e.switch_names()
+	open := e.switch_open
+	force := e.force_encoding
+
+	// The lines being edited are copied, as they may be edited meanwhile.
+	current := e.content_name
+	var lines [][]rune
+	for line := e.start; line != nil; line = line.next {
+		lines = append(lines, append([]rune{}, line.values[:len(line.values)-1]...))
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range names {
+			select {
+			case jobs <- i:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < EDITOR_SEARCH_WORKERS; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				name := names[i]
+				var matches []workspaceMatch
+				if name == current {
+					matches = searchLines(lines, match, name, i)
+				} else if c := open(name); c != nil {
+					content, _, _ := decodeContent(c.ReadText(), force)
+					matches = searchContent(content, match, name, i)
+				}
+				if len(matches) > 0 {
+					e.PostEdit(func(e *Editor) {
+						s.add(matches)
+					})
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		e.PostEdit(func(e *Editor) {
+			s.done = true
+		})
+	}()
+	return true
+}
+
+// searchContent returns the matches in the text of the named content.
+func searchContent(text []byte, match lineMatcher, name string, order int) []workspaceMatch {
+	var lines [][]rune
+	for _, line := range strings.Split(string(text), "\n") {
+		lines = append(lines, []rune(strings.TrimSuffix(line, "\r")))
+	}
+	return searchLines(lines, match, name, order)
+}
+
+// searchLines returns the matches in the lines of the named content.
+func searchLines(lines [][]rune, match lineMatcher, name string, order int) (matches []workspaceMatch) {
+	for row, values := range lines {
+		for _, span := range match(values) {
+			matches = append(matches, workspaceMatch{
+				name:  name,
+				order: order,
+				row:   row,
+				x:     span[0],
+				end:   span[1],
+				line:  strings.TrimSpace(string(values)),
+			})
+		}
+	}
+	return matches
+}
+
+// add lists the matches of a content, in the order of the names.
+func (s *workspaceSearch) add(matches []workspaceMatch) {
+	if s.stopped || len(s.matches) >= EDITOR_SEARCH_LIMIT {
+		return
+	}
+	if room := EDITOR_SEARCH_LIMIT - len(s.matches); len(matches) > room {
+		matches = matches[:room]
+	}
+	s.matches = append(s.matches, matches...)
+	s.files++
+	sort.SliceStable(s.matches, func(i, j int) bool {
+		return s.matches[i].order < s.matches[j].order
+	})
+}
+
+// cancel stops the search, ignoring any matches still to be added.
+func (s *workspaceSearch) cancel() {
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+}
+
+// open moves the cursor to the match, opening its content if it isn't
+// the content being edited. A match which hasn't loaded yet is moved to
+// once it has.
+func (s *workspaceSearch) open(e *Editor, m workspaceMatch) {
+	if m.name != e.content_name {
+		e.switchTo(m.name, m.row, m.x)
+		if m.name != e.content_name {
+			return
+		}
+		if e.openAt != nil {
+			e.openEnd = m.end
+			return
+		}
+	}
+	e.showMatch(m.row, m.x, m.end)
+}
+
+// heading returns the text above the matches.
+func (s *workspaceSearch) heading(e *Editor, term string) string {
+	if !s.done {
+		return e.tr("%d matches of %q in %d files, searching...", len(s.matches), term, s.files)
+	}
+	return e.tr("%d matches of %q in %d files", len(s.matches), term, s.files)
+}
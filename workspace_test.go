@@ -0,0 +1,123 @@
+package noter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchWorkspace(t *testing.T) {
+	contents := map[string]string{
+		"a.txt": "saved apple\n",
+		"b.txt": "banana\r\nno\r\nan apple, apple\r\n",
+		"c.txt": "cherry\n",
+	}
+	editor := NewEditor(
+		WithContentName("a.txt"),
+		WithSwitcher(
+			func() []string { return []string{"a.txt", "b.txt", "c.txt"} },
+			func(name string) Content { return &dummyContent{contents[name]} },
+		),
+	)
+	editor.WriteText([]byte("edited\napple\n"))
+
+	if !editor.SearchWorkspace("apple") {
+		t.Fatalf("Expected the search to start")
+	}
+	panel := editor.findPanel
+	for deadline := time.Now().Add(5 * time.Second); !panel.workspace.done; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the search to finish")
+		}
+		editor.runPosted()
+	}
+
+	// The content being edited is searched as it is.
+	want := []string{"a.txt:2: apple", "b.txt:3: an apple, apple", "b.txt:3: an apple, apple"}
+	if panel.count() != len(want) || panel.workspace.files != 2 {
+		t.Fatalf("Incorrect matches, expected %v, got %v in %v files", len(want), panel.count(), panel.workspace.files)
+	}
+	for i, item := range want {
+		if got := panel.item(editor, i); got != item {
+			t.Fatalf("Incorrect match %v, expected %q, got %q", i, item, got)
+		}
+	}
+
+	// Choosing a match in another content opens it at the match.
+	panel.move(2)
+	panel.jump(editor)
+	if row, col := editor.Cursor(); editor.ContentName() != "b.txt" || row != 2 || col != 10 {
+		t.Fatalf("Expected b.txt to be opened at (2,10), got %q at (%v,%v)", editor.ContentName(), row, col)
+	}
+}
+
+func TestSearchWorkspaceOpen(t *testing.T) {
+	lines := 3 * EDITOR_LOAD_CHUNK / 32
+	contents := map[string]string{
+		"a.txt": "caf\xc3\xa9\n",
+		"b.txt": strings.Repeat("a line of a very large log file\n", lines-1) + "the last caf\xc3\xa9\n",
+	}
+	editor := NewEditor(
+		WithContentName("a.txt"),
+		WithEncoding(ENCODING_LATIN1),
+		WithStreamingLoad(true),
+		WithSwitcher(
+			func() []string { return []string{"a.txt", "b.txt"} },
+			func(name string) Content { return &dummyContent{contents[name]} },
+		),
+	)
+	editor.WriteText([]byte(contents["a.txt"]))
+
+	// The other contents are decoded in the encoding of WithEncoding.
+	editor.SearchWorkspace("cafÃ©")
+	panel := editor.findPanel
+	for deadline := time.Now().Add(5 * time.Second); !panel.workspace.done; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the search to finish")
+		}
+		editor.runPosted()
+	}
+	if panel.count() != 2 || panel.workspace.files != 2 {
+		t.Fatalf("Incorrect matches, expected 2, got %v in %v files", panel.count(), panel.workspace.files)
+	}
+
+	// A match which hasn't loaded yet is moved to once it has.
+	panel.move(1)
+	panel.jump(editor)
+	for editor.Loading() {
+		editor.runQueue()
+	}
+	if row, col := editor.Cursor(); editor.ContentName() != "b.txt" || row != lines-1 || col != 9 {
+		t.Fatalf("Expected b.txt to be opened at (%v,9), got %q at (%v,%v)", lines-1, editor.ContentName(), row, col)
+	}
+	if len(editor.highlighted[editor.cursor.line]) != 5 {
+		t.Fatalf("Expected the match to be highlighted, got %v", editor.highlighted[editor.cursor.line])
+	}
+}
+
+func TestSearchWorkspaceRows(t *testing.T) {
+	editor := NewEditor(
+		WithContentName("a.txt"),
+		WithProseMode(true),
+		WithSwitcher(
+			func() []string { return []string{"a.txt"} },
+			func(name string) Content { return &dummyContent{} },
+		),
+	)
+	editor.WriteText([]byte("one two\napple\n"))
+	editor.MoveCursor(0, 3)
+	editor.storeUndoAction(editor.fnSoftBreak())
+
+	// Matches are at the rows of the editor, after any soft breaks.
+	editor.SearchWorkspace("apple")
+	panel := editor.findPanel
+	for deadline := time.Now().Add(5 * time.Second); !panel.workspace.done; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the search to finish")
+		}
+		editor.runPosted()
+	}
+	if panel.count() != 1 || panel.item(editor, 0) != "a.txt:3: apple" {
+		t.Fatalf("Incorrect matches, got %v", panel.workspace.matches)
+	}
+}
@@ -0,0 +1,76 @@
+// MIT License
+//
+// Copyright (c) 2024 Andrew Healey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package noter
+
+const (
+	EDITOR_ZOOM_STEP     = 1.0 // the points added or removed by each zoom.
+	EDITOR_MIN_FONT_SIZE = 4.0
+)
+
+// FontSize returns the size in points of the font of WithFont, or 0 for a
+// face set by WithFontFace.
+func (e *Editor) FontSize() float64 {
+	if e.font_source == nil {
+		return 0
+	}
+	return e.font_size
+}
+
+// SetFontSize changes the size in points of the font of WithFont. The
+// editor keeps its size, with more or fewer rows and columns fitting.
+// Sizes below EDITOR_MIN_FONT_SIZE are ignored, as is a face set by
+// WithFontFace, which can't be resized.
+func (e *Editor) SetFontSize(size float64) {
+	if e.font_source == nil || size < EDITOR_MIN_FONT_SIZE || size == e.font_size {
+		return
+	}
+	face, err := e.newFace(size, e.scale)
+	if err != nil {
+		e.on_error(err)
+		return
+	}
+	e.font_size = size
+	e.setFace(face, true)
+	e.relayout()
+}
+
+// unbindZoom removes the bindings of the zoom commands, which only resize
+// the font of WithFont.
+func (e *Editor) unbindZoom() {
+	for keystroke, command := range e.bindings {
+		switch command {
+		case "ZoomIn", "ZoomOut", "ZoomReset":
+			delete(e.bindings, keystroke)
+		}
+	}
+}
+
+// zoom grows (dir > 0) or shrinks (dir < 0) the font by EDITOR_ZOOM_STEP,
+// or resets it to the size of WithFont (dir == 0).
+func (e *Editor) zoom(dir int) {
+	if dir == 0 {
+		e.SetFontSize(e.font_base_size)
+		return
+	}
+	e.SetFontSize(e.font_size + float64(dir)*EDITOR_ZOOM_STEP)
+}
@@ -0,0 +1,72 @@
+package noter
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+)
+
+func TestZoom(t *testing.T) {
+	f, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		t.Fatalf("Couldn't parse the font: %v", err)
+	}
+	editor := NewEditor(WithFont(f, 12, 72), WithRows(10), WithColumns(40))
+	width, height := editor.Size()
+
+	table := [](struct {
+		command string
+		size    float64
+	}){
+		{"ZoomIn", 13},
+		{"ZoomIn", 14},
+		{"ZoomOut", 13},
+		{"ZoomReset", 12},
+	}
+
+	for _, entry := range table {
+		editor.RunCommand(entry.command)
+		if size := editor.FontSize(); size != entry.size {
+			t.Fatalf("Incorrect size after %v, expected %v, got %v", entry.command, entry.size, size)
+		}
+		if w, h := editor.Size(); w != width || h != height {
+			t.Fatalf("Expected the size to be kept at %vx%v, got %vx%v", width, height, w, h)
+		}
+	}
+
+	editor.SetFontSize(24)
+	if editor.cols >= 40 || editor.rows >= 10 {
+		t.Fatalf("Expected fewer rows and columns to fit, got %v and %v", editor.rows, editor.cols)
+	}
+
+	editor.SetFontSize(EDITOR_MIN_FONT_SIZE - 1)
+	if size := editor.FontSize(); size != 24 {
+		t.Fatalf("Expected a size below the minimum to be ignored, got %v", size)
+	}
+
+	if size := NewEditor().FontSize(); size != 0 {
+		t.Fatalf("Expected no font size for a face, got %v", size)
+	}
+}
+
+func TestZoomBindings(t *testing.T) {
+	f, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		t.Fatalf("Couldn't parse the font: %v", err)
+	}
+	editor := NewEditor(WithFont(f, 12, 72))
+	if command, ok := editor.bindings["cmd+="]; !ok || command != "ZoomIn" {
+		t.Fatalf("Expected the zoom keys to be bound with a font, got %q", command)
+	}
+
+	// Without a font, there is nothing to zoom.
+	editor.SetFontFace(nil)
+	for _, editor := range []*Editor{editor, NewEditor()} {
+		for _, keystroke := range []string{"cmd+=", "cmd+shift+=", "cmd+-", "cmd+0"} {
+			if editor.runKeystroke(keystroke) {
+				t.Fatalf("Expected %q to be unbound without a font", keystroke)
+			}
+		}
+	}
+}